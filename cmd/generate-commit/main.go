@@ -1,28 +1,51 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
 
-	"ai-commit-message-generator/internal/ai"
 	"ai-commit-message-generator/internal/app"
 	"ai-commit-message-generator/internal/config"
 	"ai-commit-message-generator/internal/git"
+	"ai-commit-message-generator/internal/setup"
+	"ai-commit-message-generator/internal/version"
+	"ai-commit-message-generator/pkg/commitgen"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		// Default behavior: generate commit message
-		runGenerate()
-		return
+	// Default behavior: generate commit message. Flags without a command
+	// (e.g. `generate-commit --dry-run`) apply to generate.
+	command := "generate"
+	args := os.Args[1:]
+	if len(args) > 0 && (!strings.HasPrefix(args[0], "-") || args[0] == "-h" || args[0] == "--help" || args[0] == "--version") {
+		command, args = args[0], args[1:]
 	}
 
-	command := os.Args[1]
 	switch command {
 	case "init":
-		runInit()
+		runInit(args)
+	case "uninstall":
+		runUninstall(args)
 	case "generate", "gen":
-		runGenerate()
+		runGenerate(args)
+	case "split":
+		runSplit(args)
+	case "lint":
+		runLint(args)
+	case "doctor":
+		runDoctor(args)
+	case "config":
+		runConfig(args)
+	case "version", "--version":
+		fmt.Println(version.String())
 	case "help", "-h", "--help":
 		printHelp()
 	default:
@@ -32,62 +55,492 @@ func main() {
 	}
 }
 
-func runInit() {
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	hookType := fs.String("hook-type", app.HookPreCommit, "Hook to install: pre-commit, or prepare-commit-msg to fill in the message git opens in the editor")
+	force := fs.Bool("force", false, "Reinitialize, overwriting the existing config, rules, and hook files")
+	fs.Parse(args)
+
 	gitClient := git.NewClient()
 	rulesLoader := config.NewLoader()
 	configLoader := config.NewConfigLoader()
 
 	application := app.NewApp(gitClient, rulesLoader, configLoader, nil)
 
-	if err := application.Init(); err != nil {
+	if err := application.Init(app.InitOptions{HookType: *hookType, Force: *force}); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func runGenerate() {
-	gitClient := git.NewClient()
-	rulesLoader := config.NewLoader()
-	configLoader := config.NewConfigLoader()
+func runUninstall(args []string) {
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	purge := fs.Bool("purge", false, "Also remove the config and rules files")
+	fs.Parse(args)
+
+	application := app.NewApp(git.NewClient(), nil, nil, nil)
+
+	if err := application.Uninstall(app.UninstallOptions{Purge: *purge}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	// Load configuration
-	cfg, err := configLoader.LoadConfig()
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Print the prompt that would be sent to the AI without calling it")
+	commit := fs.Bool("commit", false, "Commit the staged changes with the generated message")
+	interactive := fs.Bool("interactive", isTerminal(os.Stdin) && isTerminal(os.Stdout), "Prompt to accept, edit, regenerate, or quit (default when run in a terminal)")
+	amend := fs.Bool("amend", false, "Refine the last commit's message to cover the staged changes; commits amend HEAD")
+	body := fs.Bool("body", false, "Also write a body explaining why the change was made")
+	noCache := fs.Bool("no-cache", false, "Ask the model even if a cached response for this diff exists")
+	offline := fs.Bool("offline", false, "Build a heuristic message from the diff without calling the AI")
+	signOff := fs.Bool("signoff", false, "Add a Signed-off-by trailer when committing")
+	var coAuthors stringList
+	fs.Var(&coAuthors, "co-author", "Add a Co-authored-by trailer for \"Name <email>\" when committing (repeatable)")
+	testsOnly := fs.Bool("tests-only", false, "Only describe staged test files and use the \"test\" type")
+	breaking := fs.Bool("breaking", false, "Mark the message as a breaking change (\"feat!:\" and a BREAKING CHANGE footer)")
+	summary := fs.Bool("summary", false, "After committing, print the files and line counts that were committed")
+	profile := fs.String("profile", "", "Use the named provider profile from the config")
+	model := fs.String("model", "", "Use this model for this run, overriding the config and "+config.ModelEnv)
+	baseURL := fs.String("base-url", "", "Use this API endpoint for this run, overriding the config and "+config.BaseURLEnv)
+	timeout := fs.Int("timeout", 0, "Request timeout in seconds for this run, overriding the config and "+config.TimeoutEnv)
+	source := fs.String("source", app.SourceStaged, "Where to read the diff from: staged, all, stdin, file:PATH, base:REF, stash, or range:FROM..TO")
+	diffFile := fs.String("diff-file", "", "Read the diff from a patch file instead of git (same as --source file:PATH)")
+	stdin := fs.Bool("stdin", false, "Read the diff from standard input instead of git (same as --source stdin)")
+	all := fs.Bool("all", false, "Describe all tracked changes, staged or not (same as --source all)")
+	noColor := fs.Bool("no-color", false, "Print messages without ANSI colors (also set by NO_COLOR)")
+	output := fs.String("output", "", "Also write the raw commit message to this file, e.g. for git commit -F")
+	messageFile := fs.String("message-file", "", "Fill this commit message file (e.g. .git/COMMIT_EDITMSG) if it has no message yet, as a prepare-commit-msg hook")
+	jsonOutput := fs.Bool("json", false, "Print the result as JSON with its type, content, model, and elapsed_ms")
+	quiet := fs.Bool("quiet", false, "Print only the message (or JSON): no progress, warnings, or retry notices")
+	debug := fs.Bool("debug", debugFromEnv(), "Log the raw requests to and responses from the model to stderr (also set by "+config.DebugEnv+"=1)")
+	fs.Parse(args)
+
+	shorthands := 0
+	for _, set := range []bool{*diffFile != "", *stdin, *all} {
+		if set {
+			shorthands++
+		}
+	}
+	if shorthands > 1 || (shorthands == 1 && *source != app.SourceStaged) {
+		fmt.Fprintf(os.Stderr, "Error: use only one of --source, --diff-file, --stdin, or --all\n")
+		os.Exit(1)
+	}
+	if *diffFile != "" {
+		*source = app.SourceFile + ":" + *diffFile
+	}
+	if *stdin {
+		*source = app.SourceStdin
+	}
+	if *all {
+		*source = app.SourceAll
+	}
+
+	// --commit, --dry-run, --output, --message-file, --json and --quiet ask
+	// for a non-interactive run, and only staged changes can be committed
+	// from the review
+	if *commit || *dryRun || *output != "" || *messageFile != "" || *jsonOutput || *quiet || *source != app.SourceStaged {
+		*interactive = false
+	}
+
+	// stderr carries the progress and notices that --quiet drops
+	var stderr io.Writer = os.Stderr
+	if *quiet {
+		stderr = io.Discard
+	}
+
+	application := newGenerateApp(setup.Options{Profile: *profile, DryRun: *dryRun, Offline: *offline, ModelName: *model, BaseURL: *baseURL, TimeoutSeconds: *timeout, Status: stderr, Color: app.ColorEnabled(*noColor, os.Stderr), Debug: debugLog(*debug)})
+	application.Color = app.ColorEnabled(*noColor, os.Stdout)
+
+	result, err := application.Run(interruptContext(), app.RunOptions{DryRun: *dryRun, Commit: *commit, Interactive: *interactive, TestsOnly: *testsOnly, Breaking: *breaking, Summary: *summary, Source: *source, Amend: *amend, Body: *body, Output: *output, MessageFile: *messageFile, SignOff: *signOff, CoAuthors: coAuthors, NoCache: *noCache})
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if *dryRun {
+		fmt.Println(result.Prompt)
+		fmt.Fprintf(stderr, "Estimated prompt size: about %d tokens\n", result.PromptTokens)
+		return
+	}
+	if result.KeptMessageFile {
+		fmt.Fprintf(stderr, "%s already has a message, leaving it alone.\n", *messageFile)
+		return
+	}
+	if *interactive && !result.IsSplitSuggestion {
+		// The message was already shown during the review
+		if result.Committed {
+			printCommitted(stderr, result)
+		}
+		return
+	}
+	if *jsonOutput {
+		data, err := result.JSON()
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printResult(result, application.Color)
+	}
+
+	if *commit {
+		if !result.Committed {
+			fmt.Fprintf(os.Stderr, "Not committing: the AI suggested splitting the changes instead of a single message.\n")
+			os.Exit(1)
+		}
+		printCommitted(stderr, result)
+	}
+	if *output != "" && result.IsSplitSuggestion {
+		fmt.Fprintf(os.Stderr, "Not writing %s: the AI suggested splitting the changes instead of a single message.\n", *output)
+		os.Exit(1)
+	}
+}
+
+// stringList is a flag that can be given several times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// printCommitted confirms a commit on w, followed by its summary if
+// requested
+func printCommitted(w io.Writer, result *app.RunResult) {
+	fmt.Fprintln(w, "✓ Committed")
+	if result.Summary != nil {
+		fmt.Fprintln(w, result.Summary)
+	}
+}
+
+func runSplit(args []string) {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	profile := fs.String("profile", "", "Use the named provider profile from the config")
+	noColor := fs.Bool("no-color", false, "Print messages without ANSI colors (also set by NO_COLOR)")
+	fs.Parse(args)
+
+	application := newGenerateApp(setup.Options{Profile: *profile, Color: app.ColorEnabled(*noColor, os.Stderr)})
+	application.Color = app.ColorEnabled(*noColor, os.Stdout)
+
+	if err := application.RunSplitSession(interruptContext()); err != nil {
+		exitWithError(err)
+	}
+}
+
+// runDoctor checks the repository, config and AI endpoint, exiting with
+// status 1 if any check fails
+func runDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	profile := fs.String("profile", "", "Check the named profile's config and endpoint")
+	noColor := fs.Bool("no-color", false, "Print results without ANSI colors (also set by NO_COLOR)")
+	fs.Parse(args)
+
+	color := app.ColorEnabled(*noColor, os.Stdout)
+	failed := false
+	for _, check := range commitgen.Doctor(interruptContext(), commitgen.Options{Profile: *profile, Status: os.Stderr}) {
+		if check.Err != nil {
+			failed = true
+			fmt.Printf("%s %s: %v\n", app.Colorize(color, app.ColorRed, "✗"), check.Name, check.Err)
+			continue
+		}
+		fmt.Printf("%s %s", app.Colorize(color, app.ColorGreen, "✓"), check.Name)
+		if check.Detail != "" {
+			fmt.Printf(": %s", check.Detail)
+		}
+		fmt.Println()
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runLint checks an existing commit message, exiting with status 1 and a
+// report of the problems if it doesn't follow the rules
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	file := fs.String("F", "", "Read the message from this file (- for standard input), e.g. .git/COMMIT_EDITMSG")
+	profile := fs.String("profile", "", "Use the named profile's config")
+	fs.Parse(args)
+
+	var message string
+	switch {
+	case *file != "" && fs.NArg() > 0:
+		fmt.Fprintf(os.Stderr, "Error: give either a message or -F, not both\n")
+		os.Exit(1)
+	case *file == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to read message: %w", err))
+		}
+		message = string(data)
+	case *file != "":
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to read message: %w", err))
+		}
+		message = string(data)
+	case fs.NArg() > 0:
+		message = strings.Join(fs.Args(), " ")
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: generate-commit lint [--profile NAME] <message> | -F <file>\n")
+		os.Exit(1)
+	}
+
+	problems, err := commitgen.Lint(message, commitgen.Options{Profile: *profile, Status: os.Stderr})
+	if err != nil {
+		exitWithError(err)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintln(os.Stderr, "✗ Commit message does not follow the rules:")
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "✓ Commit message follows the rules")
+}
+
+// interruptContext returns a context that is cancelled on the first
+// Ctrl-C, so an AI request in progress is abandoned instead of hanging
+// until it times out. A second Ctrl-C exits immediately, e.g. while
+// waiting for input.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		cancel()
+		fmt.Fprintf(os.Stderr, "\nInterrupted (press Ctrl-C again to exit)\n")
+	}()
+	return ctx
+}
+
+// exitWithError prints err and exits, with the conventional status 130
+// if the run was interrupted
+func exitWithError(err error) {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Cancelled.\n")
+		os.Exit(130)
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
+// runConfig handles the config subcommands
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintf(os.Stderr, "Usage: generate-commit config show [--profile NAME]\n")
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	profile := fs.String("profile", "", "Show the config with the named provider profile applied")
+	fs.Parse(args[1:])
+
+	configLoader := config.NewConfigLoader()
+	configLoader.Profile = *profile
+	cfg, source, err := configLoader.LoadConfigWithSource()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Check for API key
-	if cfg.APIKey == "" {
-		fmt.Fprintf(os.Stderr, "Error: OLLAMA_API_KEY environment variable is not set and not found in config.\n")
-		fmt.Fprintf(os.Stderr, "Please set your Ollama API key:\n")
-		fmt.Fprintf(os.Stderr, "  export OLLAMA_API_KEY=your_api_key\n")
-		fmt.Fprintf(os.Stderr, "  or add it to .commit-generator-config\n")
+	data, err := json.MarshalIndent(cfg.Masked(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to marshal config: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Println(string(data))
+	fmt.Println()
+	fmt.Printf("Config file: %s\n", valueOr(source.Path, "none (defaults)"))
+	if source.GlobalPath != "" {
+		fmt.Printf("Global config file: %s\n", source.GlobalPath)
+	}
+	fmt.Printf("API key from: %s\n", valueOr(source.APIKeySource, "not set"))
+}
 
-	aiClient := ai.NewClient(cfg.APIKey, cfg.BaseURL, cfg.Model, cfg.GetTimeout())
-	application := app.NewApp(gitClient, rulesLoader, configLoader, aiClient)
+// valueOr returns value, or fallback if value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
 
-	if err := application.Run(); err != nil {
+// debugFromEnv reports whether GENERATE_COMMIT_DEBUG turns on --debug
+func debugFromEnv() bool {
+	debug, _ := strconv.ParseBool(os.Getenv(config.DebugEnv))
+	return debug
+}
+
+// debugLog returns where --debug logs go: stderr, even with --quiet, since
+// the log was asked for explicitly
+func debugLog(debug bool) io.Writer {
+	if !debug {
+		return nil
+	}
+	return os.Stderr
+}
+
+// newGenerateApp wires up an App through the public API, printing the
+// Status to stderr unless opts sets it. It exits the process if the config
+// is invalid or no API key is configured when one is needed.
+func newGenerateApp(opts setup.Options) *app.App {
+	if opts.Status == nil {
+		opts.Status = os.Stderr
+	}
+	application, err := setup.NewApp(opts)
+	var noKey *setup.NoAPIKeyError
+	if errors.As(err, &noKey) {
+		fmt.Fprintf(os.Stderr, "Error: %v.\n", err)
+		fmt.Fprintf(os.Stderr, "Please set your %s API key:\n", noKey.Provider)
+		fmt.Fprintf(os.Stderr, "  export %s=your_api_key\n", noKey.EnvVar)
+		fmt.Fprintf(os.Stderr, "  or add it to .commit-generator-config\n")
+		fmt.Fprintf(os.Stderr, "  or read it from a file or a secrets manager with api_key_file or api_key_command\n")
+		os.Exit(1)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	return application
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// printResult prints the generated message, highlighting split suggestions
+// when color is enabled. Only the message goes to stdout, so
+// `generate-commit > msg.txt` captures just the message.
+func printResult(result *app.RunResult, color bool) {
+	if result.IsSplitSuggestion {
+		// Output split suggestion in Yellow
+		fmt.Println(app.Colorize(color, app.ColorYellow, "AI Suggestion (Split Changes):"))
+		fmt.Println(result.Message)
+	} else {
+		// Output commit message in Cyan
+		fmt.Println(app.Colorize(color, app.ColorCyan, result.Message))
+	}
 }
 
 func printHelp() {
 	fmt.Println("AI Commit Message Generator")
 	fmt.Println("")
 	fmt.Println("Usage:")
-	fmt.Println("  generate-commit [command]")
+	fmt.Println("  generate-commit [command] [flags]")
 	fmt.Println("")
 	fmt.Println("Commands:")
 	fmt.Println("  init       Initialize repository with config, rules, and pre-commit hook")
+	fmt.Println("  uninstall  Remove the hook installed by init (--purge also removes config and rules)")
 	fmt.Println("  generate   Generate commit message from staged changes (default)")
+	fmt.Println("  split      Split staged changes into logical groups and commit each one")
+	fmt.Println("  lint       Check a commit message against Conventional Commits and the rules")
+	fmt.Println("  doctor     Check the repository, git user, config, API key, and that the AI")
+	fmt.Println("             endpoint answers (--profile NAME to check a profile)")
+	fmt.Println("  config show")
+	fmt.Println("             Print the effective config (API key masked) and where it came from")
+	fmt.Println("  version    Print the version, commit, and build date (also --version)")
 	fmt.Println("  help       Show this help message")
 	fmt.Println("")
+	fmt.Println("Init flags:")
+	fmt.Println("  --hook-type TYPE")
+	fmt.Println("             Hook to install: pre-commit (default), which commits for you, or")
+	fmt.Println("             prepare-commit-msg, which fills in the message git opens in the editor")
+	fmt.Println("  --force    Reinitialize, overwriting the existing config, rules, and hook files")
+	fmt.Println("")
+	fmt.Println("Lint usage:")
+	fmt.Println("  generate-commit lint \"<message>\"")
+	fmt.Println("  generate-commit lint -F FILE  Read the message from FILE (- for standard input);")
+	fmt.Println("             comment lines are ignored, so .git/COMMIT_EDITMSG works as is")
+	fmt.Println("  Exits with status 1 and lists the problems if the message breaks a rule")
+	fmt.Println("")
+	fmt.Println("Generate flags:")
+	fmt.Println("  --all      Describe all tracked changes, staged or not (same as --source all)")
+	fmt.Println("  --body     Also write a body explaining why the change was made (or set include_body)")
+	fmt.Println("  --breaking Mark the message as a breaking change: \"feat(api)!: ...\" and, with --body,")
+	fmt.Println("             a BREAKING CHANGE footer")
+	fmt.Println("  --debug    Log the raw requests to and responses from the model to stderr,")
+	fmt.Println("             with the API key redacted (or set GENERATE_COMMIT_DEBUG=1)")
+	fmt.Println("  --diff-file PATH")
+	fmt.Println("             Read the diff from a patch file instead of git (no repository needed)")
+	fmt.Println("  --dry-run  Print the prompt that would be sent to the AI without calling it")
+	fmt.Println("  --amend    Refine the last commit's message to cover the staged changes;")
+	fmt.Println("             --commit and accepting in interactive mode amend HEAD")
+	fmt.Println("  --co-author \"NAME <EMAIL>\"")
+	fmt.Println("             Add a Co-authored-by trailer when committing; repeat for several")
+	fmt.Println("             co-authors (or set co_authors)")
+	fmt.Println("  --commit   Commit the staged changes with the generated message")
+	fmt.Println("  --interactive")
+	fmt.Println("             Accept, edit, regenerate, or quit after generating")
+	fmt.Println("             (default when run in a terminal; --interactive=false to disable)")
+	fmt.Println("  --json     Print the result as JSON: {\"type\": \"message\" or \"split\", \"content\",")
+	fmt.Println("             \"model\", \"elapsed_ms\"}")
+	fmt.Println("  --base-url URL")
+	fmt.Println("             Use this API endpoint for this run (overrides GENERATE_COMMIT_BASE_URL")
+	fmt.Println("             and base_url)")
+	fmt.Println("  --message-file PATH")
+	fmt.Println("             Write the message into a commit message file that has only comments,")
+	fmt.Println("             e.g. from a prepare-commit-msg hook; a message already there is kept")
+	fmt.Println("  --model NAME")
+	fmt.Println("             Use this model for this run (overrides GENERATE_COMMIT_MODEL and model)")
+	fmt.Println("  --no-cache Ask the model even if the response cache (cache) has this diff")
+	fmt.Println("  --no-color Print messages without colors (also for split). Colors are also")
+	fmt.Println("             off when NO_COLOR is set or the output is not a terminal")
+	fmt.Println("  --offline  Build a message from the diff without calling the AI: the type from")
+	fmt.Println("             the kinds of files changed, the scope from their top-level directory")
+	fmt.Println("             (allow_offline_fallback does this when the API can't be reached)")
+	fmt.Println("  --output PATH")
+	fmt.Println("             Also write the raw commit message to PATH, e.g. for git commit -F")
+	fmt.Println("  --profile NAME")
+	fmt.Println("             Use the named provider profile from the config (also for split)")
+	fmt.Println("  --quiet    Print only the message (or JSON): no progress, warnings, or retry")
+	fmt.Println("             notices. Errors are still reported; implies --interactive=false")
+	fmt.Println("  --signoff  Add a Signed-off-by trailer for the git user when committing")
+	fmt.Println("             (or set sign_off)")
+	fmt.Println("  --source SOURCE")
+	fmt.Println("             Where to read the diff from (default staged). Only staged changes")
+	fmt.Println("             can be committed; other sources just print a message:")
+	fmt.Println("               staged          the staged changes")
+	fmt.Println("               all             all tracked changes, staged or not, against HEAD")
+	fmt.Println("               stdin           a unified diff read from standard input")
+	fmt.Println("               file:PATH       a unified diff read from a file")
+	fmt.Println("               base:REF        the commits since this branch forked from REF")
+	fmt.Println("               stash           the most recent stash entry")
+	fmt.Println("               range:FROM..TO  the changes between two revisions (TO defaults to HEAD)")
+	fmt.Println("  --stdin    Read the diff from standard input instead of git (no repository needed)")
+	fmt.Println("  --summary  After committing, print the files and line counts that were committed")
+	fmt.Println("  --tests-only")
+	fmt.Println("             Only describe staged test files and use the \"test\" type")
+	fmt.Println("  --timeout SECONDS")
+	fmt.Println("             Request timeout for this run (overrides GENERATE_COMMIT_TIMEOUT")
+	fmt.Println("             and timeout_seconds)")
+	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  generate-commit init              # Initialize the repository")
 	fmt.Println("  generate-commit generate          # Generate commit message")
 	fmt.Println("  generate-commit                   # Same as 'generate'")
+	fmt.Println("  generate-commit --dry-run         # Show the prompt without calling the AI")
+	fmt.Println("  generate-commit --commit          # Generate and commit in one step")
+	fmt.Println("  generate-commit --body --commit   # Commit with a subject and an explanatory body")
+	fmt.Println("  generate-commit --tests-only      # Describe only the staged test changes")
+	fmt.Println("  generate-commit --amend --commit  # Fold staged fixes into the last commit")
+	fmt.Println("  generate-commit --source base:main # Describe everything on this branch")
+	fmt.Println("  generate-commit --all             # Describe staged and unstaged changes")
+	fmt.Println("  git diff | generate-commit --stdin")
+	fmt.Println("  generate-commit --output msg.txt && git commit -F msg.txt")
+	fmt.Println("  generate-commit split             # Commit staged changes group by group")
+	fmt.Println("  generate-commit lint -F .git/COMMIT_EDITMSG")
 }
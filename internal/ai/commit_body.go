@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// commitMessage is a commit message split into its subject line and an
+// optional body explaining the change
+type commitMessage struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// String returns the full message: the subject, then a blank line and the
+// body if there is one
+func (m commitMessage) String() string {
+	if m.Body == "" {
+		return m.Subject
+	}
+	return m.Subject + "\n\n" + m.Body
+}
+
+// generateWithBody asks the model for a subject and body as JSON, or a
+// split suggestion
+func generateWithBody(ctx context.Context, c completer, diff, rules string, opts promptOptions) (*GenerateResult, error) {
+	instructions, input := opts.messagePrompt(diff, rules, true)
+	response, err := c.complete(ctx, instructions, input)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion, ok := cutSplitSentinel(response); ok {
+		return &GenerateResult{Kind: ResultSplit, Content: suggestion}, nil
+	}
+	message, err := parseCommitMessage(response)
+	if err != nil {
+		return nil, err
+	}
+	return &GenerateResult{Kind: ResultMessage, Content: message.String()}, nil
+}
+
+// buildBodyInstructions returns the prompt asking for a commit message with
+// a body, as JSON. Split suggestions are answered in plain text after the
+// split sentinel.
+func buildBodyInstructions(rules string, opts promptOptions) string {
+	var sb strings.Builder
+	sb.WriteString("You are an expert DevOps engineer specialized in writing git commit messages.\n\n")
+	sb.WriteString("Analyze the following code diff.\n\n")
+	sb.WriteString("First, determine whether the diff represents a single logical change or multiple independent changes that should be split into smaller commits to follow clean code and best practices.\n\n")
+	sb.WriteString("If the diff should be split, respond in plain text starting with \"" + splitSentinel + "\", then briefly state that it can be broken down and list the suggested commit scopes or purposes (do not generate the commits yet).\n\n")
+	sb.WriteString("If the diff represents a single logical change, write a git commit message following the Conventional Commits specification: ")
+	sb.WriteString("a single-line subject in the format " + opts.subjectFormat() + ", and a body of a few short sentences explaining why the change was made.\n\n")
+	opts.writeMessageRules(&sb)
+	sb.WriteString("For a commit message, respond with only a JSON object, no other text, in this format:\n")
+	sb.WriteString(`{"subject": "` + opts.subjectFormat() + `", "body": "<why the change was made>"}`)
+	sb.WriteString("\n\n")
+
+	if rules != "" {
+		sb.WriteString("Team Rules:\n")
+		sb.WriteString(rules)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// parseCommitMessage extracts the JSON object from the model response,
+// tolerating surrounding prose or Markdown code fences. A response without
+// one is taken as a plain-text message.
+func parseCommitMessage(response string) (*commitMessage, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return &commitMessage{Subject: strings.TrimSpace(response)}, nil
+	}
+
+	var message commitMessage
+	if err := json.Unmarshal([]byte(response[start:end+1]), &message); err != nil {
+		return nil, fmt.Errorf("failed to parse commit message: %w", err)
+	}
+	message.Subject = strings.TrimSpace(message.Subject)
+	message.Body = strings.TrimSpace(message.Body)
+	if message.Subject == "" {
+		return nil, fmt.Errorf("no commit subject found in model response")
+	}
+	return &message, nil
+}
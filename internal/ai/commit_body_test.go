@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommitMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		expected    commitMessage
+		expectedErr string
+	}{
+		{
+			name:     "Subject and body",
+			response: `{"subject": "fix(api): retry 503 responses", "body": "Gateways return 503 during deploys."}`,
+			expected: commitMessage{Subject: "fix(api): retry 503 responses", Body: "Gateways return 503 during deploys."},
+		},
+		{
+			name:     "Code fence and whitespace",
+			response: "```json\n{\"subject\": \" feat: add --body \", \"body\": \"\\nExplains why.\\n\"}\n```",
+			expected: commitMessage{Subject: "feat: add --body", Body: "Explains why."},
+		},
+		{
+			name:     "No body",
+			response: `{"subject": "docs: fix typo"}`,
+			expected: commitMessage{Subject: "docs: fix typo"},
+		},
+		{
+			name:     "Plain text",
+			response: "fix: handle nil config\n",
+			expected: commitMessage{Subject: "fix: handle nil config"},
+		},
+		{
+			name:        "Empty subject",
+			response:    `{"subject": "", "body": "Why."}`,
+			expectedErr: "no commit subject found",
+		},
+		{
+			name:        "Invalid JSON",
+			response:    `{"subject": }`,
+			expectedErr: "failed to parse commit message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := parseCommitMessage(tt.response)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if *message != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *message)
+			}
+		})
+	}
+}
+
+func TestCommitMessage_String(t *testing.T) {
+	tests := []struct {
+		message  commitMessage
+		expected string
+	}{
+		{commitMessage{Subject: "feat: add x"}, "feat: add x"},
+		{commitMessage{Subject: "feat: add x", Body: "Because y."}, "feat: add x\n\nBecause y."},
+	}
+	for _, tt := range tests {
+		if got := tt.message.String(); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// credentialHeaders hold API keys and are never written to the debug log
+var credentialHeaders = []string{"Authorization", "Api-Key", "X-Api-Key", "X-Goog-Api-Key"}
+
+// debugTransport writes each request and the raw response to a log,
+// with the redact headers hidden
+type debugTransport struct {
+	next   http.RoundTripper
+	log    io.Writer
+	redact []string
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fmt.Fprintf(t.log, "--> %s %s\n", req.Method, redactURL(req.URL))
+	writeHeaders(t.log, req.Header, t.redact)
+	// GetBody streams a fresh copy, leaving req.Body for the request itself
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			fmt.Fprintf(t.log, "%s\n", data)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(t.log, "<-- error: %v\n", err)
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	fmt.Fprintf(t.log, "<-- %s\n", resp.Status)
+	writeHeaders(t.log, resp.Header, t.redact)
+	fmt.Fprintf(t.log, "%s\n", body)
+	return resp, nil
+}
+
+// writeHeaders writes header in sorted order, hiding the values of the
+// redact headers, given in canonical form
+func writeHeaders(w io.Writer, header http.Header, redact []string) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		value := strings.Join(header[key], ", ")
+		if slices.Contains(redact, http.CanonicalHeaderKey(key)) {
+			value = Redacted
+		}
+		fmt.Fprintf(w, "%s: %s\n", key, value)
+	}
+}
+
+// redactURL returns u with its password and any key query parameter, as
+// Gemini takes, hidden
+func redactURL(u *url.URL) string {
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		if strings.HasPrefix(param, "key=") {
+			params[i] = "key=" + Redacted
+		}
+	}
+	redacted := *u
+	redacted.RawQuery = strings.Join(params, "&")
+	return redacted.Redacted()
+}
@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewClient_Debug(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		response string
+	}{
+		{name: "Ollama", provider: ProviderOllama, response: `{"response": "feat: add login"}`},
+		{name: "OpenAI", provider: ProviderOpenAI, response: `{"choices": [{"message": {"content": "feat: add login"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const apiKey = "sk-secret-debug-key"
+			var received string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer "+apiKey {
+					t.Errorf("expected the API key to be sent, got %q", r.Header.Get("Authorization"))
+				}
+				body, _ := io.ReadAll(r.Body)
+				received = string(body)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			var debug strings.Builder
+			client, err := NewClient(Options{Provider: tt.provider, APIKey: apiKey, BaseURL: server.URL, Debug: &debug})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			result, err := client.GenerateCommitMessage(context.Background(), "+added line", "")
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Content != "feat: add login" {
+				t.Errorf("expected message %q, got %q", "feat: add login", result.Content)
+			}
+			if !strings.Contains(received, "+added line") {
+				t.Errorf("expected the server to receive the prompt, got %q", received)
+			}
+
+			log := debug.String()
+			if strings.Contains(log, apiKey) {
+				t.Errorf("debug log contains the API key:\n%s", log)
+			}
+			for _, expected := range []string{"--> POST " + server.URL, "Authorization: " + Redacted, "+added line", "<-- 200 OK", tt.response} {
+				if !strings.Contains(log, expected) {
+					t.Errorf("expected debug log to contain %q, got:\n%s", expected, log)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Authorization", "Bearer sk-secret")
+	header.Set("X-Goog-Api-Key", "goog-secret")
+
+	var sb strings.Builder
+	writeHeaders(&sb, header, credentialHeaders)
+	expected := "Authorization: " + Redacted + "\nContent-Type: application/json\nX-Goog-Api-Key: " + Redacted + "\n"
+	if sb.String() != expected {
+		t.Errorf("expected %q, got %q", expected, sb.String())
+	}
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NamedClient is a provider's client with the name shown in fallback
+// notices, e.g. the provider or profile name
+type NamedClient struct {
+	Name   string
+	Client Client
+}
+
+// FallbackClient implements the Client interface by trying several
+// providers in order, moving on to the next when one fails with a
+// retryable error (see IsRetryable). Other errors are returned at once.
+type FallbackClient struct {
+	clients []NamedClient
+	status  io.Writer
+}
+
+// NewFallbackClient returns a client that tries clients in order. Fallback
+// notices, and which provider produced a message, are written to status;
+// nil writes to os.Stderr.
+func NewFallbackClient(clients []NamedClient, status io.Writer) *FallbackClient {
+	if status == nil {
+		status = os.Stderr
+	}
+	return &FallbackClient{clients: clients, status: status}
+}
+
+// GenerateCommitMessage asks each provider in turn for a message
+func (c *FallbackClient) GenerateCommitMessage(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	result, name, err := tryEach(ctx, c, func(client Client) (*GenerateResult, error) {
+		return client.GenerateCommitMessage(ctx, diff, rules)
+	})
+	if err == nil {
+		fmt.Fprintf(c.status, "Message generated by %s\n", name)
+	}
+	return result, err
+}
+
+// GenerateCommitMessageWithBody asks each provider in turn for a subject
+// and body
+func (c *FallbackClient) GenerateCommitMessageWithBody(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	result, name, err := tryEach(ctx, c, func(client Client) (*GenerateResult, error) {
+		return client.GenerateCommitMessageWithBody(ctx, diff, rules)
+	})
+	if err == nil {
+		fmt.Fprintf(c.status, "Message generated by %s\n", name)
+	}
+	return result, err
+}
+
+// BuildPrompt returns the first provider's prompt
+func (c *FallbackClient) BuildPrompt(diff string, rules string) string {
+	return c.clients[0].Client.BuildPrompt(diff, rules)
+}
+
+// BuildBodyPrompt returns the first provider's body prompt
+func (c *FallbackClient) BuildBodyPrompt(diff string, rules string) string {
+	return c.clients[0].Client.BuildBodyPrompt(diff, rules)
+}
+
+// SplitChanges asks each provider in turn to group the diff
+func (c *FallbackClient) SplitChanges(ctx context.Context, diff string, rules string) ([]ChangeGroup, error) {
+	groups, _, err := tryEach(ctx, c, func(client Client) ([]ChangeGroup, error) {
+		return client.SplitChanges(ctx, diff, rules)
+	})
+	return groups, err
+}
+
+// CheckMessage asks each provider in turn to review the message
+func (c *FallbackClient) CheckMessage(ctx context.Context, message, diff, rules string) (*SelfCheckResult, error) {
+	result, _, err := tryEach(ctx, c, func(client Client) (*SelfCheckResult, error) {
+		return client.CheckMessage(ctx, message, diff, rules)
+	})
+	return result, err
+}
+
+// Ping succeeds if any provider is reachable and accepts its key
+func (c *FallbackClient) Ping(ctx context.Context) error {
+	_, _, err := tryEach(ctx, c, func(client Client) (struct{}, error) {
+		return struct{}{}, client.Ping(ctx)
+	})
+	return err
+}
+
+// tryEach calls call with each client until one succeeds or fails with an
+// error that isn't retryable, and returns the result and the name of the
+// client that produced it. If every client fails, the last error is
+// returned.
+func tryEach[T any](ctx context.Context, c *FallbackClient, call func(Client) (T, error)) (T, string, error) {
+	var zero T
+	var err error
+	for i, named := range c.clients {
+		var result T
+		result, err = call(named.Client)
+		if err == nil {
+			return result, named.Name, nil
+		}
+		if ctx.Err() != nil || !IsRetryable(err) {
+			return zero, named.Name, err
+		}
+		if i < len(c.clients)-1 {
+			fmt.Fprintf(c.status, "Warning: %s failed (%v); trying %s\n", named.Name, err, c.clients[i+1].Name)
+		}
+	}
+	return zero, "", fmt.Errorf("all providers failed: %w", err)
+}
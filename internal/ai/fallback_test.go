@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFallbackClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		primaryStatus int
+		expectedMsg   string
+		expectedErr   string
+		expectedLog   []string
+	}{
+		{
+			name:          "Primary succeeds",
+			primaryStatus: http.StatusOK,
+			expectedMsg:   "feat: from primary",
+			expectedLog:   []string{"Message generated by primary"},
+		},
+		{
+			name:          "Server error falls back",
+			primaryStatus: http.StatusServiceUnavailable,
+			expectedMsg:   "feat: from secondary",
+			expectedLog:   []string{"Warning: primary failed", "trying secondary", "Message generated by secondary"},
+		},
+		{
+			name:          "Rate limit falls back",
+			primaryStatus: http.StatusTooManyRequests,
+			expectedMsg:   "feat: from secondary",
+			expectedLog:   []string{"Message generated by secondary"},
+		},
+		{
+			name:          "Bad request does not fall back",
+			primaryStatus: http.StatusBadRequest,
+			expectedErr:   "API returned error: 400 Bad Request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.primaryStatus)
+				w.Write([]byte(`{"response": "feat: from primary"}`))
+			}))
+			defer primary.Close()
+			var secondaryCalled bool
+			secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				secondaryCalled = true
+				w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "feat: from secondary"}}]}`))
+			}))
+			defer secondary.Close()
+
+			var status bytes.Buffer
+			primaryClient, err := NewClient(Options{BaseURL: primary.URL + "/api/generate", MaxRetries: -1, Status: &status})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			secondaryClient, err := NewClient(Options{Provider: ProviderOpenAI, BaseURL: secondary.URL + "/v1/chat/completions", Status: &status})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			client := NewFallbackClient([]NamedClient{{Name: "primary", Client: primaryClient}, {Name: "secondary", Client: secondaryClient}}, &status)
+
+			result, err := client.GenerateCommitMessage(context.Background(), "diff", "")
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				if secondaryCalled {
+					t.Error("expected the secondary provider not to be called")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Content != tt.expectedMsg {
+				t.Errorf("expected message %q, got %q", tt.expectedMsg, result.Content)
+			}
+			for _, line := range tt.expectedLog {
+				if !strings.Contains(status.String(), line) {
+					t.Errorf("expected status to contain %q, got:\n%s", line, status.String())
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackClient_AllFail(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL + "/api/generate"
+	server.Close()
+
+	var clients []NamedClient
+	for _, name := range []string{"first", "second"} {
+		client, err := NewClient(Options{BaseURL: url, MaxRetries: -1, Status: &bytes.Buffer{}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		clients = append(clients, NamedClient{Name: name, Client: client})
+	}
+	var status bytes.Buffer
+	_, err := NewFallbackClient(clients, &status).GenerateCommitMessage(context.Background(), "diff", "")
+	if err == nil || !strings.Contains(err.Error(), "all providers failed") {
+		t.Fatalf("expected all providers to fail, got %v", err)
+	}
+	// The offline fallback still recognises the connection failure
+	if !IsConnectionError(err) {
+		t.Errorf("expected a connection error, got %v", err)
+	}
+	if strings.Count(status.String(), "Warning:") != 1 {
+		t.Errorf("expected one fallback warning, got:\n%s", status.String())
+	}
+}
@@ -0,0 +1,202 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GeminiClient implements the Client interface for Google's Gemini API,
+// calling a model's generateContent method
+type GeminiClient struct {
+	apiKey       string
+	baseURL      string
+	model        string
+	extraOptions map[string]any
+	client       *http.Client
+	jitter       *startupJitter
+	retry        retryPolicy
+	prompt       promptOptions
+}
+
+func init() {
+	Register(ProviderGemini, newGeminiClient)
+}
+
+// newGeminiClient builds a GeminiClient, defaulting to Google's v1beta API
+// and DefaultGeminiModel
+func newGeminiClient(opts Options) (Client, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultGeminiModel
+	}
+	httpClient, err := opts.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &GeminiClient{
+		apiKey:       opts.APIKey,
+		baseURL:      opts.BaseURL,
+		model:        opts.Model,
+		extraOptions: samplingOptions(opts.ExtraOptions, opts.Temperature, opts.TopP),
+		client:       httpClient,
+		jitter:       newStartupJitter(opts.Jitter),
+		retry:        opts.retryPolicy(),
+		prompt:       opts.promptOptions(),
+	}, nil
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent `json:"systemInstruction,omitempty"`
+	GenerationConfig  map[string]any `json:"generationConfig,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+// GenerateCommitMessage sends the diff and rules to Gemini and returns the
+// generated message
+func (c *GeminiClient) GenerateCommitMessage(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	return generate(ctx, c, diff, rules, c.prompt)
+}
+
+// GenerateCommitMessageWithBody asks Gemini for a subject and body
+func (c *GeminiClient) GenerateCommitMessageWithBody(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	return generateWithBody(ctx, c, diff, rules, c.prompt)
+}
+
+// BuildPrompt returns the system instruction and user content sent for
+// the diff and rules, labelled by role
+func (c *GeminiClient) BuildPrompt(diff string, rules string) string {
+	return formatChatPrompt(c.prompt.messagePrompt(diff, rules, false))
+}
+
+// BuildBodyPrompt returns the system instruction and user content sent
+// when asking for a body, labelled by role
+func (c *GeminiClient) BuildBodyPrompt(diff string, rules string) string {
+	return formatChatPrompt(c.prompt.messagePrompt(diff, rules, true))
+}
+
+// SplitChanges asks Gemini to group the diff into independent commits
+func (c *GeminiClient) SplitChanges(ctx context.Context, diff string, rules string) ([]ChangeGroup, error) {
+	return splitChanges(ctx, c, diff, rules)
+}
+
+// CheckMessage asks Gemini to review message against the diff and rules
+func (c *GeminiClient) CheckMessage(ctx context.Context, message, diff, rules string) (*SelfCheckResult, error) {
+	return checkMessage(ctx, c, message, diff, rules, c.prompt)
+}
+
+// Ping checks that the model exists and the API key is accepted by
+// fetching the model's metadata
+func (c *GeminiClient) Ping(ctx context.Context) error {
+	return redactSecret(checkEndpoint(ctx, c.client, c.modelURL(""), ""), c.apiKey)
+}
+
+// complete sends the instructions as the system instruction and the input
+// as the user content, and returns the trimmed text of the first candidate
+func (c *GeminiClient) complete(ctx context.Context, instructions, input string) (string, error) {
+	// The key is sent in the URL, not as a bearer token
+	body, err := postWithRetry(ctx, c.client, c.modelURL("generateContent"), "", c.buildRequest(instructions, input), c.jitter, c.retry)
+	if err != nil {
+		return "", redactSecret(err, c.apiKey)
+	}
+
+	var geminiResp geminiResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if reason := geminiResp.PromptFeedback.BlockReason; reason != "" {
+		return "", fmt.Errorf("Gemini blocked the prompt: %s", reason)
+	}
+	if len(geminiResp.Candidates) == 0 {
+		return "", fmt.Errorf("empty response from model")
+	}
+	candidate := geminiResp.Candidates[0]
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+	if text.Len() == 0 {
+		if candidate.FinishReason != "" && candidate.FinishReason != "STOP" {
+			return "", fmt.Errorf("Gemini returned no text (finish reason %s)", candidate.FinishReason)
+		}
+		return "", fmt.Errorf("empty response from model")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// modelURL returns the URL of the model's method, e.g.
+// .../models/gemini-2.0-flash:generateContent?key=..., or of the model
+// itself when method is empty
+func (c *GeminiClient) modelURL(method string) string {
+	model := strings.TrimPrefix(c.model, "models/")
+	u := strings.TrimSuffix(c.baseURL, "/") + "/models/" + url.PathEscape(model)
+	if method != "" {
+		u += ":" + method
+	}
+	return u + "?key=" + url.QueryEscape(c.apiKey)
+}
+
+// buildRequest builds the generateContent body. Extra options are sent as
+// the generationConfig, and the input is streamed as the user content.
+func (c *GeminiClient) buildRequest(instructions, input string) requestBody {
+	envelope := geminiRequest{GenerationConfig: c.extraOptions}
+	if instructions != "" {
+		envelope.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: instructions}}}
+	}
+	return jsonObjectBody(envelope, "contents", func(w io.Writer) error {
+		if _, err := io.WriteString(w, `[{"role":"user","parts":[{"text":`); err != nil {
+			return err
+		}
+		if err := writeJSONString(w, input); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "}]}]")
+		return err
+	})
+}
+
+// secretError hides a secret, such as an API key in a request URL, in the
+// message of the error it wraps
+type secretError struct {
+	err    error
+	secret string
+}
+
+func (e *secretError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.secret, Redacted)
+}
+
+func (e *secretError) Unwrap() error {
+	return e.err
+}
+
+// redactSecret wraps err so its message doesn't show secret
+func redactSecret(err error, secret string) error {
+	if err == nil || secret == "" {
+		return err
+	}
+	return &secretError{err: err, secret: secret}
+}
@@ -0,0 +1,186 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeminiClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockResponse   string
+		mockStatusCode int
+		expectedMsg    string
+		expectedErr    string
+	}{
+		{
+			name:           "Success",
+			mockResponse:   `{"candidates": [{"content": {"role": "model", "parts": [{"text": " feat: added login \n"}]}, "finishReason": "STOP"}]}`,
+			mockStatusCode: http.StatusOK,
+			expectedMsg:    "feat: added login",
+		},
+		{
+			name:           "Prompt Blocked",
+			mockResponse:   `{"promptFeedback": {"blockReason": "SAFETY"}}`,
+			mockStatusCode: http.StatusOK,
+			expectedErr:    "Gemini blocked the prompt: SAFETY",
+		},
+		{
+			name:           "Candidate Blocked",
+			mockResponse:   `{"candidates": [{"finishReason": "SAFETY"}]}`,
+			mockStatusCode: http.StatusOK,
+			expectedErr:    "Gemini returned no text (finish reason SAFETY)",
+		},
+		{
+			name:           "No Candidates",
+			mockResponse:   `{"candidates": []}`,
+			mockStatusCode: http.StatusOK,
+			expectedErr:    "empty response from model",
+		},
+		{
+			name:           "API Error",
+			mockResponse:   `{"error": {"code": 400, "message": "API key not valid"}}`,
+			mockStatusCode: http.StatusBadRequest,
+			expectedErr:    "API returned error: 400 Bad Request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1beta/models/gemini-test:generateContent" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				if r.URL.Query().Get("key") != "test-api-key" {
+					t.Errorf("expected the API key as a query param, got %q", r.URL.RawQuery)
+				}
+				if auth := r.Header.Get("Authorization"); auth != "" {
+					t.Errorf("expected no Authorization header, got %q", auth)
+				}
+				var req struct {
+					SystemInstruction geminiContent `json:"systemInstruction"`
+					Contents          []struct {
+						Role  string       `json:"role"`
+						Parts []geminiPart `json:"parts"`
+					} `json:"contents"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("failed to decode request: %v", err)
+				}
+				if len(req.SystemInstruction.Parts) != 1 || req.SystemInstruction.Parts[0].Text == "" {
+					t.Errorf("expected the instructions as the system instruction, got %+v", req.SystemInstruction)
+				}
+				if len(req.Contents) != 1 || req.Contents[0].Role != "user" || !strings.Contains(req.Contents[0].Parts[0].Text, "the-diff") {
+					t.Errorf("expected the diff as the user content, got %+v", req.Contents)
+				}
+				w.WriteHeader(tt.mockStatusCode)
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client := &GeminiClient{
+				apiKey:  "test-api-key",
+				baseURL: server.URL + "/v1beta",
+				model:   "gemini-test",
+				client: &http.Client{
+					Timeout: 1 * time.Second,
+				},
+			}
+
+			msg, err := client.GenerateCommitMessage(context.Background(), "the-diff", "")
+
+			if tt.expectedErr != "" {
+				if err == nil {
+					t.Errorf("expected error %q, got nil", tt.expectedErr)
+				} else if !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error containing %q, got %q", tt.expectedErr, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if msg.Content != tt.expectedMsg {
+					t.Errorf("expected message %q, got %q", tt.expectedMsg, msg.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestGeminiClient_KeyHidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "fix: typo"}]}}]}`))
+	}))
+	unreachable := server.URL
+	defer server.Close()
+
+	var debug bytes.Buffer
+	client, err := NewClient(Options{Provider: ProviderGemini, APIKey: "secret-key", BaseURL: server.URL, Model: "gemini-test", Debug: &debug})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.GenerateCommitMessage(context.Background(), "diff", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(debug.String(), "secret-key") || !strings.Contains(debug.String(), "key="+Redacted) {
+		t.Errorf("expected the key to be hidden in the debug log, got:\n%s", debug.String())
+	}
+
+	server.Close()
+	client, err = NewClient(Options{Provider: ProviderGemini, APIKey: "secret-key", BaseURL: unreachable, Model: "gemini-test"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err = client.GenerateCommitMessage(context.Background(), "diff", "")
+	if err == nil || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("expected an error without the key, got %v", err)
+	}
+	if !IsConnectionError(err) {
+		t.Errorf("expected the wrapped error to stay a connection error, got %v", err)
+	}
+}
+
+func TestGeminiClient_Ping(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expectedErr string
+	}{
+		{name: "OK", status: http.StatusOK},
+		{name: "Key rejected", status: http.StatusForbidden, expectedErr: "API key rejected: 403 Forbidden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "GET" || r.URL.Path != "/v1beta/models/gemini-test" || r.URL.Query().Get("key") != "sk-key" {
+					t.Errorf("unexpected call %s %s", r.Method, r.URL)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"name": "models/gemini-test"}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Options{Provider: ProviderGemini, APIKey: "sk-key", BaseURL: server.URL + "/v1beta", Model: "models/gemini-test"})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			err = client.Ping(context.Background())
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -1,55 +1,262 @@
 package ai
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
+
+	"ai-commit-message-generator/internal/git"
 )
 
-// Client defines the interface for AI operations
+// Client defines the interface for AI operations. Requests are abandoned
+// when ctx is cancelled.
 type Client interface {
-	GenerateCommitMessage(diff string, rules string) (string, error)
+	// GenerateCommitMessage asks for a single-line commit message, or a
+	// suggestion to split the changes
+	GenerateCommitMessage(ctx context.Context, diff string, rules string) (*GenerateResult, error)
+	// GenerateCommitMessageWithBody asks for a subject and a body
+	// explaining the change, or a suggestion to split the changes
+	GenerateCommitMessageWithBody(ctx context.Context, diff string, rules string) (*GenerateResult, error)
+	SplitChanges(ctx context.Context, diff string, rules string) ([]ChangeGroup, error)
+	// BuildPrompt returns the prompt GenerateCommitMessage would send,
+	// without calling the API
+	BuildPrompt(diff string, rules string) string
+	// BuildBodyPrompt returns the prompt GenerateCommitMessageWithBody
+	// would send, without calling the API
+	BuildBodyPrompt(diff string, rules string) string
+	// CheckMessage asks the model to review a generated message
+	CheckMessage(ctx context.Context, message, diff, rules string) (*SelfCheckResult, error)
+	// Ping checks that the endpoint is reachable and accepts the API key
+	Ping(ctx context.Context) error
 }
 
-// OllamaClient implements the Client interface for Ollama API
-type OllamaClient struct {
-	apiKey  string
-	baseURL string
-	model   string
-	client  *http.Client
+// Supported provider names for Options.Provider
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+	// ProviderAzure is Azure OpenAI: the OpenAI client, with the key sent
+	// in an api-key header. BaseURL is the deployment's chat completions
+	// URL, including the api-version query parameter.
+	ProviderAzure  = "azure"
+	ProviderGemini = "gemini"
+)
+
+// Default models of the providers, used when no model is configured
+const (
+	DefaultOllamaModel = "gpt-oss:120b"
+	DefaultOpenAIModel = "gpt-4o-mini"
+	DefaultGeminiModel = "gemini-2.0-flash"
+)
+
+// Options holds the settings used by NewClient to build a provider client
+type Options struct {
+	Provider     string
+	APIKey       string
+	BaseURL      string
+	Model        string
+	Timeout      time.Duration
+	ExtraOptions map[string]any
+	// Temperature and TopP are sent as model options when non-zero,
+	// overriding the same keys in ExtraOptions
+	Temperature float64
+	TopP        float64
+	// Proxy is the proxy URL for API requests. When empty, the standard
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables apply.
+	Proxy string
+	// Jitter is the maximum random delay before the first request, also
+	// added to retry backoffs. Zero disables it.
+	Jitter time.Duration
+	// MaxRetries is how often a rate-limited, failing (5xx) or unreachable
+	// request is retried. Zero uses the default of 3; a negative value
+	// disables retries.
+	MaxRetries int
+	// RetryBaseDelay is the first backoff, doubled on each retry. Zero
+	// uses the default of 2s. A Retry-After header takes precedence.
+	RetryBaseDelay time.Duration
+	// Style is the message style, StyleConventional (the default) or
+	// StyleGitmoji
+	Style string
+	// Language is the language to write messages in, e.g. "fr". The
+	// conventional type stays in English. Empty means English.
+	Language string
+	// PromptTemplate is a text/template that replaces the built-in commit
+	// message prompt, rendered with PromptData. A template that fails to
+	// parse or render falls back to the built-in prompt with a warning.
+	PromptTemplate string
+	// AllowedTypes are the conventional types the model may use. Empty
+	// uses DefaultTypes.
+	AllowedTypes []string
+	// Status receives warnings and retry notices. Nil writes to os.Stderr.
+	Status io.Writer
+	// Color prints retry notices in yellow
+	Color bool
+	// Debug, if set, receives each raw request and response, with the API
+	// key and ExtraHeaders redacted
+	Debug io.Writer
+	// ExtraHeaders are set on every request, replacing the client's own
+	// headers of the same name
+	ExtraHeaders map[string]string
+	// CACertFile is a PEM file of certificates trusted in addition to the
+	// system roots, e.g. a private CA in front of a self-hosted model
+	CACertFile string
+	// InsecureSkipVerify turns off TLS certificate verification
+	InsecureSkipVerify bool
+}
+
+// NewClient creates the AI client for the configured provider, using the
+// factory registered under its name. An empty provider defaults to Ollama,
+// and an empty model to the provider's default.
+func NewClient(opts Options) (Client, error) {
+	if opts.Provider == "" {
+		opts.Provider = ProviderOllama
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 60 * time.Second
+	}
+	if opts.Status == nil {
+		opts.Status = os.Stderr
+	}
+	factory, ok := lookupProvider(opts.Provider)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (expected one of %s)", opts.Provider, strings.Join(Providers(), ", "))
+	}
+	return factory(opts)
+}
+
+// HTTPClient returns an HTTP client for API requests that applies the
+// timeout, proxy, TLS, debug and extra header options. Provider factories
+// should send their requests with it.
+func (opts Options) HTTPClient() (*http.Client, error) {
+	proxy, err := proxyFunc(opts.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	tlsClientConfig, err := tlsConfig(opts.CACertFile, opts.InsecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	if tlsClientConfig != nil {
+		transport.TLSClientConfig = tlsClientConfig
+	}
+	httpClient := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+	}
+	if opts.Debug != nil {
+		redact := slices.Clone(credentialHeaders)
+		for name := range opts.ExtraHeaders {
+			redact = append(redact, http.CanonicalHeaderKey(name))
+		}
+		httpClient.Transport = &debugTransport{next: httpClient.Transport, log: opts.Debug, redact: redact}
+	}
+	if len(opts.ExtraHeaders) > 0 {
+		// Outermost, so the debug log shows the headers that are sent
+		httpClient.Transport = &headerTransport{next: httpClient.Transport, headers: opts.ExtraHeaders}
+	}
+	return httpClient, nil
+}
+
+// retryPolicy returns the retry settings, with notices sent to Status
+func (opts Options) retryPolicy() retryPolicy {
+	return retryPolicy{maxRetries: opts.MaxRetries, baseDelay: opts.RetryBaseDelay, notices: opts.Status, color: opts.Color}
+}
+
+// promptOptions returns the prompt settings for the style, language,
+// template and allowed types
+func (opts Options) promptOptions() promptOptions {
+	prompt := newPromptOptions(opts.Style, opts.Language, opts.PromptTemplate, opts.Status)
+	prompt.types = opts.AllowedTypes
+	return prompt
 }
 
-// NewClient creates a new Ollama AI client from config
-func NewClient(apiKey, baseURL, model string, timeout time.Duration) Client {
-	if baseURL == "" {
-		baseURL = "http://localhost:11434/api/generate"
+func init() {
+	Register(ProviderOllama, newOllamaClient)
+}
+
+// newOllamaClient builds an OllamaClient, defaulting to a local server and
+// DefaultOllamaModel
+func newOllamaClient(opts Options) (Client, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "http://localhost:11434/api/generate"
 	}
-	if model == "" {
-		model = "gpt-oss:120b"
+	if opts.Model == "" {
+		opts.Model = DefaultOllamaModel
 	}
-	if timeout == 0 {
-		timeout = 60 * time.Second
+	httpClient, err := opts.HTTPClient()
+	if err != nil {
+		return nil, err
 	}
 	return &OllamaClient{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		model:   model,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		apiKey:       opts.APIKey,
+		baseURL:      opts.BaseURL,
+		model:        opts.Model,
+		extraOptions: samplingOptions(opts.ExtraOptions, opts.Temperature, opts.TopP),
+		client:       httpClient,
+		jitter:       newStartupJitter(opts.Jitter),
+		retry:        opts.retryPolicy(),
+		prompt:       opts.promptOptions(),
+	}, nil
+}
+
+// proxyFunc returns the transport's Proxy func: a fixed proxy when one is
+// configured, otherwise HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
 	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", proxy, err)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// samplingOptions returns the model options to send: extra with the
+// temperature and top_p set when they are non-zero. extra is not modified.
+func samplingOptions(extra map[string]any, temperature, topP float64) map[string]any {
+	if temperature == 0 && topP == 0 {
+		return extra
+	}
+	options := make(map[string]any, len(extra)+2)
+	for k, v := range extra {
+		options[k] = v
+	}
+	if temperature != 0 {
+		options["temperature"] = temperature
+	}
+	if topP != 0 {
+		options["top_p"] = topP
+	}
+	return options
+}
+
+// OllamaClient implements the Client interface for Ollama API
+type OllamaClient struct {
+	apiKey       string
+	baseURL      string
+	model        string
+	extraOptions map[string]any
+	client       *http.Client
+	jitter       *startupJitter
+	retry        retryPolicy
+	prompt       promptOptions
 }
 
 // Request/Response structures for Ollama API
 type ollamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt,omitempty"`
+	Stream  bool           `json:"stream"`
+	Options map[string]any `json:"options,omitempty"`
 }
 
 type ollamaResponse struct {
@@ -58,81 +265,159 @@ type ollamaResponse struct {
 }
 
 // GenerateCommitMessage sends the diff and rules to Ollama and returns the generated message
-func (c *OllamaClient) GenerateCommitMessage(diff string, rules string) (string, error) {
-	prompt := c.buildPrompt(diff, rules)
+func (c *OllamaClient) GenerateCommitMessage(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	return generate(ctx, c, diff, rules, c.prompt)
+}
+
+// GenerateCommitMessageWithBody asks Ollama for a subject and body
+func (c *OllamaClient) GenerateCommitMessageWithBody(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	return generateWithBody(ctx, c, diff, rules, c.prompt)
+}
+
+// BuildPrompt returns the prompt sent to Ollama for the diff and rules
+func (c *OllamaClient) BuildPrompt(diff string, rules string) string {
+	instructions, input := c.prompt.messagePrompt(diff, rules, false)
+	return instructions + input
+}
+
+// BuildBodyPrompt returns the prompt sent to Ollama when asking for a body
+func (c *OllamaClient) BuildBodyPrompt(diff string, rules string) string {
+	instructions, input := c.prompt.messagePrompt(diff, rules, true)
+	return instructions + input
+}
+
+// SplitChanges asks Ollama to group the diff into independent commits
+func (c *OllamaClient) SplitChanges(ctx context.Context, diff string, rules string) ([]ChangeGroup, error) {
+	return splitChanges(ctx, c, diff, rules)
+}
+
+// CheckMessage asks Ollama to review message against the diff and rules
+func (c *OllamaClient) CheckMessage(ctx context.Context, message, diff, rules string) (*SelfCheckResult, error) {
+	return checkMessage(ctx, c, message, diff, rules, c.prompt)
+}
 
-	reqBody := ollamaRequest{
-		Model:  c.model,
-		Prompt: prompt,
-		Stream: false,
+// complete sends the instructions followed by the input as a single prompt
+// and returns the trimmed model response
+func (c *OllamaClient) complete(ctx context.Context, instructions, input string) (string, error) {
+	// The prompt, which holds the diff, is streamed after the other fields
+	envelope := ollamaRequest{
+		Model:   c.model,
+		Stream:  false,
+		Options: c.extraOptions,
 	}
+	reqBody := jsonObjectBody(envelope, "prompt", func(w io.Writer) error {
+		return writeJSONString(w, instructions, input)
+	})
 
-	jsonBody, err := json.Marshal(reqBody)
+	body, err := postWithRetry(ctx, c.client, c.baseURL, c.apiKey, reqBody, c.jitter, c.retry)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
-	// Retry loop
-	maxRetries := 3
-	baseDelay := 2 * time.Second
+	var ollamaResp ollamaResponse
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
 
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Backoff logic
-			delay := baseDelay * time.Duration(1<<uint(attempt-1)) // 2s, 4s, 8s
-			fmt.Fprintf(os.Stderr, "\033[33mRate limit hit. Retrying in %v...\033[0m\n", delay)
-			time.Sleep(delay)
-		}
+	if ollamaResp.Response == "" {
+		return "", fmt.Errorf("empty response from model")
+	}
 
-		req, err := http.NewRequest("POST", c.baseURL, bytes.NewBuffer(jsonBody))
-		if err != nil {
-			return "", fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	return strings.TrimSpace(ollamaResp.Response), nil
+}
 
-		resp, err := c.client.Do(req)
-		if err != nil {
-			return "", fmt.Errorf("API call failed: %w", err)
-		}
-		defer resp.Body.Close()
+// postWithRetry POSTs a JSON body to url and returns the response body of a
+// successful (200) reply. Rate-limited (429) and server error (500, 502,
+// 503, 504) responses and transient network errors are retried according
+// to retry, waiting as long as the Retry-After header asks if present.
+// Other errors fail immediately. The jitter, if any, delays the first
+// request and is added to each backoff. Cancelling ctx aborts the request
+// or backoff in progress.
+func postWithRetry(ctx context.Context, client *http.Client, url, apiKey string, reqBody requestBody, jitter *startupJitter, retry retryPolicy) ([]byte, error) {
+	if err := jitter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		resp, body, err := post(ctx, client, url, apiKey, reqBody)
 
-		if resp.StatusCode == 429 {
-			if attempt == maxRetries {
-				body, _ := io.ReadAll(resp.Body)
-				return "", fmt.Errorf("API rate limit exceeded after %d retries: %s", maxRetries, string(body))
+		var failure error
+		var header http.Header
+		var reason string
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || !isTransientError(err) {
+				return nil, err
 			}
-			continue // Retry
+			failure, reason = err, "Request failed"
+		case resp.StatusCode == http.StatusOK:
+			return body, nil
+		case resp.StatusCode == http.StatusTooManyRequests:
+			failure = fmt.Errorf("API rate limit exceeded: %s", string(body))
+			header, reason = resp.Header, "Rate limit hit"
+		case isRetryableStatus(resp.StatusCode):
+			failure = fmt.Errorf("API returned error: %s (body: %s)", resp.Status, string(body))
+			header, reason = resp.Header, "API returned "+resp.Status
+		default:
+			return nil, fmt.Errorf("API returned error: %s (body: %s)", resp.Status, string(body))
 		}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return "", fmt.Errorf("API returned error: %s (body: %s)", resp.Status, string(body))
+		if attempt == retry.retries() {
+			return nil, &retryableError{fmt.Errorf("%w (gave up after %d retries)", failure, retry.retries())}
 		}
-
-		var ollamaResp ollamaResponse
-		if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-			return "", fmt.Errorf("failed to decode response: %w", err)
+		delay := jitter.spread(retry.delay(attempt+1, header))
+		if waited+delay > maxTotalRetryWait {
+			return nil, &retryableError{fmt.Errorf("%w (retrying would wait more than %v in total)", failure, maxTotalRetryWait)}
 		}
-
-		if ollamaResp.Response == "" {
-			return "", fmt.Errorf("empty response from model")
+		retry.notify("%s. Retrying in %v...", reason, delay.Round(time.Millisecond))
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
 		}
+		waited += delay
+	}
+}
 
-		return strings.TrimSpace(ollamaResp.Response), nil
+// post sends a single request, streaming its body, and reads the whole
+// response body
+func post(ctx context.Context, client *http.Client, url, apiKey string, reqBody requestBody) (*http.Response, []byte, error) {
+	bodyStream := bodyReader(reqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bodyStream)
+	if err != nil {
+		bodyStream.Close()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	return "", fmt.Errorf("unreachable")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return bodyReader(reqBody), nil
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("API call failed: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp, body, nil
 }
 
-func (c *OllamaClient) buildPrompt(diff string, rules string) string {
+// buildInstructions returns the instruction part of the prompt, including
+// any team rules. Chat-style providers send this as the system message.
+func buildInstructions(rules string, opts promptOptions) string {
 	var sb strings.Builder
 	sb.WriteString("You are an expert DevOps engineer specialized in writing git commit messages.\n\n")
 	sb.WriteString("Analyze the following code diff.\n\n")
 	sb.WriteString("First, determine whether the diff represents a single logical change or multiple independent changes that should be split into smaller commits to follow clean code and best practices.\n\n")
-	sb.WriteString("If the diff should be split, briefly state that it can be broken down and list the suggested commit scopes or purposes (do not generate the commits yet).\n\n")
+	sb.WriteString("If the diff should be split, start your response with \"" + splitSentinel + "\", then briefly state that it can be broken down and list the suggested commit scopes or purposes (do not generate the commits yet).\n\n")
 	sb.WriteString("If the diff represents a single logical change, generate a single-line git commit message following the Conventional Commits specification.\n\n")
-	sb.WriteString("Format for commit message:\n<type>(<scope>): <description>\n\n")
-	sb.WriteString("Allowed types: feat, fix, docs, style, refactor, test, chore.\n\n")
+	sb.WriteString("Format for commit message:\n" + opts.subjectFormat() + "\n\n")
+	opts.writeMessageRules(&sb)
 	sb.WriteString("Do not output anything other than the message or the split suggestion.\n\n")
 
 	if rules != "" {
@@ -140,7 +425,40 @@ func (c *OllamaClient) buildPrompt(diff string, rules string) string {
 		sb.WriteString(rules)
 		sb.WriteString("\n\n")
 	}
-	sb.WriteString("Diff:\n")
-	sb.WriteString(diff)
+	return sb.String()
+}
+
+// buildDiffPrompt returns the diff part of the prompt, after a summary of
+// the lines changed per file
+func buildDiffPrompt(diff string) string {
+	if stats := formatDiffStats(diff); stats != "" {
+		return "Diff stats:\n" + stats + "\n\nDiff:\n" + diff
+	}
+	return "Diff:\n" + diff
+}
+
+// formatDiffStats returns a compact `git diff --stat` for diff: a line per
+// file with its added and removed line counts, then the totals. It is
+// empty if diff has no file sections.
+func formatDiffStats(diff string) string {
+	stats := git.DiffStats(diff)
+	if len(stats) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, stat := range stats {
+		sb.WriteString(" " + stat.Path)
+		if stat.Status == git.StatusAdded || stat.Status == git.StatusDeleted {
+			sb.WriteString(" (" + string(stat.Status) + ")")
+		}
+		switch {
+		case stat.Binary:
+			sb.WriteString(" | binary")
+		case stat.Insertions+stat.Deletions > 0:
+			fmt.Fprintf(&sb, " | +%d -%d", stat.Insertions, stat.Deletions)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(" " + git.StatSummary(stats))
 	return sb.String()
 }
@@ -1,8 +1,11 @@
 package ai
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -103,7 +106,7 @@ func TestOllamaClient_GenerateCommitMessage(t *testing.T) {
 				},
 			}
 
-			msg, err := client.GenerateCommitMessage(tt.diff, tt.rules)
+			msg, err := client.GenerateCommitMessage(context.Background(), tt.diff, tt.rules)
 
 			if tt.expectedErr != "" {
 				if err == nil {
@@ -113,12 +116,229 @@ func TestOllamaClient_GenerateCommitMessage(t *testing.T) {
 				}
 			} else {
 				if err != nil {
-					t.Errorf("expected no error, got %v", err)
+					t.Fatalf("expected no error, got %v", err)
 				}
-				if msg != tt.expectedMsg {
-					t.Errorf("expected message %q, got %q", tt.expectedMsg, msg)
+				if msg.Content != tt.expectedMsg {
+					t.Errorf("expected message %q, got %q", tt.expectedMsg, msg.Content)
 				}
 			}
 		})
 	}
 }
+
+func TestOllamaClient_ExtraOptionsPassthrough(t *testing.T) {
+	var received ollamaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"response": "feat: ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		APIKey:  "test-api-key",
+		BaseURL: server.URL + "/api/generate",
+		Model:   "test-model",
+		Timeout: 1 * time.Second,
+		ExtraOptions: map[string]any{
+			"num_ctx": 8192,
+			"stop":    []string{"\n\n"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.GenerateCommitMessage(context.Background(), "diff", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got, ok := received.Options["num_ctx"].(float64); !ok || got != 8192 {
+		t.Errorf("expected options.num_ctx 8192, got %v", received.Options["num_ctx"])
+	}
+	stop, ok := received.Options["stop"].([]any)
+	if !ok || len(stop) != 1 || stop[0] != "\n\n" {
+		t.Errorf("expected options.stop [\"\\n\\n\"], got %v", received.Options["stop"])
+	}
+}
+
+func TestOllamaClient_NoExtraOptions(t *testing.T) {
+	var raw map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"response": "feat: ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		APIKey:  "test-api-key",
+		BaseURL: server.URL + "/api/generate",
+		Model:   "test-model",
+		Timeout: 1 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.GenerateCommitMessage(context.Background(), "diff", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, ok := raw["options"]; ok {
+		t.Errorf("expected options to be omitted, got %v", raw["options"])
+	}
+}
+
+func TestOllamaClient_SamplingOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		temperature float64
+		topP        float64
+		extra       map[string]any
+		expected    map[string]any
+	}{
+		{name: "Unset", expected: nil},
+		{name: "Temperature only", temperature: 0.2, expected: map[string]any{"temperature": 0.2}},
+		{name: "Both", temperature: 0.7, topP: 0.9, expected: map[string]any{"temperature": 0.7, "top_p": 0.9}},
+		{
+			name:        "Overrides extra options",
+			temperature: 0.3,
+			extra:       map[string]any{"temperature": 1.5, "num_ctx": 4096},
+			expected:    map[string]any{"temperature": 0.3, "num_ctx": float64(4096)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"response": "feat: ok", "done": true}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Options{
+				BaseURL:      server.URL + "/api/generate",
+				Model:        "test-model",
+				Timeout:      1 * time.Second,
+				ExtraOptions: tt.extra,
+				Temperature:  tt.temperature,
+				TopP:         tt.topP,
+			})
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if _, err := client.GenerateCommitMessage(context.Background(), "diff", ""); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			options, ok := raw["options"]
+			if tt.expected == nil {
+				if ok {
+					t.Errorf("expected options to be omitted, got %v", options)
+				}
+				return
+			}
+			if !reflect.DeepEqual(options, tt.expected) {
+				t.Errorf("expected options %v, got %v", tt.expected, options)
+			}
+			if tt.extra != nil && tt.extra["temperature"] != 1.5 {
+				t.Errorf("expected extra options to be left unchanged, got %v", tt.extra)
+			}
+		})
+	}
+}
+
+func TestNewClient_Provider(t *testing.T) {
+	tests := []struct {
+		provider    string
+		expectedErr string
+		isOpenAI    bool
+	}{
+		{provider: "", isOpenAI: false},
+		{provider: ProviderOllama, isOpenAI: false},
+		{provider: ProviderOpenAI, isOpenAI: true},
+		{provider: ProviderGemini, isOpenAI: false},
+		{provider: "bogus", expectedErr: "unknown provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			client, err := NewClient(Options{Provider: tt.provider})
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			_, isOpenAI := client.(*OpenAIClient)
+			if isOpenAI != tt.isOpenAI {
+				t.Errorf("expected OpenAI client %v, got %T", tt.isOpenAI, client)
+			}
+		})
+	}
+}
+
+func TestNewClient_Proxy(t *testing.T) {
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL
+		proxiedURL = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"response": "feat: via proxy", "done": true}`))
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(Options{
+		BaseURL: "http://ollama.invalid/api/generate",
+		Timeout: 1 * time.Second,
+		Proxy:   proxy.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	msg, err := client.GenerateCommitMessage(context.Background(), "diff", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if msg.Content != "feat: via proxy" {
+		t.Errorf("unexpected message %q", msg.Content)
+	}
+	if proxiedURL != "http://ollama.invalid/api/generate" {
+		t.Errorf("expected request to go through proxy, got %q", proxiedURL)
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	proxy, err := proxyFunc("http://proxy.corp.example:3128")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	req, _ := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", nil)
+	got, err := proxy(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got == nil || got.String() != "http://proxy.corp.example:3128" {
+		t.Errorf("expected the configured proxy, got %v", got)
+	}
+}
+
+func TestNewClient_InvalidProxy(t *testing.T) {
+	if _, err := NewClient(Options{Proxy: "://bad"}); err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+		t.Errorf("expected invalid proxy error, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+// Kinds of GenerateResult
+const (
+	// ResultMessage is a commit message
+	ResultMessage = "message"
+	// ResultSplit is a suggestion to split the changes into several commits
+	ResultSplit = "split"
+)
+
+// splitSentinel starts a response that suggests splitting the changes
+// instead of giving a commit message
+const splitSentinel = "SPLIT:"
+
+// GenerateResult is the model's answer to a generate request
+type GenerateResult struct {
+	// Kind is ResultMessage or ResultSplit
+	Kind string
+	// Content is the commit message, including any body, or the split
+	// suggestion
+	Content string
+}
+
+// generate asks the model for a commit message or split suggestion
+func generate(ctx context.Context, c completer, diff, rules string, opts promptOptions) (*GenerateResult, error) {
+	instructions, input := opts.messagePrompt(diff, rules, false)
+	response, err := c.complete(ctx, instructions, input)
+	if err != nil {
+		return nil, err
+	}
+	return parseGenerateResult(response), nil
+}
+
+// parseGenerateResult classifies a model response by the split sentinel.
+// Anything else is a commit message.
+func parseGenerateResult(response string) *GenerateResult {
+	if suggestion, ok := cutSplitSentinel(response); ok {
+		return &GenerateResult{Kind: ResultSplit, Content: suggestion}
+	}
+	return &GenerateResult{Kind: ResultMessage, Content: strings.TrimSpace(response)}
+}
+
+// cutSplitSentinel returns the split suggestion following the sentinel, if
+// the response starts with one. Case and Markdown emphasis are ignored.
+func cutSplitSentinel(response string) (string, bool) {
+	trimmed := strings.TrimLeft(strings.TrimSpace(response), "*_`")
+	if len(trimmed) < len(splitSentinel) || !strings.EqualFold(trimmed[:len(splitSentinel)], splitSentinel) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimLeft(trimmed[len(splitSentinel):], "*_`")), true
+}
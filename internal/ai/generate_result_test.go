@@ -0,0 +1,45 @@
+package ai
+
+import "testing"
+
+func TestParseGenerateResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected GenerateResult
+	}{
+		{
+			name:     "Message",
+			response: " feat(auth): add login \n",
+			expected: GenerateResult{Kind: ResultMessage, Content: "feat(auth): add login"},
+		},
+		{
+			name:     "Message with body",
+			response: "feat(auth): add login\n\nUsers asked for it.",
+			expected: GenerateResult{Kind: ResultMessage, Content: "feat(auth): add login\n\nUsers asked for it."},
+		},
+		{
+			name:     "Split",
+			response: "SPLIT: This diff can be broken down:\n- auth\n- docs",
+			expected: GenerateResult{Kind: ResultSplit, Content: "This diff can be broken down:\n- auth\n- docs"},
+		},
+		{
+			name:     "Split with emphasis and lower case",
+			response: "**Split:** auth and docs",
+			expected: GenerateResult{Kind: ResultSplit, Content: "auth and docs"},
+		},
+		{
+			name:     "Split mentioned later is a message",
+			response: "refactor: split config loading",
+			expected: GenerateResult{Kind: ResultMessage, Content: "refactor: split config loading"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseGenerateResult(tt.response); *got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *got)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package ai
+
+import "net/http"
+
+// headerTransport sets extra headers on each request, replacing any the
+// client set itself, such as Authorization
+type headerTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.next.RoundTrip(req)
+}
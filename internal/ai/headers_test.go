@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewClient_ExtraHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		response string
+	}{
+		{name: "Ollama", provider: ProviderOllama, response: `{"response": "feat: add login"}`},
+		{name: "OpenAI", provider: ProviderOpenAI, response: `{"choices": [{"message": {"content": "feat: add login"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r.Header
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			var debug strings.Builder
+			client, err := NewClient(Options{
+				Provider:     tt.provider,
+				APIKey:       "sk-key",
+				BaseURL:      server.URL,
+				ExtraHeaders: map[string]string{"X-Org-Id": "acme", "authorization": "Token gateway-secret"},
+				Debug:        &debug,
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if _, err := client.GenerateCommitMessage(context.Background(), "diff", ""); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if got := received.Get("X-Org-Id"); got != "acme" {
+				t.Errorf("expected X-Org-Id %q, got %q", "acme", got)
+			}
+			if got := received.Values("Authorization"); len(got) != 1 || got[0] != "Token gateway-secret" {
+				t.Errorf("expected the configured Authorization to replace the API key, got %q", got)
+			}
+			if got := received.Get("Content-Type"); got != "application/json" {
+				t.Errorf("expected Content-Type application/json, got %q", got)
+			}
+			if log := debug.String(); strings.Contains(log, "acme") || strings.Contains(log, "gateway-secret") {
+				t.Errorf("expected extra header values to be redacted in the debug log, got:\n%s", log)
+			}
+		})
+	}
+}
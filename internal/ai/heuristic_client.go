@@ -0,0 +1,202 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// errOffline is returned for operations that need a model
+var errOffline = errors.New("not available offline")
+
+// HeuristicClient implements the Client interface without a model. It
+// builds a conventional commit message from the shape of the diff alone:
+// the type from which kinds of files changed and the scope from their
+// shared top-level directory.
+type HeuristicClient struct{}
+
+// NewHeuristicClient creates a client that works offline
+func NewHeuristicClient() *HeuristicClient {
+	return &HeuristicClient{}
+}
+
+// changeKind is how a file was changed by the diff
+type changeKind int
+
+const (
+	fileModified changeKind = iota
+	fileAdded
+	fileDeleted
+)
+
+// fileChange is one file touched by the diff
+type fileChange struct {
+	path string
+	kind changeKind
+}
+
+// GenerateCommitMessage returns a heuristic single-line message
+func (c *HeuristicClient) GenerateCommitMessage(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	changes := parseFileChanges(diff)
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no file changes found in diff")
+	}
+	return &GenerateResult{Kind: ResultMessage, Content: heuristicSubject(changes)}, nil
+}
+
+// GenerateCommitMessageWithBody returns a heuristic message with a body
+// listing the changed files
+func (c *HeuristicClient) GenerateCommitMessageWithBody(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	changes := parseFileChanges(diff)
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no file changes found in diff")
+	}
+	var body strings.Builder
+	body.WriteString("Changed files:")
+	for _, change := range changes {
+		body.WriteString("\n- " + change.path)
+	}
+	message := commitMessage{Subject: heuristicSubject(changes), Body: body.String()}
+	return &GenerateResult{Kind: ResultMessage, Content: message.String()}, nil
+}
+
+// SplitChanges needs a model, so it always fails
+func (c *HeuristicClient) SplitChanges(ctx context.Context, diff string, rules string) ([]ChangeGroup, error) {
+	return nil, fmt.Errorf("splitting changes is %w", errOffline)
+}
+
+// BuildPrompt explains that no prompt is sent offline
+func (c *HeuristicClient) BuildPrompt(diff string, rules string) string {
+	return "(offline: the message is built from the diff, no prompt is sent)\n"
+}
+
+// BuildBodyPrompt explains that no prompt is sent offline
+func (c *HeuristicClient) BuildBodyPrompt(diff string, rules string) string {
+	return c.BuildPrompt(diff, rules)
+}
+
+// CheckMessage needs a model, so it always fails
+func (c *HeuristicClient) CheckMessage(ctx context.Context, message, diff, rules string) (*SelfCheckResult, error) {
+	return nil, fmt.Errorf("self-check is %w", errOffline)
+}
+
+// parseFileChanges lists the files touched by diff and how each changed
+func parseFileChanges(diff string) []fileChange {
+	var changes []fileChange
+	for _, file := range git.SplitDiff(diff) {
+		change := fileChange{path: file.Path}
+		switch {
+		case strings.Contains(file.Text, "\nnew file mode "):
+			change.kind = fileAdded
+		case strings.Contains(file.Text, "\ndeleted file mode "):
+			change.kind = fileDeleted
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// heuristicSubject builds a "type(scope): description" subject for changes
+func heuristicSubject(changes []fileChange) string {
+	subject := inferType(changes)
+	if scope := inferScope(changes); scope != "" {
+		subject += "(" + scope + ")"
+	}
+	return subject + ": " + describeChanges(changes)
+}
+
+// inferType picks the commit type: docs or test when only those changed,
+// feat when new source files were added, and chore otherwise
+func inferType(changes []fileChange) string {
+	allDocs, allTests := true, true
+	for _, change := range changes {
+		allDocs = allDocs && isDocFile(change.path)
+		allTests = allTests && isTestFile(change.path)
+	}
+	switch {
+	case allDocs:
+		return "docs"
+	case allTests:
+		return "test"
+	}
+	for _, change := range changes {
+		if change.kind == fileAdded && !isDocFile(change.path) && !isTestFile(change.path) {
+			return "feat"
+		}
+	}
+	return "chore"
+}
+
+// inferScope returns the top-level directory shared by every changed file,
+// or "" if they don't share one
+func inferScope(changes []fileChange) string {
+	var scope string
+	for i, change := range changes {
+		top, _, ok := strings.Cut(change.path, "/")
+		if !ok || (i > 0 && top != scope) {
+			return ""
+		}
+		scope = top
+	}
+	return scope
+}
+
+// describeChanges names the single changed file, or counts the files
+func describeChanges(changes []fileChange) string {
+	verb := changeVerb(changes[0].kind)
+	for _, change := range changes[1:] {
+		if changeVerb(change.kind) != verb {
+			verb = changeVerb(fileModified)
+		}
+	}
+	if len(changes) == 1 {
+		return verb + " " + path.Base(changes[0].path)
+	}
+	return fmt.Sprintf("%s %d files", verb, len(changes))
+}
+
+// changeVerb describes a kind of change in the imperative mood
+func changeVerb(kind changeKind) string {
+	switch kind {
+	case fileAdded:
+		return "add"
+	case fileDeleted:
+		return "remove"
+	}
+	return "update"
+}
+
+// isTestFile reports whether p looks like a test file
+func isTestFile(p string) bool {
+	base := path.Base(p)
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		switch dir {
+		case "test", "tests", "testdata", "__tests__", "spec":
+			return true
+		}
+	}
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") ||
+		strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_")
+}
+
+// isDocFile reports whether p looks like documentation
+func isDocFile(p string) bool {
+	base := path.Base(p)
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		if dir == "docs" || dir == "doc" {
+			return true
+		}
+	}
+	switch strings.ToLower(path.Ext(base)) {
+	case ".md", ".rst", ".adoc", ".txt":
+		return true
+	}
+	name := strings.ToUpper(strings.TrimSuffix(base, path.Ext(base)))
+	return name == "README" || name == "CHANGELOG" || name == "LICENSE" || name == "CONTRIBUTING"
+}
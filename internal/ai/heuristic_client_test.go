@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// fileDiff returns a diff section for path; mode is "new", "deleted", or ""
+// for a modified file
+func fileDiff(path, mode string) string {
+	header := "diff --git a/" + path + " b/" + path + "\n"
+	switch mode {
+	case "new":
+		header += "new file mode 100644\n"
+	case "deleted":
+		header += "deleted file mode 100644\n"
+	}
+	return header + "--- a/" + path + "\n+++ b/" + path + "\n@@ -1 +1 @@\n-old\n+new\n"
+}
+
+func TestHeuristicClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "new source file is a feature",
+			diff: fileDiff("internal/ai/heuristic.go", "new"),
+			want: "feat(internal): add heuristic.go",
+		},
+		{
+			name: "new file alongside its test is still a feature",
+			diff: fileDiff("internal/cache.go", "new") + fileDiff("internal/cache_test.go", "new"),
+			want: "feat(internal): add 2 files",
+		},
+		{
+			name: "only test files",
+			diff: fileDiff("internal/app/app_test.go", "") + fileDiff("internal/git/client_test.go", "new"),
+			want: "test(internal): update 2 files",
+		},
+		{
+			name: "files under a tests directory",
+			diff: fileDiff("tests/e2e/login.py", ""),
+			want: "test(tests): update login.py",
+		},
+		{
+			name: "spec file",
+			diff: fileDiff("web/src/button.spec.ts", ""),
+			want: "test(web): update button.spec.ts",
+		},
+		{
+			name: "only docs",
+			diff: fileDiff("README.md", "") + fileDiff("docs/setup.md", "new"),
+			want: "docs: update 2 files",
+		},
+		{
+			name: "new doc is docs, not a feature",
+			diff: fileDiff("docs/guide.rst", "new"),
+			want: "docs(docs): add guide.rst",
+		},
+		{
+			name: "modified source is a chore",
+			diff: fileDiff("cmd/main.go", ""),
+			want: "chore(cmd): update main.go",
+		},
+		{
+			name: "deleted files",
+			diff: fileDiff("cmd/old.go", "deleted") + fileDiff("cmd/older.go", "deleted"),
+			want: "chore(cmd): remove 2 files",
+		},
+		{
+			name: "different top-level dirs have no scope",
+			diff: fileDiff("cmd/main.go", "") + fileDiff("internal/app/app.go", ""),
+			want: "chore: update 2 files",
+		},
+		{
+			name: "root file has no scope",
+			diff: fileDiff("go.mod", ""),
+			want: "chore: update go.mod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewHeuristicClient().GenerateCommitMessage(context.Background(), tt.diff, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Kind != ResultMessage || got.Content != tt.want {
+				t.Errorf("got %q (%s), want %q", got.Content, got.Kind, tt.want)
+			}
+			if err := ValidateConventional(got.Content, nil); err != nil {
+				t.Errorf("message is not conventional: %v", err)
+			}
+		})
+	}
+}
+
+func TestHeuristicClient_GenerateCommitMessageWithBody(t *testing.T) {
+	diff := fileDiff("cmd/main.go", "") + fileDiff("cmd/flags.go", "new")
+	got, err := NewHeuristicClient().GenerateCommitMessageWithBody(context.Background(), diff, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "feat(cmd): update 2 files\n\nChanged files:\n- cmd/main.go\n- cmd/flags.go"
+	if got.Content != want {
+		t.Errorf("got %q, want %q", got.Content, want)
+	}
+}
+
+func TestHeuristicClient_Errors(t *testing.T) {
+	c := NewHeuristicClient()
+	if _, err := c.GenerateCommitMessage(context.Background(), "", ""); err == nil {
+		t.Error("expected an error for an empty diff")
+	}
+	if _, err := c.SplitChanges(context.Background(), fileDiff("a.go", ""), ""); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("expected an offline error from SplitChanges, got %v", err)
+	}
+	if _, err := c.CheckMessage(context.Background(), "chore: x", fileDiff("a.go", ""), ""); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("expected an offline error from CheckMessage, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// sleep and randDuration are replaced in tests
+var (
+	sleep        = sleepContext
+	randDuration = func(n time.Duration) time.Duration { return rand.N(n) }
+)
+
+// sleepContext waits for d, or returns ctx's error if it is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startupJitter spreads out the requests of many clients started at the
+// same time (e.g. simultaneous commits against a shared, rate-limited
+// gateway). A nil *startupJitter never waits.
+type startupJitter struct {
+	max  time.Duration
+	once sync.Once
+}
+
+// newStartupJitter returns a jitter of up to max, or nil if max is not
+// positive
+func newStartupJitter(max time.Duration) *startupJitter {
+	if max <= 0 {
+		return nil
+	}
+	return &startupJitter{max: max}
+}
+
+// wait sleeps for a random delay below max before the client's first
+// request. Later calls return immediately. It returns ctx's error if ctx
+// is cancelled during the delay.
+func (j *startupJitter) wait(ctx context.Context) error {
+	if j == nil {
+		return nil
+	}
+	var err error
+	j.once.Do(func() {
+		err = sleep(ctx, randDuration(j.max))
+	})
+	return err
+}
+
+// spread adds a random delay below max to a retry backoff so clients
+// that were limited together don't all retry at the same moment
+func (j *startupJitter) spread(delay time.Duration) time.Duration {
+	if j == nil {
+		return delay
+	}
+	return delay + randDuration(j.max)
+}
@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubSleep records sleeps instead of waiting and makes randDuration return
+// the largest possible value
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	oldSleep, oldRand := sleep, randDuration
+	sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	randDuration = func(n time.Duration) time.Duration { return n - 1 }
+	t.Cleanup(func() { sleep, randDuration = oldSleep, oldRand })
+	return &slept
+}
+
+func TestStartupJitter_WaitsOnceWithinBounds(t *testing.T) {
+	slept := stubSleep(t)
+
+	jitter := newStartupJitter(500 * time.Millisecond)
+	jitter.wait(context.Background())
+	jitter.wait(context.Background())
+
+	if len(*slept) != 1 {
+		t.Fatalf("expected a single startup delay, got %v", *slept)
+	}
+	if d := (*slept)[0]; d < 0 || d >= 500*time.Millisecond {
+		t.Errorf("expected delay in [0, 500ms), got %v", d)
+	}
+}
+
+func TestStartupJitter_Disabled(t *testing.T) {
+	slept := stubSleep(t)
+
+	jitter := newStartupJitter(0)
+	if jitter != nil {
+		t.Fatalf("expected nil jitter for zero max, got %+v", jitter)
+	}
+	jitter.wait(context.Background())
+	if got := jitter.spread(2 * time.Second); got != 2*time.Second {
+		t.Errorf("expected backoff unchanged, got %v", got)
+	}
+	if len(*slept) != 0 {
+		t.Errorf("expected no delay, got %v", *slept)
+	}
+}
+
+func TestStartupJitter_RealRandomWithinBounds(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := randDuration(10 * time.Millisecond); d < 0 || d >= 10*time.Millisecond {
+			t.Fatalf("expected random delay in [0, 10ms), got %v", d)
+		}
+	}
+}
+
+func TestPostWithRetry_AppliesJitter(t *testing.T) {
+	slept := stubSleep(t)
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"response": "ok"}`))
+	}))
+	defer server.Close()
+
+	jitter := newStartupJitter(100 * time.Millisecond)
+	if _, err := postWithRetry(context.Background(), server.Client(), server.URL, "key", emptyBody, jitter, retryPolicy{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []time.Duration{
+		100*time.Millisecond - 1,                 // startup jitter
+		2*time.Second + 100*time.Millisecond - 1, // first backoff plus jitter
+	}
+	if len(*slept) != len(expected) {
+		t.Fatalf("expected sleeps %v, got %v", expected, *slept)
+	}
+	for i := range expected {
+		if (*slept)[i] != expected[i] {
+			t.Errorf("sleep %d: expected %v, got %v", i, expected[i], (*slept)[i])
+		}
+	}
+}
+
+func TestPostWithRetry_CancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cancel while the client is about to back off
+		cancel()
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := postWithRetry(ctx, server.Client(), server.URL, "key", emptyBody, nil, retryPolicy{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the backoff to be abandoned, took %v", elapsed)
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
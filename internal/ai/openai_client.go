@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+)
+
+// OpenAIClient implements the Client interface for OpenAI-compatible
+// /v1/chat/completions endpoints (OpenAI, OpenRouter, LocalAI, vLLM, ...)
+type OpenAIClient struct {
+	apiKey       string
+	azure        bool
+	baseURL      string
+	model        string
+	extraOptions map[string]any
+	client       *http.Client
+	jitter       *startupJitter
+	retry        retryPolicy
+	prompt       promptOptions
+}
+
+func init() {
+	Register(ProviderOpenAI, newOpenAIClient)
+	Register(ProviderAzure, newAzureClient)
+}
+
+// newOpenAIClient builds an OpenAIClient, defaulting to OpenAI's API and
+// DefaultOpenAIModel
+func newOpenAIClient(opts Options) (Client, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.openai.com/v1/chat/completions"
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultOpenAIModel
+	}
+	return openAIClient(opts)
+}
+
+// openAIClient builds an OpenAIClient from opts as given
+func openAIClient(opts Options) (*OpenAIClient, error) {
+	httpClient, err := opts.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAIClient{
+		apiKey:       opts.APIKey,
+		baseURL:      opts.BaseURL,
+		model:        opts.Model,
+		extraOptions: samplingOptions(opts.ExtraOptions, opts.Temperature, opts.TopP),
+		client:       httpClient,
+		jitter:       newStartupJitter(opts.Jitter),
+		retry:        opts.retryPolicy(),
+		prompt:       opts.promptOptions(),
+	}, nil
+}
+
+// newAzureClient builds an OpenAIClient for an Azure OpenAI deployment,
+// which takes the key in an api-key header rather than as a bearer token.
+// The deployment decides the model, so there is no default.
+func newAzureClient(opts Options) (Client, error) {
+	if opts.BaseURL == "" {
+		return nil, errors.New("the azure provider needs a BaseURL")
+	}
+	headers := map[string]string{"api-key": opts.APIKey}
+	maps.Copy(headers, opts.ExtraHeaders)
+	opts.ExtraHeaders = headers
+	opts.APIKey = ""
+	client, err := openAIClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	client.azure = true
+	return client, nil
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// GenerateCommitMessage sends the diff and rules as a chat completion and
+// returns the content of the first choice
+func (c *OpenAIClient) GenerateCommitMessage(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	return generate(ctx, c, diff, rules, c.prompt)
+}
+
+// GenerateCommitMessageWithBody asks for a subject and body as a chat
+// completion
+func (c *OpenAIClient) GenerateCommitMessageWithBody(ctx context.Context, diff string, rules string) (*GenerateResult, error) {
+	return generateWithBody(ctx, c, diff, rules, c.prompt)
+}
+
+// BuildPrompt returns the system and user messages sent for the diff and
+// rules, labelled by role
+func (c *OpenAIClient) BuildPrompt(diff string, rules string) string {
+	return formatChatPrompt(c.prompt.messagePrompt(diff, rules, false))
+}
+
+// BuildBodyPrompt returns the system and user messages sent when asking
+// for a body, labelled by role
+func (c *OpenAIClient) BuildBodyPrompt(diff string, rules string) string {
+	return formatChatPrompt(c.prompt.messagePrompt(diff, rules, true))
+}
+
+// formatChatPrompt labels the system and user messages by role. An empty
+// system message is not sent, so it is left out.
+func formatChatPrompt(instructions, input string) string {
+	if instructions == "" {
+		return "[user]\n" + input
+	}
+	return "[system]\n" + instructions + "[user]\n" + input
+}
+
+// SplitChanges asks the model to group the diff into independent commits
+func (c *OpenAIClient) SplitChanges(ctx context.Context, diff string, rules string) ([]ChangeGroup, error) {
+	return splitChanges(ctx, c, diff, rules)
+}
+
+// CheckMessage asks the model to review message against the diff and rules
+func (c *OpenAIClient) CheckMessage(ctx context.Context, message, diff, rules string) (*SelfCheckResult, error) {
+	return checkMessage(ctx, c, message, diff, rules, c.prompt)
+}
+
+// complete sends the instructions as the system message and the input as
+// the user message, and returns the trimmed content of the first choice
+func (c *OpenAIClient) complete(ctx context.Context, instructions, input string) (string, error) {
+	body, err := postWithRetry(ctx, c.client, c.baseURL, c.apiKey, c.buildRequest(instructions, input), c.jitter, c.retry)
+	if err != nil {
+		return "", err
+	}
+
+	var openAIResp openAIResponse
+	if err := json.Unmarshal(body, &openAIResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(openAIResp.Choices) == 0 || openAIResp.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("empty response from model")
+	}
+
+	return strings.TrimSpace(openAIResp.Choices[0].Message.Content), nil
+}
+
+// buildRequest builds the chat completion body. The instructions go in the
+// system message, left out if empty, and the diff in the user message;
+// the messages are streamed after the other fields. Extra options are sent
+// as top-level request fields, which is where OpenAI-style APIs expect
+// sampling parameters such as temperature.
+func (c *OpenAIClient) buildRequest(instructions, input string) requestBody {
+	envelope := make(map[string]any, len(c.extraOptions)+2)
+	for k, v := range c.extraOptions {
+		envelope[k] = v
+	}
+	delete(envelope, "messages")
+	envelope["model"] = c.model
+	envelope["stream"] = false
+
+	var messages []openAIMessage
+	if instructions != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: instructions})
+	}
+	messages = append(messages, openAIMessage{Role: "user", Content: input})
+
+	return jsonObjectBody(envelope, "messages", func(w io.Writer) error {
+		return writeOpenAIMessages(w, messages)
+	})
+}
+
+// writeOpenAIMessages writes messages as a JSON array, streaming their
+// content
+func writeOpenAIMessages(w io.Writer, messages []openAIMessage) error {
+	for i, m := range messages {
+		open := `{"role":`
+		if i == 0 {
+			open = "[" + open
+		} else {
+			open = "," + open
+		}
+		if _, err := io.WriteString(w, open); err != nil {
+			return err
+		}
+		if err := writeJSONString(w, m.Role); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, `,"content":`); err != nil {
+			return err
+		}
+		if err := writeJSONString(w, m.Content); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "}"); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
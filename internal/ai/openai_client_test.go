@@ -0,0 +1,247 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenAIClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockResponse   string
+		mockStatusCode int
+		expectedMsg    string
+		expectedErr    string
+	}{
+		{
+			name:           "Success",
+			mockResponse:   `{"choices": [{"message": {"role": "assistant", "content": " feat: added login \n"}}]}`,
+			mockStatusCode: http.StatusOK,
+			expectedMsg:    "feat: added login",
+		},
+		{
+			name:           "API Error",
+			mockResponse:   `{"error": {"message": "bad request"}}`,
+			mockStatusCode: http.StatusBadRequest,
+			expectedErr:    "API returned error: 400 Bad Request",
+		},
+		{
+			name:           "No Choices",
+			mockResponse:   `{"choices": []}`,
+			mockStatusCode: http.StatusOK,
+			expectedErr:    "empty response from model",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/v1/chat/completions") {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer test-api-key" {
+					t.Errorf("unexpected Authorization header: %s", r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tt.mockStatusCode)
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client := &OpenAIClient{
+				apiKey:  "test-api-key",
+				baseURL: server.URL + "/v1/chat/completions",
+				model:   "test-model",
+				client: &http.Client{
+					Timeout: 1 * time.Second,
+				},
+			}
+
+			msg, err := client.GenerateCommitMessage(context.Background(), "diff", "")
+
+			if tt.expectedErr != "" {
+				if err == nil {
+					t.Errorf("expected error %q, got nil", tt.expectedErr)
+				} else if !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error containing %q, got %q", tt.expectedErr, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if msg.Content != tt.expectedMsg {
+					t.Errorf("expected message %q, got %q", tt.expectedMsg, msg.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_RequestMessages(t *testing.T) {
+	var received struct {
+		Model       string          `json:"model"`
+		Messages    []openAIMessage `json:"messages"`
+		Temperature float64         `json:"temperature"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "fix: ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		Provider:     ProviderOpenAI,
+		APIKey:       "test-api-key",
+		BaseURL:      server.URL + "/v1/chat/completions",
+		Model:        "gpt-4o-mini",
+		Timeout:      1 * time.Second,
+		ExtraOptions: map[string]any{"temperature": 0.2},
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.GenerateCommitMessage(context.Background(), "diff content", "team rule"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if received.Model != "gpt-4o-mini" {
+		t.Errorf("expected model gpt-4o-mini, got %q", received.Model)
+	}
+	if received.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", received.Temperature)
+	}
+	if len(received.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(received.Messages))
+	}
+	system, user := received.Messages[0], received.Messages[1]
+	if system.Role != "system" || !strings.Contains(system.Content, "Conventional Commits") || !strings.Contains(system.Content, "team rule") {
+		t.Errorf("unexpected system message: %+v", system)
+	}
+	if strings.Contains(system.Content, "diff content") {
+		t.Errorf("system message should not contain the diff")
+	}
+	if user.Role != "user" || !strings.Contains(user.Content, "diff content") {
+		t.Errorf("unexpected user message: %+v", user)
+	}
+}
+
+func TestOpenAIClient_PromptTemplate(t *testing.T) {
+	var received struct {
+		Messages []openAIMessage `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "fix: ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		Provider:       ProviderOpenAI,
+		BaseURL:        server.URL + "/v1/chat/completions",
+		PromptTemplate: "Summarize {{.Diff}}",
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.GenerateCommitMessage(context.Background(), "diff content", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The rendered template is the only message
+	if len(received.Messages) != 1 || received.Messages[0].Role != "user" || received.Messages[0].Content != "Summarize diff content" {
+		t.Errorf("unexpected messages: %+v", received.Messages)
+	}
+	if prompt := client.BuildPrompt("diff content", ""); prompt != "[user]\nSummarize diff content" {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+}
+
+func TestNewClient_Azure(t *testing.T) {
+	var pinged bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/my-gpt/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("api-version") != "2024-10-21" {
+			t.Errorf("expected the api-version query param, got %q", r.URL.RawQuery)
+		}
+		if r.Header.Get("api-key") != "azure-key" {
+			t.Errorf("expected the key in the api-key header, got %q", r.Header.Get("api-key"))
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected no Authorization header, got %q", auth)
+		}
+		pinged = true
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "fix: typo"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		Provider: ProviderAzure,
+		APIKey:   "azure-key",
+		BaseURL:  server.URL + "/openai/deployments/my-gpt/chat/completions?api-version=2024-10-21",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	msg, err := client.GenerateCommitMessage(context.Background(), "diff", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if msg.Content != "fix: typo" {
+		t.Errorf("expected message %q, got %q", "fix: typo", msg.Content)
+	}
+
+	// Deployments can't list models, so Ping sends a prompt to the same URL
+	pinged = false
+	if err := client.Ping(context.Background()); err != nil || !pinged {
+		t.Errorf("expected Ping to call the deployment, got %v", err)
+	}
+
+	if _, err := NewClient(Options{Provider: ProviderAzure}); err == nil {
+		t.Error("expected an error without a BaseURL")
+	}
+}
+
+func TestNewClient_DefaultModel(t *testing.T) {
+	tests := []struct {
+		provider string
+		expected string
+	}{
+		{ProviderOllama, DefaultOllamaModel},
+		{ProviderOpenAI, DefaultOpenAIModel},
+		{ProviderGemini, DefaultGeminiModel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			client, err := NewClient(Options{Provider: tt.provider, APIKey: "key"})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			var model string
+			switch c := client.(type) {
+			case *OllamaClient:
+				model = c.model
+			case *OpenAIClient:
+				model = c.model
+			case *GeminiClient:
+				model = c.model
+			}
+			if model != tt.expected {
+				t.Errorf("expected model %q, got %q", tt.expected, model)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// pingPrompt is the trivial prompt sent when the endpoint has no cheaper
+// way to check it
+const pingPrompt = "Reply with OK."
+
+// Ping checks that Ollama is reachable and accepts the API key by listing
+// the models at /api/tags next to the generate endpoint
+func (c *OllamaClient) Ping(ctx context.Context) error {
+	return ping(ctx, c, c.client, c.baseURL, c.apiKey, "/api/generate", "/api/tags")
+}
+
+// Ping checks that the endpoint is reachable and accepts the API key by
+// listing the models at /models next to the chat completions endpoint.
+// Azure deployments have no such listing, so they are sent pingPrompt.
+func (c *OpenAIClient) Ping(ctx context.Context) error {
+	if c.azure {
+		_, err := c.complete(ctx, "", pingPrompt)
+		return err
+	}
+	return ping(ctx, c, c.client, c.baseURL, c.apiKey, "/chat/completions", "/models")
+}
+
+// Ping always succeeds: offline messages need no endpoint
+func (c *HeuristicClient) Ping(ctx context.Context) error {
+	return nil
+}
+
+// ping GETs the listing endpoint found by replacing suffix with listPath
+// in baseURL. Endpoints with another path, e.g. behind a gateway, are sent
+// pingPrompt instead.
+func ping(ctx context.Context, c completer, client *http.Client, baseURL, apiKey, suffix, listPath string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil || !strings.HasSuffix(u.Path, suffix) {
+		_, err := c.complete(ctx, "", pingPrompt)
+		return err
+	}
+	u.Path = strings.TrimSuffix(u.Path, suffix) + listPath
+	return checkEndpoint(ctx, client, u.String(), apiKey)
+}
+
+// checkEndpoint GETs endpoint, sending apiKey as a bearer token when set,
+// and reports whether it answered 200 OK
+func checkEndpoint(ctx context.Context, client *http.Client, endpoint, apiKey string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("API key rejected: %s (body: %s)", resp.Status, string(body))
+	default:
+		return fmt.Errorf("API returned error: %s (body: %s)", resp.Status, string(body))
+	}
+}
@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_Ping(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		path         string
+		status       int
+		response     string
+		expectedCall string
+		expectedErr  string
+	}{
+		{name: "Ollama", provider: ProviderOllama, path: "/api/generate", status: http.StatusOK, response: `{"models": []}`, expectedCall: "GET /api/tags"},
+		{name: "OpenAI", provider: ProviderOpenAI, path: "/v1/chat/completions", status: http.StatusOK, response: `{"data": []}`, expectedCall: "GET /v1/models"},
+		{name: "Key rejected", provider: ProviderOpenAI, path: "/v1/chat/completions", status: http.StatusUnauthorized, response: `{"error": "invalid key"}`, expectedCall: "GET /v1/models", expectedErr: "API key rejected: 401 Unauthorized"},
+		{name: "Server error", provider: ProviderOllama, path: "/api/generate", status: http.StatusBadGateway, response: "upstream down", expectedCall: "GET /api/tags", expectedErr: "API returned error: 502 Bad Gateway"},
+		{name: "Gateway path", provider: ProviderOllama, path: "/llm/v2", status: http.StatusOK, response: `{"response": "OK"}`, expectedCall: "POST /llm/v2 " + pingPrompt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var call string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer sk-key" {
+					t.Errorf("expected the API key to be sent, got %q", r.Header.Get("Authorization"))
+				}
+				call = r.Method + " " + r.URL.Path
+				if body, _ := io.ReadAll(r.Body); strings.Contains(string(body), pingPrompt) {
+					call += " " + pingPrompt
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Options{Provider: tt.provider, APIKey: "sk-key", BaseURL: server.URL + tt.path})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			err = client.Ping(context.Background())
+			if call != tt.expectedCall {
+				t.Errorf("expected call %q, got %q", tt.expectedCall, call)
+			}
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestClient_PingUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL + "/api/generate"
+	server.Close()
+
+	client, err := NewClient(Options{BaseURL: url})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "API call failed") {
+		t.Errorf("expected a connection error, got %v", err)
+	}
+}
+
+func TestHeuristicClient_Ping(t *testing.T) {
+	if err := NewHeuristicClient().Ping(context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
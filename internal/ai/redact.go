@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secret values removed from text sent to the model
+const Redacted = "***REDACTED***"
+
+// defaultRedactPatterns match common secrets. When a pattern has a capture
+// group only the group is redacted, keeping the surrounding key name.
+var defaultRedactPatterns = []string{
+	// Private key blocks, or just their header if the block is cut off
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+	// AWS access key IDs and secret keys
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`,
+	// Bearer tokens
+	`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`,
+	// password=..., DB_PASSWORD=..., api_key: ... and similar assignments
+	`(?i)(?:password|passwd|pwd|secret|token|api_?key)["']?\s*[=:]\s*["']?([^\s"',;=][^\s"',;]*)`,
+}
+
+// Redactor scrubs secrets from text before it is sent to the model
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor builds a Redactor from the default patterns plus any extra
+// regular expressions
+func NewRedactor(extraPatterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range append(defaultRedactPatterns, extraPatterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact replaces every secret in text with Redacted
+func (r *Redactor) Redact(text string) string {
+	for _, re := range r.patterns {
+		text = redactMatches(re, text)
+	}
+	return text
+}
+
+// redactMatches replaces the first capture group of each match of re, or
+// the whole match if re has no groups
+func redactMatches(re *regexp.Regexp, text string) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) > 2 && m[2] != -1 {
+			start, end = m[2], m[3]
+		}
+		sb.WriteString(text[last:start])
+		sb.WriteString(Redacted)
+		last = end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"slices"
+	"sync"
+)
+
+// Factory builds a provider's Client from the Options given to NewClient,
+// after NewClient has applied its defaults
+type Factory func(opts Options) (Client, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]Factory)
+)
+
+// Register makes a provider available to NewClient under name, which is
+// then accepted as the config's provider. It is meant to be called from
+// an init function, and panics if name is empty, factory is nil or the
+// name is already registered.
+func Register(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if name == "" {
+		panic("ai: Register with an empty provider name")
+	}
+	if factory == nil {
+		panic("ai: Register factory is nil for provider " + name)
+	}
+	if _, dup := providers[name]; dup {
+		panic("ai: Register called twice for provider " + name)
+	}
+	providers[name] = factory
+}
+
+// Providers returns the sorted names of the registered providers
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsRegistered reports whether a provider is registered under name
+func IsRegistered(name string) bool {
+	_, ok := lookupProvider(name)
+	return ok
+}
+
+func lookupProvider(name string) (Factory, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	factory, ok := providers[name]
+	return factory, ok
+}
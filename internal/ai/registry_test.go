@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeClient is a provider registered by the tests
+type fakeClient struct {
+	HeuristicClient
+	opts Options
+}
+
+func init() {
+	Register("fake", func(opts Options) (Client, error) {
+		return &fakeClient{opts: opts}, nil
+	})
+}
+
+func TestRegister(t *testing.T) {
+	if !IsRegistered("fake") || !slices.Contains(Providers(), "fake") {
+		t.Fatalf("expected the fake provider to be registered, got %v", Providers())
+	}
+	for _, name := range []string{ProviderOllama, ProviderOpenAI, ProviderAzure, ProviderGemini} {
+		if !IsRegistered(name) {
+			t.Errorf("expected built-in provider %q to be registered", name)
+		}
+	}
+
+	client, err := NewClient(Options{Provider: "fake", APIKey: "fake-key"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	fake, ok := client.(*fakeClient)
+	if !ok {
+		t.Fatalf("expected the fake client, got %T", client)
+	}
+	// The factory sees the options after NewClient's defaults, and picks
+	// its own default model
+	if fake.opts.APIKey != "fake-key" || fake.opts.Model != "" || fake.opts.Timeout == 0 || fake.opts.Status == nil {
+		t.Errorf("expected defaulted options, got %+v", fake.opts)
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	_, err = NewClient(Options{Provider: "missing"})
+	if err == nil || !strings.Contains(err.Error(), "unknown provider \"missing\"") || !strings.Contains(err.Error(), "fake") {
+		t.Errorf("expected an unknown provider error listing the providers, got %v", err)
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		factory  Factory
+	}{
+		{name: "Empty name", provider: "", factory: newOllamaClient},
+		{name: "Nil factory", provider: "nil-factory", factory: nil},
+		{name: "Duplicate", provider: ProviderOllama, factory: newOllamaClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected Register to panic")
+				}
+			}()
+			Register(tt.provider, tt.factory)
+		})
+	}
+}
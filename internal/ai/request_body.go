@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// bodyBufferSize is how much of a request body is buffered before it is
+// handed to the HTTP client
+const bodyBufferSize = 32 * 1024
+
+// requestBody writes a JSON request body to w. It is called again for
+// each attempt, so a retried request streams the prompt again instead of
+// keeping an encoded copy of the diff in memory.
+type requestBody func(w io.Writer) error
+
+// bodyReader streams body through a pipe as the request reads it. Closing
+// the reader stops the writer.
+func bodyReader(body requestBody) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		bw := bufio.NewWriterSize(pw, bodyBufferSize)
+		err := body(bw)
+		if err == nil {
+			err = bw.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// jsonObjectBody returns a body with the fields of envelope, which must
+// encode to a JSON object, followed by key with the value written by
+// writeValue. Only the small envelope is marshaled; the large value is
+// written straight to the request.
+func jsonObjectBody(envelope any, key string, writeValue func(w io.Writer) error) requestBody {
+	return func(w io.Writer) error {
+		fields, err := json.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		if len(fields) < 2 || fields[0] != '{' || fields[len(fields)-1] != '}' {
+			return fmt.Errorf("failed to marshal request: %T is not a JSON object", envelope)
+		}
+		fields = fields[:len(fields)-1]
+		if len(fields) > 1 {
+			fields = append(fields, ',')
+		}
+		if _, err := w.Write(fields); err != nil {
+			return err
+		}
+		if err := writeJSONString(w, key); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, ":"); err != nil {
+			return err
+		}
+		if err := writeValue(w); err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "}")
+		return err
+	}
+}
+
+// writeJSONString writes the concatenated parts as a single JSON string,
+// escaping as it goes instead of building an encoded copy. Invalid UTF-8
+// is replaced with U+FFFD, as encoding/json does.
+func writeJSONString(w io.Writer, parts ...string) error {
+	var err error
+	write := func(s string) {
+		if err == nil && s != "" {
+			_, err = io.WriteString(w, s)
+		}
+	}
+
+	write(`"`)
+	for _, s := range parts {
+		start := 0
+		for i := 0; i < len(s); {
+			if c := s[i]; c < utf8.RuneSelf {
+				if c >= 0x20 && c != '"' && c != '\\' {
+					i++
+					continue
+				}
+				write(s[start:i])
+				switch c {
+				case '"':
+					write(`\"`)
+				case '\\':
+					write(`\\`)
+				case '\n':
+					write(`\n`)
+				case '\r':
+					write(`\r`)
+				case '\t':
+					write(`\t`)
+				default:
+					write(fmt.Sprintf(`\u%04x`, c))
+				}
+				i++
+				start = i
+				continue
+			}
+
+			r, size := utf8.DecodeRuneInString(s[i:])
+			switch {
+			case r == utf8.RuneError && size == 1:
+				write(s[start:i])
+				write(`\ufffd`)
+			case r == '\u2028' || r == '\u2029':
+				// Valid JSON, but not valid JavaScript
+				write(s[start:i])
+				write(fmt.Sprintf(`\u%04x`, r))
+			default:
+				i += size
+				continue
+			}
+			i += size
+			start = i
+		}
+		write(s[start:])
+	}
+	write(`"`)
+	return err
+}
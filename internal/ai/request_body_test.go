@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSONString(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+	}{
+		{name: "empty", parts: nil},
+		{name: "plain", parts: []string{"feat: add login"}},
+		{name: "quotes and backslashes", parts: []string{`say "hi" \ bye`}},
+		{name: "control characters", parts: []string{"line\nnext\ttab\rret\x00\x1f"}},
+		{name: "multi-byte", parts: []string{"héllo wörld ✓ 日本"}},
+		{name: "line separators", parts: []string{"a\u2028b\u2029c"}},
+		{name: "html", parts: []string{"<b>&</b>"}},
+		{name: "invalid UTF-8", parts: []string{"bad \xff byte \xc3"}},
+		{name: "several parts", parts: []string{"instructions\n\n", "diff --git a/x b/x\n+\"q\"\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeJSONString(&buf, tt.parts...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got string
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+			}
+			// Decoding what encoding/json produces gives the expected string,
+			// including its replacement of invalid UTF-8
+			encoded, _ := json.Marshal(strings.Join(tt.parts, ""))
+			var want string
+			_ = json.Unmarshal(encoded, &want)
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestJSONObjectBody(t *testing.T) {
+	value := func(w io.Writer) error { return writeJSONString(w, "big value") }
+	tests := []struct {
+		name     string
+		envelope any
+		want     string
+	}{
+		{name: "with fields", envelope: map[string]any{"model": "m", "stream": false}, want: `{"model":"m","stream":false,"prompt":"big value"}`},
+		{name: "empty envelope", envelope: map[string]any{}, want: `{"prompt":"big value"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := jsonObjectBody(tt.envelope, "prompt", value)(&buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %s, want %s", buf.String(), tt.want)
+			}
+		})
+	}
+
+	if err := jsonObjectBody([]string{"x"}, "prompt", value)(io.Discard); err == nil {
+		t.Error("expected an error for an envelope that isn't an object")
+	}
+}
+
+func TestOpenAIClient_BuildRequest(t *testing.T) {
+	c := &OpenAIClient{model: "gpt", extraOptions: map[string]any{"temperature": 0.2, "messages": "ignored"}}
+	data, err := io.ReadAll(bodyReader(c.buildRequest("be brief", "diff")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	want := map[string]any{
+		"model":       "gpt",
+		"stream":      false,
+		"temperature": 0.2,
+		"messages": []any{
+			map[string]any{"role": "system", "content": "be brief"},
+			map[string]any{"role": "user", "content": "diff"},
+		},
+	}
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("got %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+// benchmarkPrompt is a multi-MB diff-like prompt
+var benchmarkPrompt = strings.Repeat("+\tfmt.Println(\"line of a large staged change\")\n", 100_000)
+
+// BenchmarkRequestBody_Marshal encodes the whole request up front, as
+// requests were built before bodies were streamed
+func BenchmarkRequestBody_Marshal(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := json.Marshal(ollamaRequest{Model: "m", Prompt: "instructions\n\n" + benchmarkPrompt})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, bytes.NewBuffer(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRequestBody_Stream streams the same request as the HTTP client
+// reads it
+func BenchmarkRequestBody_Stream(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		body := jsonObjectBody(ollamaRequest{Model: "m"}, "prompt", func(w io.Writer) error {
+			return writeJSONString(w, "instructions\n\n", benchmarkPrompt)
+		})
+		r := bodyReader(body)
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+		r.Close()
+	}
+}
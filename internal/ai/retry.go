@@ -0,0 +1,152 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Retry defaults, used when Options leaves them unset
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 2 * time.Second
+)
+
+// maxRetryDelay caps a single wait, including one requested by a
+// Retry-After header, and maxTotalRetryWait caps the time spent waiting
+// across all retries of a request
+const (
+	maxRetryDelay     = time.Minute
+	maxTotalRetryWait = 2 * time.Minute
+)
+
+// retryPolicy decides how often and how long to wait before retrying a
+// failed request. The zero value uses the defaults.
+type retryPolicy struct {
+	// maxRetries of 0 uses the default; a negative value disables retries
+	maxRetries int
+	// baseDelay of 0 uses the default
+	baseDelay time.Duration
+	// notices receives a line before each retry; nil writes to os.Stderr
+	notices io.Writer
+	// color prints the notices in yellow
+	color bool
+}
+
+// notify writes a retry notice
+func (p retryPolicy) notify(format string, args ...any) {
+	w := p.notices
+	if w == nil {
+		w = os.Stderr
+	}
+	notice := fmt.Sprintf(format, args...)
+	if p.color {
+		notice = "\033[33m" + notice + "\033[0m"
+	}
+	fmt.Fprintln(w, notice)
+}
+
+// retries returns the maximum number of retries
+func (p retryPolicy) retries() int {
+	switch {
+	case p.maxRetries == 0:
+		return defaultMaxRetries
+	case p.maxRetries < 0:
+		return 0
+	}
+	return p.maxRetries
+}
+
+// delay returns how long to wait before retry number attempt (starting at
+// 1). A Retry-After header on the failed response takes precedence over
+// the exponential backoff.
+func (p retryPolicy) delay(attempt int, header http.Header) time.Duration {
+	delay, ok := parseRetryAfter(header.Get("Retry-After"), time.Now())
+	if !ok {
+		// base, 2x base, 4x base, ...
+		delay = p.baseDelay
+		if delay <= 0 {
+			delay = defaultRetryBaseDelay
+		}
+		for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+			delay *= 2
+		}
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// parseRetryAfter parses a Retry-After value, either a number of seconds
+// or an HTTP date, relative to now
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// isRetryableStatus reports whether a response status is worth retrying:
+// gateway and overload errors that usually clear up on their own
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientError reports whether a request error is a network failure
+// worth retrying: timeouts, failed dials, and dropped connections
+func isTransientError(err error) bool {
+	if IsConnectionError(err) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// retryableError marks a failure that was still worth retrying when the
+// retries ran out, e.g. a rate limit or an unreachable server
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// IsRetryable reports whether err is a failure that may clear up or go
+// away on another endpoint: a rate limit, a server error or a network
+// failure that outlasted the retries, or an unreachable API
+func IsRetryable(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable) || IsConnectionError(err)
+}
+
+// IsConnectionError reports whether err means the API could not be
+// reached at all: a failed dial or a timeout
+func IsConnectionError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
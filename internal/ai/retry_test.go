@@ -0,0 +1,247 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", ok: false},
+		{value: "5", expected: 5 * time.Second, ok: true},
+		{value: "0", expected: 0, ok: true},
+		{value: "-1", ok: false},
+		{value: "soon", ok: false},
+		{value: "Wed, 01 Jan 2025 12:00:30 GMT", expected: 30 * time.Second, ok: true},
+		{value: "Wed, 01 Jan 2025 11:00:00 GMT", expected: 0, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("parseRetryAfter(%q) = %v, %v; expected %v, %v", tt.value, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name            string
+		policy          retryPolicy
+		attempt         int
+		retryAfter      string
+		expectedRetries int
+		expectedDelay   time.Duration
+	}{
+		{name: "Defaults", attempt: 1, expectedRetries: 3, expectedDelay: 2 * time.Second},
+		{name: "Exponential", attempt: 3, expectedRetries: 3, expectedDelay: 8 * time.Second},
+		{name: "Configured", policy: retryPolicy{maxRetries: 5, baseDelay: 100 * time.Millisecond}, attempt: 2, expectedRetries: 5, expectedDelay: 200 * time.Millisecond},
+		{name: "Disabled", policy: retryPolicy{maxRetries: -1}, attempt: 1, expectedRetries: 0, expectedDelay: 2 * time.Second},
+		{name: "Retry-After wins", attempt: 3, retryAfter: "1", expectedRetries: 3, expectedDelay: time.Second},
+		{name: "Retry-After capped", attempt: 1, retryAfter: "3600", expectedRetries: 3, expectedDelay: maxRetryDelay},
+		{name: "Backoff capped", attempt: 20, expectedRetries: 3, expectedDelay: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.retryAfter != "" {
+				header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := tt.policy.retries(); got != tt.expectedRetries {
+				t.Errorf("expected %d retries, got %d", tt.expectedRetries, got)
+			}
+			if got := tt.policy.delay(tt.attempt, header); got != tt.expectedDelay {
+				t.Errorf("expected delay %v, got %v", tt.expectedDelay, got)
+			}
+		})
+	}
+}
+
+func TestPostWithRetry_RetryAfter(t *testing.T) {
+	slept := stubSleep(t)
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"response": "ok"}`))
+	}))
+	defer server.Close()
+
+	var notices strings.Builder
+	body, err := postWithRetry(context.Background(), server.Client(), server.URL, "key", emptyBody, nil, retryPolicy{notices: &notices})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(body) != `{"response": "ok"}` {
+		t.Errorf("unexpected body %q", body)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 7*time.Second {
+		t.Errorf("expected a single 7s wait from Retry-After, got %v", *slept)
+	}
+	if !strings.Contains(notices.String(), "Retrying in 7s") {
+		t.Errorf("expected a retry notice, got %q", notices.String())
+	}
+}
+
+func TestPostWithRetry_Limits(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        retryPolicy
+		retryAfter    string
+		expectedCalls int
+		expectedErr   string
+	}{
+		{name: "Max retries", policy: retryPolicy{maxRetries: 1}, expectedCalls: 2, expectedErr: "after 1 retries"},
+		{name: "Retries disabled", policy: retryPolicy{maxRetries: -1}, expectedCalls: 1, expectedErr: "after 0 retries"},
+		{name: "Total wait", policy: retryPolicy{maxRetries: 10}, retryAfter: "60", expectedCalls: 3, expectedErr: "more than 2m0s in total"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubSleep(t)
+
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+
+			_, err := postWithRetry(context.Background(), server.Client(), server.URL, "key", emptyBody, nil, tt.policy)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestPostWithRetry_Status(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		expectedCalls int
+		expectedErr   string
+	}{
+		{name: "Service unavailable then OK", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, expectedCalls: 2},
+		{name: "Bad gateway then OK", statuses: []int{http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusOK}, expectedCalls: 3},
+		{name: "Unauthorized fails fast", statuses: []int{http.StatusUnauthorized, http.StatusOK}, expectedCalls: 1, expectedErr: "401 Unauthorized"},
+		{name: "Not found fails fast", statuses: []int{http.StatusNotFound, http.StatusOK}, expectedCalls: 1, expectedErr: "404 Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubSleep(t)
+
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+				w.Write([]byte("body"))
+			}))
+			defer server.Close()
+
+			body, err := postWithRetry(context.Background(), server.Client(), server.URL, "key", emptyBody, nil, retryPolicy{})
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+			} else if err != nil || string(body) != "body" {
+				t.Fatalf("expected body, got %q, %v", body, err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestPostWithRetry_NetworkError(t *testing.T) {
+	slept := stubSleep(t)
+
+	// A closed server refuses connections, so every attempt fails to dial
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	_, err := postWithRetry(context.Background(), http.DefaultClient, url, "key", emptyBody, nil, retryPolicy{maxRetries: 2})
+	if err == nil || !strings.Contains(err.Error(), "after 2 retries") {
+		t.Fatalf("expected error after 2 retries, got %v", err)
+	}
+	if !IsConnectionError(err) {
+		t.Errorf("expected a connection error, got %v", err)
+	}
+	if len(*slept) != 2 {
+		t.Errorf("expected 2 backoffs, got %d", len(*slept))
+	}
+}
+
+// emptyBody writes an empty JSON object
+func emptyBody(w io.Writer) error {
+	_, err := io.WriteString(w, "{}")
+	return err
+}
+
+func TestNewClient_RetryNotices(t *testing.T) {
+	tests := []struct {
+		name     string
+		color    bool
+		expected string
+	}{
+		{name: "Plain", expected: "API returned 503 Service Unavailable. Retrying in 2s...\n"},
+		{name: "Color", color: true, expected: "\033[33mAPI returned 503 Service Unavailable. Retrying in 2s...\033[0m\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubSleep(t)
+
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`{"response": "feat: add login"}`))
+			}))
+			defer server.Close()
+
+			var status strings.Builder
+			client, err := NewClient(Options{BaseURL: server.URL, Status: &status, Color: tt.color})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if _, err := client.GenerateCommitMessage(context.Background(), "diff", ""); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if status.String() != tt.expected {
+				t.Errorf("expected notice %q, got %q", tt.expected, status.String())
+			}
+		})
+	}
+}
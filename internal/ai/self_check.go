@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SelfCheckResult is the model's assessment of a generated message
+type SelfCheckResult struct {
+	// Confidence is 0-100, how sure the model is that the message follows
+	// the rules and describes the diff accurately
+	Confidence int `json:"confidence"`
+	// Issues briefly lists any problems found
+	Issues string `json:"issues"`
+}
+
+// checkMessage asks the model to review message against the diff and rules
+func checkMessage(ctx context.Context, c completer, message, diff, rules string, opts promptOptions) (*SelfCheckResult, error) {
+	response, err := c.complete(ctx, buildSelfCheckInstructions(message, rules, opts), buildDiffPrompt(diff))
+	if err != nil {
+		return nil, err
+	}
+	return parseSelfCheck(response)
+}
+
+// buildSelfCheckInstructions returns the prompt asking the model to grade
+// a commit message
+func buildSelfCheckInstructions(message, rules string, opts promptOptions) string {
+	var sb strings.Builder
+	sb.WriteString("You are reviewing a git commit message written for the diff below.\n\n")
+	sb.WriteString("Check that it follows the Conventional Commits format " + opts.subjectFormat() + ", ")
+	sb.WriteString("uses one of the allowed types, ")
+	sb.WriteString("accurately describes the diff, and follows the team rules.\n\n")
+	opts.writeMessageRules(&sb)
+	sb.WriteString("Respond with only a JSON object, no other text, in this format:\n")
+	sb.WriteString(`{"confidence": <0-100>, "issues": "<problems found, or empty>"}`)
+	sb.WriteString("\n\n")
+
+	if rules != "" {
+		sb.WriteString("Team Rules:\n")
+		sb.WriteString(rules)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("Commit message:\n")
+	sb.WriteString(message)
+	sb.WriteString("\n\n")
+	return sb.String()
+}
+
+// parseSelfCheck extracts the JSON object from the model response,
+// tolerating surrounding prose or Markdown code fences
+func parseSelfCheck(response string) (*SelfCheckResult, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no self-check result found in model response")
+	}
+
+	var result SelfCheckResult
+	if err := json.Unmarshal([]byte(response[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse self-check result: %w", err)
+	}
+	if result.Confidence < 0 || result.Confidence > 100 {
+		return nil, fmt.Errorf("self-check confidence %d out of range 0-100", result.Confidence)
+	}
+	return &result, nil
+}
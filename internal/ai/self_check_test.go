@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSelfCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		expected    SelfCheckResult
+		expectedErr string
+	}{
+		{
+			name:     "Plain JSON",
+			response: `{"confidence": 92, "issues": ""}`,
+			expected: SelfCheckResult{Confidence: 92},
+		},
+		{
+			name:     "Code fence",
+			response: "```json\n{\"confidence\": 40, \"issues\": \"missing scope\"}\n```",
+			expected: SelfCheckResult{Confidence: 40, Issues: "missing scope"},
+		},
+		{
+			name:        "No JSON",
+			response:    "Looks good to me",
+			expectedErr: "no self-check result found",
+		},
+		{
+			name:        "Out of range",
+			response:    `{"confidence": 150}`,
+			expectedErr: "out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseSelfCheck(tt.response)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if *result != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *result)
+			}
+		})
+	}
+}
+
+func TestOllamaClient_CheckMessage(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		prompt = string(body)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"response": "{\"confidence\": 85, \"issues\": \"\"}", "done": true}`))
+	}))
+	defer server.Close()
+
+	client := &OllamaClient{
+		apiKey:  "test-api-key",
+		baseURL: server.URL + "/api/generate",
+		client: &http.Client{
+			Timeout: 1 * time.Second,
+		},
+	}
+
+	result, err := client.CheckMessage(context.Background(), "feat: add login", "diff", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Confidence != 85 {
+		t.Errorf("expected confidence 85, got %d", result.Confidence)
+	}
+	if !strings.Contains(prompt, "feat: add login") {
+		t.Errorf("expected prompt to contain the message under review")
+	}
+}
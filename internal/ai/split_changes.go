@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChangeGroup is one logical commit suggested by SplitChanges
+type ChangeGroup struct {
+	Description string   `json:"description"`
+	Files       []string `json:"files"`
+}
+
+// completer sends a prompt to a provider and returns the raw model text.
+// Instructions and input are kept apart so chat providers can send them as
+// separate messages.
+type completer interface {
+	complete(ctx context.Context, instructions, input string) (string, error)
+}
+
+// splitChanges asks the model to group the diff and parses its JSON reply
+func splitChanges(ctx context.Context, c completer, diff, rules string) ([]ChangeGroup, error) {
+	response, err := c.complete(ctx, buildSplitInstructions(rules), buildDiffPrompt(diff))
+	if err != nil {
+		return nil, err
+	}
+	return parseChangeGroups(response)
+}
+
+// buildSplitInstructions returns the prompt asking for file groups as JSON
+func buildSplitInstructions(rules string) string {
+	var sb strings.Builder
+	sb.WriteString("You are an expert DevOps engineer specialized in writing git commit messages.\n\n")
+	sb.WriteString("Analyze the following code diff and split it into the smallest set of independent logical changes, each of which should be its own commit.\n\n")
+	sb.WriteString("Every file in the diff must appear in exactly one group.\n\n")
+	sb.WriteString("Respond with only a JSON array, no other text, in this format:\n")
+	sb.WriteString(`[{"description": "<short purpose of the commit>", "files": ["<path>", "..."]}]`)
+	sb.WriteString("\n\n")
+
+	if rules != "" {
+		sb.WriteString("Team Rules:\n")
+		sb.WriteString(rules)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// parseChangeGroups extracts the JSON array from the model response,
+// tolerating surrounding prose or Markdown code fences
+func parseChangeGroups(response string) ([]ChangeGroup, error) {
+	start := strings.Index(response, "[")
+	end := strings.LastIndex(response, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no change groups found in model response")
+	}
+
+	var groups []ChangeGroup
+	if err := json.Unmarshal([]byte(response[start:end+1]), &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse change groups: %w", err)
+	}
+	return groups, nil
+}
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseChangeGroups(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		expected    []ChangeGroup
+		expectedErr string
+	}{
+		{
+			name:     "Plain JSON",
+			response: `[{"description": "auth", "files": ["auth.go"]}, {"description": "docs", "files": ["README.md"]}]`,
+			expected: []ChangeGroup{
+				{Description: "auth", Files: []string{"auth.go"}},
+				{Description: "docs", Files: []string{"README.md"}},
+			},
+		},
+		{
+			name:     "Code fence",
+			response: "```json\n[{\"description\": \"ui\", \"files\": [\"a.css\", \"b.css\"]}]\n```",
+			expected: []ChangeGroup{
+				{Description: "ui", Files: []string{"a.css", "b.css"}},
+			},
+		},
+		{
+			name:        "No JSON",
+			response:    "This is a single change.",
+			expectedErr: "no change groups found",
+		},
+		{
+			name:        "Malformed JSON",
+			response:    `[{"description": }]`,
+			expectedErr: "failed to parse change groups",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := parseChangeGroups(tt.response)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(groups, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, groups)
+			}
+		})
+	}
+}
+
+func TestOllamaClient_SplitChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"response": "[{\"description\": \"auth\", \"files\": [\"auth.go\"]}]", "done": true}`))
+	}))
+	defer server.Close()
+
+	client := &OllamaClient{
+		apiKey:  "test-api-key",
+		baseURL: server.URL + "/api/generate",
+		client: &http.Client{
+			Timeout: 1 * time.Second,
+		},
+	}
+
+	groups, err := client.SplitChanges(context.Background(), "diff", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(groups) != 1 || groups[0].Description != "auth" || !reflect.DeepEqual(groups[0].Files, []string{"auth.go"}) {
+		t.Errorf("unexpected groups: %+v", groups)
+	}
+}
@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Message styles for Options.Style
+const (
+	// StyleConventional writes plain Conventional Commits (the default)
+	StyleConventional = "conventional"
+	// StyleGitmoji prefixes Conventional Commits with the gitmoji for
+	// their type, e.g. "✨ feat: add login"
+	StyleGitmoji = "gitmoji"
+)
+
+// DefaultTypes are the conventional commit types allowed when no others
+// are configured, in the order they are listed in the prompt
+var DefaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "test", "chore"}
+
+// gitmojis maps conventional types to their gitmoji. Types without one are
+// listed without an emoji in the gitmoji style.
+var gitmojis = map[string]string{
+	"feat":     "✨",
+	"fix":      "🐛",
+	"docs":     "📝",
+	"style":    "🎨",
+	"refactor": "♻️",
+	"test":     "✅",
+	"chore":    "🔧",
+	"perf":     "⚡️",
+	"ci":       "👷",
+	"build":    "📦",
+	"revert":   "⏪",
+}
+
+// promptOptions tunes the instructions sent to the model
+type promptOptions struct {
+	style string
+	// language is the language of the message text, e.g. "fr". Empty or
+	// "en" leaves it to the default, English.
+	language string
+	// template, if set, replaces the built-in commit message prompt
+	template *template.Template
+	// types are the allowed conventional types. Empty uses DefaultTypes.
+	types []string
+	// status receives template warnings; nil writes to os.Stderr
+	status io.Writer
+}
+
+// statusWriter returns where template warnings go
+func (p promptOptions) statusWriter() io.Writer {
+	if p.status == nil {
+		return os.Stderr
+	}
+	return p.status
+}
+
+// PromptData is the data a custom prompt template is rendered with
+type PromptData struct {
+	Diff  string
+	Rules string
+	// Stats lists the lines changed per file and in total
+	Stats string
+}
+
+// newPromptOptions builds the prompt options for a style, a language and
+// an optional custom template. A template that doesn't parse is ignored
+// with a warning to status.
+func newPromptOptions(style, language, text string, status io.Writer) promptOptions {
+	opts := promptOptions{style: style, language: language, status: status}
+	if text == "" {
+		return opts
+	}
+	tmpl, err := template.New("prompt").Parse(text)
+	if err != nil {
+		fmt.Fprintf(opts.statusWriter(), "Warning: invalid prompt template, using the default prompt: %v\n", err)
+		return opts
+	}
+	opts.template = tmpl
+	return opts
+}
+
+// messagePrompt returns the instructions and input asking for a commit
+// message, with a body if withBody is set. A custom template is rendered
+// as the whole input, with no separate instructions; if it fails to
+// render, the built-in prompt is used with a warning.
+func (p promptOptions) messagePrompt(diff, rules string, withBody bool) (instructions, input string) {
+	if p.template != nil {
+		var sb strings.Builder
+		err := p.template.Execute(&sb, PromptData{Diff: diff, Rules: rules, Stats: formatDiffStats(diff)})
+		if err == nil {
+			return "", sb.String()
+		}
+		fmt.Fprintf(p.statusWriter(), "Warning: failed to render prompt template, using the default prompt: %v\n", err)
+	}
+	if withBody {
+		return buildBodyInstructions(rules, p), buildDiffPrompt(diff)
+	}
+	return buildInstructions(rules, p), buildDiffPrompt(diff)
+}
+
+// subjectFormat returns the subject line format for the style
+func (p promptOptions) subjectFormat() string {
+	if p.style == StyleGitmoji {
+		return "<gitmoji> <type>(<scope>): <description>"
+	}
+	return "<type>(<scope>): <description>"
+}
+
+// writeMessageRules writes the allowed types instruction, with the gitmoji
+// for each type in the gitmoji style, and the language to write in
+func (p promptOptions) writeMessageRules(sb *strings.Builder) {
+	types := slices.Clone(allowedTypes(p.types))
+	if p.style == StyleGitmoji {
+		for i, t := range types {
+			if emoji, ok := gitmojis[t]; ok {
+				types[i] = emoji + " " + t
+			}
+		}
+	}
+	sb.WriteString("Allowed types: " + strings.Join(types, ", ") + ".\n\n")
+	if p.style == StyleGitmoji {
+		sb.WriteString("Start the message with the gitmoji listed for its type, followed by a space.\n\n")
+	}
+	if p.language != "" && p.language != "en" {
+		fmt.Fprintf(sb, "Write the description and any body in the language %q, but keep the type and scope in English.\n\n", p.language)
+	}
+}
+
+// conventionalSubject matches "<type>(<scope>)!: <description>", optionally
+// preceded by an emoji such as a gitmoji
+var conventionalSubject = regexp.MustCompile(`^(?:[^\sA-Za-z0-9]+\s+)?([a-z]+)(?:\(([^()\s][^()]*)\))?(!?): (\S.*)$`)
+
+// ConventionalSubject is the parsed subject line of a Conventional Commit
+type ConventionalSubject struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// ParseConventional parses the subject line of msg, reporting false if it
+// doesn't match <type>(<scope>): <description>. The type is not checked
+// against the allowed types.
+func ParseConventional(msg string) (ConventionalSubject, bool) {
+	subject, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	m := conventionalSubject.FindStringSubmatch(subject)
+	if m == nil {
+		return ConventionalSubject{}, false
+	}
+	return ConventionalSubject{Type: m[1], Scope: m[2], Breaking: m[3] == "!", Description: m[4]}, true
+}
+
+// allowedTypes returns types, or DefaultTypes if it is empty
+func allowedTypes(types []string) []string {
+	if len(types) == 0 {
+		return DefaultTypes
+	}
+	return types
+}
+
+// breakingFooter matches a breaking change footer line. Conventional
+// Commits allows "BREAKING-CHANGE" as a synonym.
+var breakingFooter = regexp.MustCompile(`^BREAKING[ -]CHANGE: \S`)
+
+// IsBreaking reports whether msg marks a breaking change, with a "!"
+// after the type or scope or with a "BREAKING CHANGE: " footer
+func IsBreaking(msg string) bool {
+	if parsed, ok := ParseConventional(msg); ok && parsed.Breaking {
+		return true
+	}
+	_, body, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	for _, line := range strings.Split(body, "\n") {
+		if breakingFooter.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateConventional checks that the subject line of msg follows the
+// Conventional Commits grammar <type>(<scope>): <description> with one of
+// the allowed types (DefaultTypes if types is empty). A leading gitmoji is
+// accepted. A breaking change footer must read "BREAKING CHANGE: <description>".
+func ValidateConventional(msg string, types []string) error {
+	subject, body, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	if subject == "" {
+		return fmt.Errorf("message is empty")
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if (strings.HasPrefix(line, "BREAKING CHANGE") || strings.HasPrefix(line, "BREAKING-CHANGE")) && !breakingFooter.MatchString(line) {
+			return fmt.Errorf("footer %q does not match BREAKING CHANGE: <description>", line)
+		}
+	}
+	m := conventionalSubject.FindStringSubmatch(subject)
+	if m == nil {
+		return fmt.Errorf("subject %q does not match <type>(<scope>): <description>", subject)
+	}
+	types = allowedTypes(types)
+	if slices.Contains(types, m[1]) {
+		return nil
+	}
+	return fmt.Errorf("type %q is not one of %s", m[1], strings.Join(types, ", "))
+}
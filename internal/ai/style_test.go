@@ -0,0 +1,299 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildInstructions_Style(t *testing.T) {
+	tests := []struct {
+		name        string
+		style       string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "Conventional",
+			style:       StyleConventional,
+			contains:    []string{"<type>(<scope>): <description>", "Allowed types: feat, fix, docs, style, refactor, test, chore."},
+			notContains: []string{"gitmoji", "✨"},
+		},
+		{
+			name:        "Default",
+			contains:    []string{"Allowed types: feat, fix, docs, style, refactor, test, chore."},
+			notContains: []string{"gitmoji"},
+		},
+		{
+			name:  "Gitmoji",
+			style: StyleGitmoji,
+			contains: []string{
+				"<gitmoji> <type>(<scope>): <description>",
+				"Allowed types: ✨ feat, 🐛 fix, 📝 docs, 🎨 style, ♻️ refactor, ✅ test, 🔧 chore.",
+				"Start the message with the gitmoji listed for its type",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := promptOptions{style: tt.style}
+			for _, prompt := range []string{
+				buildInstructions("", opts),
+				buildBodyInstructions("", opts),
+				buildSelfCheckInstructions("feat: add login", "", opts),
+			} {
+				for _, s := range tt.contains {
+					if !strings.Contains(prompt, s) {
+						t.Errorf("expected prompt to contain %q, got:\n%s", s, prompt)
+					}
+				}
+				for _, s := range tt.notContains {
+					if strings.Contains(prompt, s) {
+						t.Errorf("expected prompt not to contain %q, got:\n%s", s, prompt)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewClient_Style(t *testing.T) {
+	client, err := NewClient(Options{Style: StyleGitmoji})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if prompt := client.BuildPrompt("diff", ""); !strings.Contains(prompt, "✨ feat") {
+		t.Errorf("expected gitmoji instructions in prompt, got:\n%s", prompt)
+	}
+}
+
+func TestPromptOptions_Template(t *testing.T) {
+	tests := []struct {
+		name                 string
+		template             string
+		expectedInstructions bool
+		expectedInput        string
+		expectedWarning      bool
+	}{
+		{
+			name:          "Rendered",
+			template:      "Rules: {{.Rules}}\nWrite a commit message for:\n{{.Diff}}",
+			expectedInput: "Rules: - use past tense\nWrite a commit message for:\n+added line",
+		},
+		{
+			name:                 "Parse error falls back",
+			template:             "Diff: {{.Diff",
+			expectedInstructions: true,
+			expectedInput:        "Diff:\n+added line",
+			expectedWarning:      true,
+		},
+		{
+			name:                 "Render error falls back",
+			template:             "Diff: {{.Patch}}",
+			expectedInstructions: true,
+			expectedInput:        "Diff:\n+added line",
+			expectedWarning:      true,
+		},
+		{
+			name:                 "No template",
+			expectedInstructions: true,
+			expectedInput:        "Diff:\n+added line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status strings.Builder
+			opts := newPromptOptions(StyleConventional, "", tt.template, &status)
+			for _, withBody := range []bool{false, true} {
+				instructions, input := opts.messagePrompt("+added line", "- use past tense", withBody)
+				if (instructions != "") != tt.expectedInstructions {
+					t.Errorf("expected built-in instructions %v, got %q", tt.expectedInstructions, instructions)
+				}
+				if input != tt.expectedInput {
+					t.Errorf("expected input %q, got %q", tt.expectedInput, input)
+				}
+			}
+			if strings.Contains(status.String(), "Warning") != tt.expectedWarning {
+				t.Errorf("expected warning %v, got status %q", tt.expectedWarning, status.String())
+			}
+		})
+	}
+}
+
+func TestBuildInstructions_Language(t *testing.T) {
+	tests := []struct {
+		language string
+		expected bool
+	}{
+		{language: "", expected: false},
+		{language: "en", expected: false},
+		{language: "fr", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			opts := promptOptions{language: tt.language}
+			for _, prompt := range []string{
+				buildInstructions("", opts),
+				buildBodyInstructions("", opts),
+				buildSelfCheckInstructions("feat: ajoute la connexion", "", opts),
+			} {
+				got := strings.Contains(prompt, `in the language "fr", but keep the type and scope in English`)
+				if got != tt.expected {
+					t.Errorf("expected language instruction %v, got prompt:\n%s", tt.expected, prompt)
+				}
+				if strings.Contains(prompt, "in the language \"en\"") {
+					t.Errorf("expected no instruction for English, got prompt:\n%s", prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConventional(t *testing.T) {
+	tests := []struct {
+		message     string
+		types       []string
+		expectedErr string
+	}{
+		{message: "feat: add login"},
+		{message: "fix(api): handle 503 responses"},
+		{message: "refactor(git)!: drop the shell fallback"},
+		{message: "docs(readme): describe --body\n\nMentions include_body too."},
+		{message: "✨ feat(auth): add login"},
+		{message: "♻️ refactor: split the client"},
+		{message: "", expectedErr: "message is empty"},
+		{message: "This commit adds a login form", expectedErr: "does not match"},
+		{message: "Add login form", expectedErr: "does not match"},
+		{message: "feat add login", expectedErr: "does not match"},
+		{message: "feat:add login", expectedErr: "does not match"},
+		{message: "feat(): add login", expectedErr: "does not match"},
+		{message: "feat: ", expectedErr: "does not match"},
+		{message: "wip feat: add login", expectedErr: "does not match"},
+		{message: "Feat: add login", expectedErr: "does not match"},
+		{message: "perf: cache the index", expectedErr: `type "perf" is not one of feat, fix, docs, style, refactor, test, chore`},
+		{message: "perf: cache the index", types: []string{"feat", "fix", "perf"}},
+		{message: "⚡️ perf: cache the index", types: []string{"perf"}},
+		{message: "chore: bump deps", types: []string{"feat", "fix", "build"}, expectedErr: `type "chore" is not one of feat, fix, build`},
+		{message: "feat(api)!: drop the v1 endpoints"},
+		{message: "feat(api): drop the v1 endpoints\n\nBREAKING CHANGE: clients must use /v2"},
+		{message: "feat(api): drop the v1 endpoints\n\nBREAKING-CHANGE: clients must use /v2"},
+		{message: "feat(api): drop the v1 endpoints\n\nBREAKING CHANGE clients must use /v2", expectedErr: `footer "BREAKING CHANGE clients must use /v2" does not match`},
+		{message: "feat(api): drop the v1 endpoints\n\nBREAKING CHANGE:", expectedErr: "does not match BREAKING CHANGE: <description>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			err := ValidateConventional(tt.message, tt.types)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseConventional(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected ConventionalSubject
+		ok       bool
+	}{
+		{message: "feat: add login", expected: ConventionalSubject{Type: "feat", Description: "add login"}, ok: true},
+		{message: "fix(api): handle 503\n\nBody.", expected: ConventionalSubject{Type: "fix", Scope: "api", Description: "handle 503"}, ok: true},
+		{message: "refactor(git)!: drop the shell fallback", expected: ConventionalSubject{Type: "refactor", Scope: "git", Breaking: true, Description: "drop the shell fallback"}, ok: true},
+		{message: "✨ feat(auth): add login", expected: ConventionalSubject{Type: "feat", Scope: "auth", Description: "add login"}, ok: true},
+		{message: "perf: cache the index", expected: ConventionalSubject{Type: "perf", Description: "cache the index"}, ok: true},
+		{message: "Add login form"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got, ok := ParseConventional(tt.message)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("expected %+v, %v, got %+v, %v", tt.expected, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestBuildDiffPrompt_Stats(t *testing.T) {
+	diff := "diff --git a/cmd/main.go b/cmd/main.go\n" +
+		"--- a/cmd/main.go\n+++ b/cmd/main.go\n@@ -1,2 +1,2 @@\n-old\n+new\n+more\n" +
+		"diff --git a/docs/guide.md b/docs/guide.md\n" +
+		"new file mode 100644\n--- /dev/null\n+++ b/docs/guide.md\n@@ -0,0 +1 @@\n+# Guide\n" +
+		"diff --git a/logo.png b/logo.png\n" +
+		"deleted file mode 100644\nBinary files a/logo.png and /dev/null differ\n"
+
+	expected := "Diff stats:\n" +
+		" cmd/main.go | +2 -1\n" +
+		" docs/guide.md (added) | +1 -0\n" +
+		" logo.png (deleted) | binary\n" +
+		" 3 files changed, 3 insertions(+), 1 deletion(-)\n\n" +
+		"Diff:\n" + diff
+	if got := buildDiffPrompt(diff); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// Custom templates get the stats too
+	opts := newPromptOptions(StyleConventional, "", "{{.Stats}}", nil)
+	if _, input := opts.messagePrompt(diff, "", false); !strings.HasSuffix(input, "3 files changed, 3 insertions(+), 1 deletion(-)") {
+		t.Errorf("expected the stats in the template, got %q", input)
+	}
+}
+
+func TestNewClient_AllowedTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{name: "Default", expected: "Allowed types: feat, fix, docs, style, refactor, test, chore."},
+		{name: "Custom", opts: Options{AllowedTypes: []string{"feat", "fix", "perf", "ci"}}, expected: "Allowed types: feat, fix, perf, ci."},
+		{name: "Custom gitmoji", opts: Options{Style: StyleGitmoji, AllowedTypes: []string{"perf", "deps"}}, expected: "Allowed types: ⚡️ perf, deps."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			for _, prompt := range []string{client.BuildPrompt("diff", ""), client.BuildBodyPrompt("diff", "")} {
+				if !strings.Contains(prompt, tt.expected) {
+					t.Errorf("expected %q in prompt, got:\n%s", tt.expected, prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestIsBreaking(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{message: "feat(api)!: drop the v1 endpoints", expected: true},
+		{message: "refactor!: rename Client to Conn", expected: true},
+		{message: "💥 feat!: drop Go 1.21", expected: true},
+		{message: "feat(api): drop the v1 endpoints\n\nBREAKING CHANGE: clients must use /v2", expected: true},
+		{message: "feat(api): drop the v1 endpoints\n\nMigrate first.\n\nBREAKING-CHANGE: clients must use /v2", expected: true},
+		{message: "feat(api): add the v2 endpoints"},
+		{message: "fix: mention that this is not a BREAKING CHANGE: promise\n\nNo breaking change here."},
+		{message: "docs: explain breaking changes\n\nDescribe the BREAKING CHANGE: footer."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := IsBreaking(tt.message); got != tt.expected {
+				t.Errorf("IsBreaking(%q) = %v, expected %v", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsConfig returns the TLS settings for API requests: the system roots
+// plus the PEM certificates in caCertFile, if set. insecureSkipVerify
+// turns off certificate verification. It returns nil when neither is set,
+// keeping the transport's defaults.
+func tlsConfig(caCertFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	if caCertFile == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if caCertFile == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA certificate file %s", caCertFile)
+	}
+	config.RootCAs = pool
+	return config, nil
+}
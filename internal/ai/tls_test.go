@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCertFile writes the server's certificate as PEM to a temp file
+func writeCertFile(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	return path
+}
+
+func TestTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	certFile := writeCertFile(t, server)
+
+	config, err := tlsConfig("", false)
+	if err != nil || config != nil {
+		t.Errorf("expected the default TLS config, got %v, %v", config, err)
+	}
+
+	config, err = tlsConfig(certFile, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if config.InsecureSkipVerify {
+		t.Error("expected certificates to be verified")
+	}
+	if _, err := server.Certificate().Verify(x509.VerifyOptions{Roots: config.RootCAs}); err != nil {
+		t.Errorf("expected the CA pool to trust the certificate, got %v", err)
+	}
+
+	config, err = tlsConfig("", true)
+	if err != nil || !config.InsecureSkipVerify {
+		t.Errorf("expected verification to be skipped, got %v, %v", config, err)
+	}
+
+	if _, err := tlsConfig(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0644)
+	if _, err := tlsConfig(notPEM, false); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("expected an error for a file without certificates, got %v", err)
+	}
+}
+
+func TestNewClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "feat: add login"}`))
+	}))
+	defer server.Close()
+	certFile := writeCertFile(t, server)
+
+	tests := []struct {
+		name        string
+		opts        Options
+		expectedErr string
+	}{
+		{name: "Untrusted", opts: Options{MaxRetries: -1}, expectedErr: "certificate"},
+		{name: "Custom CA", opts: Options{CACertFile: certFile}},
+		{name: "Skip verify", opts: Options{InsecureSkipVerify: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.BaseURL = server.URL
+			client, err := NewClient(tt.opts)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			_, err = client.GenerateCommitMessage(context.Background(), "diff", "")
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
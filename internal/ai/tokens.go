@@ -0,0 +1,17 @@
+package ai
+
+import "unicode/utf8"
+
+// charsPerToken is the rough number of characters in a token for English
+// text and code with common tokenizers
+const charsPerToken = 4
+
+// TokenEstimator returns the approximate number of tokens in text
+type TokenEstimator func(text string) int
+
+// EstimateTokens approximates the token count of text as one token per
+// four characters, rounded up. It avoids depending on a provider's
+// tokenizer, so it is only good enough for guarding the context window.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
@@ -0,0 +1,28 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "Empty", text: "", expected: 0},
+		{name: "Partial token rounds up", text: "abc", expected: 1},
+		{name: "Exact tokens", text: "abcdefgh", expected: 2},
+		{name: "Multi-byte characters count once", text: "héllo wörld", expected: 3},
+		{name: "Long diff", text: strings.Repeat("+line\n", 100), expected: 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateTokens(tt.text); got != tt.expected {
+				t.Errorf("EstimateTokens(%q) = %d, expected %d", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package app
+
+import "strings"
+
+// amendRule asks the model to refine the message of the commit being
+// amended instead of writing a new one. It is passed in the rules, the only
+// per-request text every ai.Client and custom commitgen.Model receives.
+func amendRule(previousMessage string) string {
+	var sb strings.Builder
+	sb.WriteString("This diff will be added to the previous commit with `git commit --amend`. Its message was:\n")
+	for _, line := range strings.Split(previousMessage, "\n") {
+		sb.WriteString("  > ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  Refine that message so it also covers this diff. Keep its type, scope, and wording where they still fit instead of rewriting it from scratch.")
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/git"
+)
+
+func TestApp_Run_Amend(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    string
+		previousErr error
+		opts        RunOptions
+		expectedErr string
+	}{
+		{
+			name:     "Previous message in prompt",
+			previous: "feat(auth): add login\n\nAdds the login form.",
+			opts:     RunOptions{Amend: true},
+		},
+		{
+			name:     "Commit amends HEAD",
+			previous: "feat(auth): add login",
+			opts:     RunOptions{Amend: true, Commit: true},
+		},
+		{
+			name:        "No commit to amend",
+			previousErr: errors.New("no commits yet"),
+			opts:        RunOptions{Amend: true},
+			expectedErr: "failed to read the commit to amend: no commits yet",
+		},
+		{
+			name:        "Other sources",
+			opts:        RunOptions{Amend: true, Source: "all"},
+			expectedErr: "only staged changes can be committed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentRules string
+			var committed, amended string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:         func() (bool, error) { return true, nil },
+				HasStagedChangesFunc:     func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:        func() (string, error) { return "diff", nil },
+				GetLastCommitMessageFunc: func() (string, error) { return tt.previous, tt.previousErr },
+				CommitWithMessageFunc: func(message string, opts git.CommitOptions) error {
+					committed = message
+					return nil
+				},
+				AmendWithMessageFunc: func(message string, opts git.CommitOptions) error {
+					amended = message
+					return nil
+				},
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sentRules = rules
+					return message("feat(auth): add login with remember me"), nil
+				},
+			})
+
+			result, err := app.Run(context.Background(), tt.opts)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, line := range strings.Split(tt.previous, "\n") {
+				if !strings.Contains(sentRules, "> "+line) {
+					t.Errorf("expected rules to quote %q, got %q", line, sentRules)
+				}
+			}
+			if !strings.Contains(sentRules, "Refine that message") {
+				t.Errorf("expected rules to ask for a refinement, got %q", sentRules)
+			}
+
+			if committed != "" {
+				t.Errorf("expected no new commit, got %q", committed)
+			}
+			if tt.opts.Commit {
+				if !result.Committed || amended != result.Message {
+					t.Errorf("expected HEAD to be amended with %q, got %q", result.Message, amended)
+				}
+			} else if amended != "" {
+				t.Errorf("expected no commit without --commit, got %q", amended)
+			}
+		})
+	}
+}
@@ -1,12 +1,17 @@
 package app
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"ai-commit-message-generator/internal/ai"
 	"ai-commit-message-generator/internal/config"
@@ -17,12 +22,130 @@ import (
 type App struct {
 	Git          git.Client
 	RulesLoader  config.Loader
-	ConfigLoader *config.ConfigLoader
+	ConfigLoader config.Store
 	AI           ai.Client
+
+	// Config holds the loaded settings. A nil Config uses defaults.
+	Config *config.Config
+
+	// Input is read for interactive prompts and for the stdin diff
+	// source. A nil Input reads os.Stdin.
+	Input  io.Reader
+	reader *bufio.Reader
+
+	// Status receives progress messages and warnings, keeping them out of
+	// the commit message printed on stdout. A nil Status writes to
+	// os.Stderr.
+	Status io.Writer
+
+	// EditMessage lets the user edit a message. A nil EditMessage opens
+	// the message in $EDITOR.
+	EditMessage func(message string) (string, error)
+
+	// EstimateTokens sizes prompts against max_prompt_tokens. A nil
+	// EstimateTokens uses ai.EstimateTokens.
+	EstimateTokens ai.TokenEstimator
+
+	// Color enables ANSI colors in the messages printed during interactive
+	// review and split. See ColorEnabled.
+	Color bool
+}
+
+// ErrMidMergeOrRebase is returned by Run while a merge or rebase is in
+// progress
+var ErrMidMergeOrRebase = errors.New("a merge or rebase is in progress; finish it with git's prepared message, or abort it (git merge --abort, git rebase --abort), before generating a message")
+
+// RunResult describes the outcome of a generate run
+type RunResult struct {
+	// Message is the generated commit message or split suggestion
+	Message string
+	// IsSplitSuggestion is true when the model suggested splitting the
+	// staged changes instead of producing a commit message
+	IsSplitSuggestion bool
+	// Model is the configured model name, if known
+	Model string
+	// DiffBytes is the size of the diff sent to the model
+	DiffBytes int
+	// PromptTokens is the estimated size of the prompt. It is only set for
+	// dry runs.
+	PromptTokens int
+	// Committed is true when the message was committed
+	Committed bool
+	// Prompt is the prompt that would be sent to the model. It is only
+	// set for dry runs, in which case Message is empty.
+	Prompt string
+	// SelfCheck is the model's review of Message, if self-check is enabled
+	SelfCheck *ai.SelfCheckResult
+	// LowConfidence is true when the self-check scored below the minimum
+	LowConfidence bool
+	// MostlyGenerated is true when most of the diff is in lockfiles or
+	// generated sources
+	MostlyGenerated bool
+	// VaguePhrase is the forbidden vague phrase still present in Message
+	// after regenerating, if any
+	VaguePhrase string
+	// Summary describes the commit. It is only set when RunOptions.Summary
+	// is enabled and the message was committed.
+	Summary *CommitSummary
+	// Elapsed is how long generating the message took, including any
+	// retries and the self-check
+	Elapsed time.Duration
+	// KeptMessageFile is true when RunOptions.MessageFile already held a
+	// message, which is then in Message. Nothing was generated.
+	KeptMessageFile bool
+	// Outcome is what became of Message: printed, committed, or accepted,
+	// edited or rejected in interactive review
+	Outcome Outcome
+}
+
+// RunOptions holds per-invocation settings for Run
+type RunOptions struct {
+	// DryRun builds the prompt without calling the AI
+	DryRun bool
+	// Commit commits the staged changes with the generated message.
+	// Split suggestions are never committed.
+	Commit bool
+	// Interactive prompts the user to accept, edit, regenerate, or quit
+	// after the message is generated. It takes precedence over Commit.
+	Interactive bool
+	// TestsOnly limits the diff to test files and forces the "test" type
+	TestsOnly bool
+	// Breaking asks for a message marked as a breaking change, with "!"
+	// after the type or scope and a BREAKING CHANGE footer in any body
+	Breaking bool
+	// Summary records the files and line counts of a commit made by Run
+	Summary bool
+	// Source selects where the diff comes from, e.g. "all" or
+	// "range:v1.0..v1.1" (see the Source constants). Empty means the
+	// staged changes. Only staged changes can be committed.
+	Source string
+	// Amend refines the message of the HEAD commit to cover the staged
+	// changes, and commits by amending HEAD
+	Amend bool
+	// Body asks for a body explaining why the change was made, below the
+	// subject. Config.IncludeBody enables it for every run.
+	Body bool
+	// NoCache asks the model even if Config.Cache holds a response for
+	// this diff
+	NoCache bool
+	// SignOff adds a Signed-off-by trailer when committing.
+	// Config.SignOff enables it for every run.
+	SignOff bool
+	// CoAuthors are "Name <email>" entries added as Co-authored-by
+	// trailers when committing, along with Config.CoAuthors
+	CoAuthors []string
+	// Output writes the final commit message, without any decoration, to
+	// this file. Split suggestions are not written.
+	Output string
+	// MessageFile is a commit message file such as the one git passes to a
+	// prepare-commit-msg hook. If it holds only comments and whitespace, the
+	// generated message is written above them; if the user already wrote a
+	// message, it is left alone and nothing is generated.
+	MessageFile string
 }
 
 // NewApp creates a new App
-func NewApp(gitClient git.Client, rulesLoader config.Loader, configLoader *config.ConfigLoader, aiClient ai.Client) *App {
+func NewApp(gitClient git.Client, rulesLoader config.Loader, configLoader config.Store, aiClient ai.Client) *App {
 	return &App{
 		Git:          gitClient,
 		RulesLoader:  rulesLoader,
@@ -31,63 +154,536 @@ func NewApp(gitClient git.Client, rulesLoader config.Loader, configLoader *confi
 	}
 }
 
-// Run executes the main logic
-func (a *App) Run() error {
+// status returns the writer for progress messages and warnings
+func (a *App) status() io.Writer {
+	if a.Status == nil {
+		return os.Stderr
+	}
+	return a.Status
+}
+
+// Run executes the main logic and returns the generated result.
+// It does not print the result; callers decide how to present it.
+// Cancelling ctx aborts any request to the AI in progress.
+func (a *App) Run(ctx context.Context, opts RunOptions) (*RunResult, error) {
 	// 1. Pre-flight Checks
-	isRepo, err := a.Git.IsInsideRepo()
+	provider, err := a.diffProvider(opts.Source)
 	if err != nil {
-		return fmt.Errorf("failed to check repository status: %w", err)
+		return nil, err
 	}
-	if !isRepo {
-		return errors.New("not a git repository")
+
+	// A message the user already wrote takes precedence, e.g. when
+	// amending or committing with -m
+	var existingMessage string
+	if opts.MessageFile != "" {
+		existingMessage, err = readMessageFile(opts.MessageFile)
+		if err != nil {
+			return nil, err
+		}
+		if !isBlankMessage(existingMessage) {
+			return &RunResult{Message: existingMessage, KeptMessageFile: true}, nil
+		}
 	}
 
-	hasChanges, err := a.Git.HasStagedChanges()
-	if err != nil {
-		return fmt.Errorf("failed to check for staged changes: %w", err)
+	// A patch from stdin or a file doesn't need a repository
+	if !isPatchSource(opts.Source) {
+		isRepo, err := a.Git.IsInsideRepo()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check repository status: %w", err)
+		}
+		if !isRepo {
+			return nil, errors.New("not a git repository")
+		}
+	}
+
+	// Mid-merge or mid-rebase the index holds conflict resolutions, not a
+	// change to describe, and git has already prepared a message
+	if isStagedSource(opts.Source) || opts.Source == SourceAll {
+		inProgress, err := a.Git.IsMidMergeOrRebase()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for a merge or rebase: %w", err)
+		}
+		if inProgress {
+			return nil, ErrMidMergeOrRebase
+		}
 	}
-	if !hasChanges {
-		return errors.New("no staged changes found. Please stage your changes using 'git add'")
+
+	if isStagedSource(opts.Source) {
+		hasChanges, err := a.Git.HasStagedChanges()
+		if errors.Is(err, git.ErrOnlyIgnored) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for staged changes: %w", err)
+		}
+		if !hasChanges {
+			return nil, errors.New("no staged changes found. Please stage your changes using 'git add'")
+		}
+	} else if opts.Commit || opts.Interactive || opts.Amend {
+		return nil, fmt.Errorf("only staged changes can be committed, not source %q", opts.Source)
+	}
+	// Catch malformed co-authors before asking the model
+	if opts.Commit || opts.Interactive {
+		if _, err := a.commitOptions(opts); err != nil {
+			return nil, err
+		}
 	}
 
 	// 2. Custom Rule Injection
 	rules, err := a.RulesLoader.LoadRules()
 	if err != nil {
-		fmt.Printf("Warning: failed to load rules: %v. Proceeding without rules.\n", err)
+		fmt.Fprintf(a.status(), "Warning: failed to load rules: %v. Proceeding without rules.\n", err)
 	}
 
 	// 3. Smart Diff Reading
-	diff, err := a.Git.GetStagedDiff()
+	diff, err := a.getDiff(provider, opts)
 	if err != nil {
-		return fmt.Errorf("failed to get diff: %w", err)
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+	if opts.Amend {
+		previous, err := a.Git.GetLastCommitMessage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the commit to amend: %w", err)
+		}
+		rules = appendRule(rules, amendRule(previous))
+	}
+
+	if opts.TestsOnly {
+		if diff == "" {
+			return nil, errors.New("no staged test files found")
+		}
+		rules = appendRule(rules, "This commit only changes tests. Use the \"test\" type.")
+	}
+
+	rules, mostlyGenerated := a.diffRules(diff, rules, opts)
+
+	diff, err = a.fitPrompt(diff, rules, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		prompt := a.buildPrompt(diff, rules, opts)
+		return &RunResult{
+			Prompt:          prompt,
+			Model:           a.model(),
+			DiffBytes:       len(diff),
+			PromptTokens:    a.estimateTokens(prompt),
+			MostlyGenerated: mostlyGenerated,
+		}, nil
+	}
+
+	result, err := a.generate(ctx, diff, rules, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 7. Optional interactive review or commit
+	if opts.Interactive && !result.IsSplitSuggestion {
+		if err := a.review(ctx, result, diff, rules, opts); err != nil {
+			return nil, err
+		}
+	} else if opts.Commit && !result.IsSplitSuggestion {
+		if err := a.commitResult(result, opts); err != nil {
+			return nil, err
+		}
+		result.Outcome = OutcomeCommitted
+	}
+	if result.IsSplitSuggestion {
+		result.Outcome = OutcomeSplit
+	} else if result.Outcome == "" {
+		result.Outcome = OutcomeGenerated
 	}
 
-	fmt.Println("Generating commit message...")
+	// A message the user rejected in review isn't handed on
+	keep := !result.IsSplitSuggestion && result.Outcome != OutcomeRejected
+	if opts.Output != "" && keep {
+		if err := os.WriteFile(opts.Output, []byte(result.Message), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write message file: %w", err)
+		}
+	}
+	if opts.MessageFile != "" && keep {
+		if err := fillMessageFile(opts.MessageFile, result.Message, existingMessage); err != nil {
+			return nil, err
+		}
+	}
+	a.logResult(result, diff)
+	return result, nil
+}
+
+// diffRules adds the rules derived from the diff itself, such as breaking
+// change hints, suggested scopes and recent commit style, and reports
+// whether the diff is mostly generated content
+func (a *App) diffRules(diff, rules string, opts RunOptions) (string, bool) {
+	if opts.Breaking {
+		rules = appendRule(rules, breakingRule)
+	} else if names := removedExports(diff); len(names) > 0 {
+		rules = appendRule(rules, breakingHint(names))
+	}
+
+	if a.forbidVague() {
+		rules = appendRule(rules, vagueRule(a.vaguePhrases()))
+	}
+
+	if scopes := suggestedScopes(changedPaths(diff)); len(scopes) > 0 {
+		rules = appendRule(rules, scopeRule(scopes))
+	}
+
+	if isDependencyOnly(diff) {
+		rules = appendRule(rules, dependencyRule(parseDependencyUpdates(diff)))
+	}
+
+	// A patch from stdin or a file may not come from this repository
+	if !isPatchSource(opts.Source) {
+		if subjects := a.recentSubjects(); len(subjects) > 0 {
+			rules = appendRule(rules, historyRule(subjects))
+		}
+	}
+
+	mostlyGenerated := isMostlyGenerated(diff)
+	if mostlyGenerated {
+		fmt.Fprintln(a.status(), "Warning: most of the staged diff is generated content (lockfiles or generated code). The message should describe the source change behind it.")
+		rules = appendRule(rules, "Most of this diff is generated content such as lockfiles or generated code. Describe the source change that caused it, not the generated files.")
+	}
+	return rules, mostlyGenerated
+}
+
+// generate asks the AI for a message and post-processes it
+func (a *App) generate(ctx context.Context, diff, rules string, opts RunOptions) (*RunResult, error) {
+	fmt.Fprintln(a.status(), "Generating commit message...")
+	start := time.Now()
 
 	// 4. AI Integration
-	message, err := a.AI.GenerateCommitMessage(diff, rules)
+	generated, err := a.generateMessage(ctx, diff, rules, opts)
 	if err != nil {
-		return fmt.Errorf("failed to generate commit message: %w", err)
+		return nil, err
 	}
+	isSplit := generated.Kind == ai.ResultSplit
+	message := generated.Content
 
-	// 5. Output
-	// Check if the response suggests splitting (multi-line or specific keywords)
-	// Heuristic: If it has multiple lines, it's likely a split suggestion or discussion.
-	// Conventional commits are typically single line (subject).
-	if strings.Contains(message, "\n") {
-		// Output split suggestion in Yellow
-		fmt.Println("\n\033[33mAI Suggestion (Split Changes):\033[0m")
-		fmt.Println(message)
-	} else {
-		// Output commit message in Cyan
-		fmt.Println("\n\033[36m" + message + "\033[0m")
+	// Vague messages get one more try
+	vaguePhrase := ""
+	if a.forbidVague() && !isSplit {
+		vaguePhrase = findVaguePhrase(subjectLine(message), a.vaguePhrases())
+		if vaguePhrase != "" {
+			fmt.Fprintf(a.status(), "Warning: message %q is vague (%q), regenerating...\n", subjectLine(message), vaguePhrase)
+			retryRules := appendRule(rules, fmt.Sprintf("A previous attempt, %q, was rejected as too vague. Name the specific component and change.", subjectLine(message)))
+			generated, err = a.generateMessage(ctx, diff, retryRules, opts)
+			if err != nil {
+				return nil, err
+			}
+			isSplit = generated.Kind == ai.ResultSplit
+			message = generated.Content
+			vaguePhrase = ""
+			if !isSplit {
+				vaguePhrase = findVaguePhrase(subjectLine(message), a.vaguePhrases())
+			}
+			if vaguePhrase != "" {
+				fmt.Fprintf(a.status(), "Warning: regenerated message is still vague (%q)\n", vaguePhrase)
+			}
+		}
+	}
+
+	// Malformed messages get one more try
+	generated, err = a.ensureConventional(ctx, generated, diff, rules, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The subject leaves room for what the branch ticket adds to it
+	ticket, err := a.branchTicket()
+	if err != nil {
+		return nil, err
+	}
+	reserved := 0
+	if ticket != "" {
+		reserved = ticketSubjectLength(ticket, a.Config.BranchTicketTemplate)
+	}
+
+	// Long subjects are flagged, truncated, or regenerated once
+	generated, err = a.fitSubject(ctx, generated, diff, rules, opts, reserved)
+	if err != nil {
+		return nil, err
+	}
+	isSplit = generated.Kind == ai.ResultSplit
+	message = generated.Content
+
+	// 5. Result
+	if !isSplit && opts.TestsOnly {
+		message = forceType(message, "test")
+	}
+	if !isSplit && opts.Breaking {
+		message = markBreaking(message)
+	}
+	if !isSplit {
+		message = wrapMessageBody(message, a.bodyWrapWidth())
+	}
+	if !isSplit && a.Config != nil && a.Config.IssueFooter {
+		message = a.addIssueFooter(message)
+	}
+	if !isSplit && ticket != "" {
+		message, err = a.addBranchTicket(message, ticket)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !isSplit && a.Config != nil && a.Config.MessageFilterCommand != "" {
+		message, err = runMessageFilter(a.Config.MessageFilterCommand, message, messageFilterTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &RunResult{
+		Message:           message,
+		IsSplitSuggestion: isSplit,
+		Model:             a.model(),
+		DiffBytes:         len(diff),
+		MostlyGenerated:   isMostlyGenerated(diff),
+		VaguePhrase:       vaguePhrase,
+	}
+
+	// 6. Optional self-check
+	if !isSplit {
+		if err := a.selfCheck(ctx, result, diff, rules); err != nil {
+			return nil, err
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result, nil
+}
+
+// generateMessage asks the AI for a message, with a body if requested, or
+// a split suggestion. Responses are reused from the cache if enabled.
+func (a *App) generateMessage(ctx context.Context, diff, rules string, opts RunOptions) (*ai.GenerateResult, error) {
+	withBody := a.includeBody(opts)
+	cache := a.responseCache()
+	key := a.cacheKey(diff, rules, withBody)
+	if cache != nil && !opts.NoCache {
+		if cached, ok := cache.get(key); ok {
+			fmt.Fprintln(a.status(), "Using the cached response for this diff (--no-cache to ask again)")
+			return cached, nil
+		}
+	}
+
+	generate := a.AI.GenerateCommitMessage
+	if withBody {
+		generate = a.AI.GenerateCommitMessageWithBody
+	}
+	generated, err := generate(ctx, diff, rules)
+	if err != nil && a.Config != nil && a.Config.AllowOfflineFallback && ai.IsConnectionError(err) {
+		fmt.Fprintf(a.status(), "Warning: %v; building the message offline from the diff\n", err)
+		return a.offlineMessage(ctx, diff, rules, withBody)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate commit message: %w", err)
+	}
+	if cache != nil {
+		if err := cache.put(key, generated); err != nil {
+			fmt.Fprintf(a.status(), "Warning: %v\n", err)
+		}
+	}
+	return generated, nil
+}
+
+// offlineMessage builds a heuristic message from the diff alone. It is
+// not cached, so the model is asked again once it can be reached.
+func (a *App) offlineMessage(ctx context.Context, diff, rules string, withBody bool) (*ai.GenerateResult, error) {
+	offline := ai.NewHeuristicClient()
+	generate := offline.GenerateCommitMessage
+	if withBody {
+		generate = offline.GenerateCommitMessageWithBody
+	}
+	generated, err := generate(ctx, diff, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate commit message: %w", err)
+	}
+	return generated, nil
+}
+
+// subjectLine returns the first line of message
+func subjectLine(message string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	return subject
+}
+
+// includeBody reports whether the message should have a body, either
+// requested for this run or enabled in the config
+func (a *App) includeBody(opts RunOptions) bool {
+	return opts.Body || (a.Config != nil && a.Config.IncludeBody)
+}
+
+// commitOptions returns how commits made by the run are created. It fails
+// if a co-author is not in the "Name <email>" form.
+func (a *App) commitOptions(opts RunOptions) (git.CommitOptions, error) {
+	var configured []string
+	if a.Config != nil {
+		configured = a.Config.CoAuthors
+	}
+	coAuthors, err := config.MergeCoAuthors(configured, opts.CoAuthors)
+	if err != nil {
+		return git.CommitOptions{}, err
+	}
+	return git.CommitOptions{
+		SignOff:   opts.SignOff || (a.Config != nil && a.Config.SignOff),
+		CoAuthors: coAuthors,
+	}, nil
+}
+
+// selfCheck has the model review the generated message when enabled.
+// In warn mode a low score is flagged on the result; in strict mode it is
+// an error. A failed self-check request only produces a warning.
+func (a *App) selfCheck(ctx context.Context, result *RunResult, diff, rules string) error {
+	if a.Config == nil || a.Config.SelfCheck == "" || a.Config.SelfCheck == "off" {
+		return nil
 	}
 
+	check, err := a.AI.CheckMessage(ctx, result.Message, diff, rules)
+	if err != nil {
+		fmt.Fprintf(a.status(), "Warning: self-check failed: %v\n", err)
+		return nil
+	}
+	result.SelfCheck = check
+
+	if check.Confidence >= a.Config.MinConfidence {
+		return nil
+	}
+	if a.Config.SelfCheck == "strict" {
+		return fmt.Errorf("self-check confidence %d is below the minimum %d: %s", check.Confidence, a.Config.MinConfidence, check.Issues)
+	}
+	result.LowConfidence = true
+	fmt.Fprintf(a.status(), "Warning: self-check confidence %d is below the minimum %d: %s\n", check.Confidence, a.Config.MinConfidence, check.Issues)
 	return nil
 }
 
+// model returns the configured model name, or "" if unknown
+func (a *App) model() string {
+	if a.Config == nil {
+		return ""
+	}
+	return a.Config.Model
+}
+
+// getDiff returns the diff from provider, filtered according to opts and
+// truncated to the configured size
+func (a *App) getDiff(provider DiffProvider, opts RunOptions) (string, error) {
+	diff, err := provider.Diff()
+	if err != nil {
+		return "", err
+	}
+	if !isStagedSource(opts.Source) && strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("no changes found in source %q", opts.Source)
+	}
+
+	if a.Config != nil && len(a.Config.ExcludePaths) > 0 && diff != "" {
+		excluded := git.NewIgnoreMatcher(a.Config.ExcludePaths)
+		diff = filterDiff(diff, func(path string) bool {
+			return !excluded.Ignored(path)
+		})
+		if diff == "" {
+			return "", errors.New("all staged files match exclude_paths")
+		}
+	}
+
+	if opts.TestsOnly {
+		patterns := defaultTestPatterns
+		if a.Config != nil && len(a.Config.TestPatterns) > 0 {
+			patterns = a.Config.TestPatterns
+		}
+		diff = filterDiff(diff, func(path string) bool {
+			return matchesAny(path, patterns)
+		})
+	}
+
+	if files := conflictedFiles(diff); len(files) > 0 {
+		if a.Config == nil || a.Config.ConflictMarkers != "strip" {
+			return "", fmt.Errorf("conflict markers found in %s; resolve the conflicts, or set conflict_markers to \"strip\" to describe the changes anyway", strings.Join(files, ", "))
+		}
+		fmt.Fprintf(a.status(), "Warning: stripped conflict markers from %s\n", strings.Join(files, ", "))
+		diff = stripConflictMarkers(diff)
+	}
+
+	// Secrets never leave the machine
+	var redactPatterns []string
+	if a.Config != nil {
+		redactPatterns = a.Config.RedactPatterns
+	}
+	redactor, err := ai.NewRedactor(redactPatterns)
+	if err != nil {
+		return "", err
+	}
+	diff = redactor.Redact(diff)
+
+	renameThreshold := defaultBulkRenameThreshold
+	if a.Config != nil && a.Config.BulkRenameThreshold != 0 {
+		renameThreshold = a.Config.BulkRenameThreshold
+	}
+	diff = summarizeRenames(diff, renameThreshold)
+
+	maxBytes := config.DefaultMaxDiffBytes
+	if a.Config != nil {
+		maxBytes = a.Config.MaxDiffBytes
+	}
+	return truncateDiff(diff, maxBytes), nil
+}
+
+// appendRule adds an extra instruction to the team rules
+func appendRule(rules, rule string) string {
+	if rules == "" {
+		return "- " + rule
+	}
+	return rules + "\n- " + rule
+}
+
+// truncateDiff cuts diff down to maxBytes. Whole files are left out
+// first, in dropOrder, so lockfiles and generated code go before source
+// files. If a single file is still too large, it is cut and marked as
+// truncated. A maxBytes of 0 disables truncation.
+func truncateDiff(diff string, maxBytes int) string {
+	if maxBytes <= 0 || len(diff) <= maxBytes {
+		return diff
+	}
+	kept, note, _, ok := trimFiles(diff, func(candidate string) bool {
+		return len(candidate) <= maxBytes
+	})
+	if ok {
+		return kept + note
+	}
+	return cutDiff(kept, maxBytes) + note
+}
+
+// cutDiff cuts diff at maxBytes and marks it as truncated
+func cutDiff(diff string, maxBytes int) string {
+	if len(diff) <= maxBytes {
+		return diff
+	}
+	// Avoid cutting a multi-byte character in half
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(diff[cut]) {
+		cut--
+	}
+	return diff[:cut] + "\n...[TRUNCATED]"
+}
+
+// addIssueFooter appends a closing-keyword footer when the current branch
+// references an issue. Branch lookup failures leave the message unchanged.
+func (a *App) addIssueFooter(message string) string {
+	branch, err := a.Git.GetCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(a.status(), "Warning: failed to read current branch: %v\n", err)
+		return message
+	}
+	ticket := ExtractTicket(branch)
+	// A closing keyword only closes tracker keys with an integration, so
+	// they need opting in
+	if !strings.HasPrefix(ticket, "#") && !a.Config.IssueFooterKeys {
+		return message
+	}
+	return addClosingFooter(message, ticket, a.Config.ClosingKeyword)
+}
+
 // Init initializes the repository with config, rules file, and pre-commit hook
-func (a *App) Init() error {
+func (a *App) Init(opts InitOptions) error {
 	// Check if we're in a git repo
 	isRepo, err := a.Git.IsInsideRepo()
 	if err != nil {
@@ -103,16 +699,37 @@ func (a *App) Init() error {
 		return fmt.Errorf("failed to get repository root: %w", err)
 	}
 
+	// Generate the hook up front, so an unknown hook type leaves nothing
+	// behind
+	hookType := opts.hookType()
+	hookContent, err := a.generateHook(hookType)
+	if err != nil {
+		return fmt.Errorf("failed to generate %s hook: %w", hookType, err)
+	}
+
 	// Check if already initialized
 	configExists, err := a.ConfigLoader.ConfigExists()
 	if err != nil {
 		return fmt.Errorf("failed to check config existence: %w", err)
 	}
-	if configExists {
+	if configExists && !opts.Force {
 		fmt.Println("Repository already initialized. Use --force to reinitialize.")
 		return nil
 	}
 
+	// A linked worktree uses the main repository's hooks
+	hooksDir, err := a.Git.GitPath("hooks")
+	if err != nil {
+		return fmt.Errorf("failed to find hooks directory: %w", err)
+	}
+	hookPath := hookFilePath(hooksDir, hookType)
+
+	// Another tool's hook is moved aside, unless forced
+	backupPath, err := hookBackupPath(hookPath, opts.Force)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("Initializing commit generator...")
 
 	// 1. Generate config file
@@ -123,7 +740,9 @@ func (a *App) Init() error {
 
 	// 2. Generate rules file
 	rulesPath := filepath.Join(repoRoot, ".git-commit-rules-for-ai")
-	if _, err := os.Stat(rulesPath); os.IsNotExist(err) {
+	_, err = os.Stat(rulesPath)
+	rulesExist := err == nil
+	if !rulesExist || opts.Force {
 		rulesContent := `# Git Commit Rules for AI Generator
 # Customize these rules to match your team's conventions
 
@@ -136,45 +755,57 @@ func (a *App) Init() error {
 		if err := os.WriteFile(rulesPath, []byte(rulesContent), 0644); err != nil {
 			return fmt.Errorf("failed to create rules file: %w", err)
 		}
-		fmt.Printf("✓ Created .git-commit-rules-for-ai\n")
+		if rulesExist {
+			fmt.Printf("✓ Overwrote .git-commit-rules-for-ai\n")
+		} else {
+			fmt.Printf("✓ Created .git-commit-rules-for-ai\n")
+		}
 	} else {
 		fmt.Printf("✓ Rules file already exists\n")
 	}
 
-	// 3. Generate pre-commit hook
-	hookPath := filepath.Join(repoRoot, ".git", "hooks", "pre-commit")
-	hookContent, err := a.generatePreCommitHook()
-	if err != nil {
-		return fmt.Errorf("failed to generate pre-commit hook: %w", err)
-	}
-
-	// On Windows, use .bat extension for batch files, otherwise no extension
-	if runtime.GOOS == "windows" {
-		// Try to detect if PowerShell is preferred, otherwise use batch
-		// For now, we'll create a .bat file that can call PowerShell if needed
-		hookPath = hookPath + ".bat"
+	// 3. Install hook, keeping a copy of another tool's hook
+	if backupPath != "" {
+		if err := os.Rename(hookPath, backupPath); err != nil {
+			return fmt.Errorf("failed to back up existing %s hook: %w", hookType, err)
+		}
+		fmt.Printf("✓ Backed up existing %s hook to %s\n", hookType, backupPath)
 	}
-
 	if err := os.WriteFile(hookPath, []byte(hookContent), 0755); err != nil {
-		return fmt.Errorf("failed to create pre-commit hook: %w", err)
+		return fmt.Errorf("failed to create %s hook: %w", hookType, err)
 	}
-	fmt.Printf("✓ Created pre-commit hook\n")
+	fmt.Printf("✓ Created %s hook\n", hookType)
 
 	fmt.Println("\nInitialization complete!")
 	fmt.Println("Next steps:")
 	fmt.Println("1. Update .commit-generator-config with your API key if needed")
 	fmt.Println("2. Customize .git-commit-rules-for-ai with your team's rules")
-	fmt.Println("3. Stage your changes and commit - the hook will generate your commit message!")
+	if hookType == HookPrepareCommitMsg {
+		fmt.Println("3. Stage your changes and run git commit - the hook will fill in the message for you to review!")
+	} else {
+		fmt.Println("3. Stage your changes and commit - the hook will generate your commit message!")
+	}
 
 	return nil
 }
 
-// generatePreCommitHook generates the pre-commit hook script for the current platform
-func (a *App) generatePreCommitHook() (string, error) {
-	if runtime.GOOS == "windows" {
-		return a.generateWindowsHook(), nil
+// generateHook generates the script for hookType on the current platform
+func (a *App) generateHook(hookType string) (string, error) {
+	windows := runtime.GOOS == "windows"
+	switch hookType {
+	case HookPreCommit:
+		if windows {
+			return a.generateWindowsHook(), nil
+		}
+		return a.generateUnixHook(), nil
+	case HookPrepareCommitMsg:
+		if windows {
+			return a.generateWindowsPrepareCommitMsgHook(), nil
+		}
+		return a.generateUnixPrepareCommitMsgHook(), nil
+	default:
+		return "", fmt.Errorf("unknown hook type %q (expected %s or %s)", hookType, HookPreCommit, HookPrepareCommitMsg)
 	}
-	return a.generateUnixHook(), nil
 }
 
 // generateUnixHook generates a bash pre-commit hook for Unix systems
@@ -182,21 +813,23 @@ func (a *App) generateUnixHook() string {
 	return `#!/bin/bash
 # Pre-commit hook for AI commit message generator
 
+# Let merges and rebases commit with the message git prepared
+GIT_DIR=$(git rev-parse --git-dir)
+if [ -f "$GIT_DIR/MERGE_HEAD" ] || [ -d "$GIT_DIR/rebase-merge" ] || [ -d "$GIT_DIR/rebase-apply" ]; then
+    exit 0
+fi
+
 # Check if there are staged changes
 if ! git diff --staged --quiet; then
-    # Generate commit message
-    COMMIT_MSG=$(generate-commit 2>&1)
-    EXIT_CODE=$?
-    
-    if [ $EXIT_CODE -ne 0 ]; then
-        echo "Error generating commit message: $COMMIT_MSG"
+    # Generate commit message into a file, leaving stdout for humans
+    MSG_FILE=$(mktemp)
+    trap 'rm -f "$MSG_FILE"' EXIT
+    if ! generate-commit --interactive=false --output "$MSG_FILE" > /dev/null; then
+        echo "Error generating commit message"
         exit 1
     fi
     
-    # Extract just the message (skip "Generating commit message..." line)
-    COMMIT_MSG=$(echo "$COMMIT_MSG" | grep -v "Generating commit message" | sed 's/^[[:space:]]*//' | sed '/^$/d')
-    
-    if [ -z "$COMMIT_MSG" ]; then
+    if [ ! -s "$MSG_FILE" ]; then
         echo "No commit message generated"
         exit 1
     fi
@@ -205,7 +838,8 @@ if ! git diff --staged --quiet; then
     echo ""
     echo "Generated commit message:"
     echo "=========================="
-    echo "$COMMIT_MSG"
+    cat "$MSG_FILE"
+    echo ""
     echo "=========================="
     echo ""
     echo "Options:"
@@ -218,7 +852,7 @@ if ! git diff --staged --quiet; then
     case "$choice" in
         [Aa]*)
             # Accept: commit with the generated message
-            git commit -m "$COMMIT_MSG" --no-verify
+            git commit -F "$MSG_FILE" --no-verify
             # Exit with error to prevent original commit from proceeding
             # (since we already committed)
             exit 1
@@ -230,11 +864,8 @@ if ! git diff --staged --quiet; then
             ;;
         [Ee]*)
             # Edit: allow user to modify
-            echo "$COMMIT_MSG" > /tmp/commit_msg.txt
-            ${EDITOR:-nano} /tmp/commit_msg.txt
-            EDITED_MSG=$(cat /tmp/commit_msg.txt)
-            git commit -m "$EDITED_MSG" --no-verify
-            rm -f /tmp/commit_msg.txt
+            ${EDITOR:-nano} "$MSG_FILE"
+            git commit -F "$MSG_FILE" --no-verify
             # Exit with error to prevent original commit from proceeding
             exit 1
             ;;
@@ -251,20 +882,23 @@ fi
 func (a *App) generateWindowsHook() string {
 	return "@echo off\n" +
 		"REM Pre-commit hook for AI commit message generator (Windows)\n\n" +
+		"REM Let merges and rebases commit with the message git prepared\n" +
+		"for /f \"delims=\" %%i in ('git rev-parse --git-dir') do set GIT_DIR=%%i\n" +
+		"if exist \"%GIT_DIR%\\MERGE_HEAD\" exit /b 0\n" +
+		"if exist \"%GIT_DIR%\\rebase-merge\" exit /b 0\n" +
+		"if exist \"%GIT_DIR%\\rebase-apply\" exit /b 0\n\n" +
 		"REM Check if there are staged changes\n" +
 		"git diff --staged --quiet >nul 2>&1\n" +
 		"if %errorlevel% equ 0 exit /b 0\n\n" +
-		"REM Generate commit message\n" +
-		"for /f \"delims=\" %%i in ('generate-commit 2^>^&1') do set OUTPUT=%%i\n" +
+		"REM Generate commit message into a file, leaving stdout for humans\n" +
+		"set MSG_FILE=%TEMP%\\commit_msg.txt\n" +
+		"if exist \"%MSG_FILE%\" del \"%MSG_FILE%\"\n" +
+		"generate-commit --interactive=false --output \"%MSG_FILE%\" >nul\n" +
 		"if errorlevel 1 (\n" +
 		"    echo Error generating commit message\n" +
 		"    exit /b 1\n" +
 		")\n\n" +
-		"REM Extract commit message (basic extraction - may need refinement)\n" +
-		"set COMMIT_MSG=%OUTPUT%\n" +
-		"REM Remove \"Generating commit message...\" line if present\n" +
-		"set COMMIT_MSG=%COMMIT_MSG:Generating commit message...=%\n\n" +
-		"if \"%COMMIT_MSG%\"==\"\" (\n" +
+		"if not exist \"%MSG_FILE%\" (\n" +
 		"    echo No commit message generated\n" +
 		"    exit /b 1\n" +
 		")\n\n" +
@@ -272,7 +906,8 @@ func (a *App) generateWindowsHook() string {
 		"echo.\n" +
 		"echo Generated commit message:\n" +
 		"echo ==========================\n" +
-		"echo %COMMIT_MSG%\n" +
+		"type \"%MSG_FILE%\"\n" +
+		"echo.\n" +
 		"echo ==========================\n" +
 		"echo.\n" +
 		"echo Options:\n" +
@@ -290,16 +925,16 @@ func (a *App) generateWindowsHook() string {
 		"echo Invalid choice. Aborting commit.\n" +
 		"exit /b 1\n\n" +
 		":accept\n" +
-		"git commit -m \"%COMMIT_MSG%\" --no-verify\n" +
+		"git commit -F \"%MSG_FILE%\" --no-verify\n" +
+		"del \"%MSG_FILE%\"\n" +
 		"exit /b 1\n\n" +
 		":reject\n" +
+		"del \"%MSG_FILE%\"\n" +
 		"echo Commit aborted by user\n" +
 		"exit /b 1\n\n" +
 		":edit\n" +
-		"echo %COMMIT_MSG% > %TEMP%\\commit_msg.txt\n" +
-		"notepad %TEMP%\\commit_msg.txt\n" +
-		"set /p EDITED_MSG=<%TEMP%\\commit_msg.txt\n" +
-		"git commit -m \"%EDITED_MSG%\" --no-verify\n" +
-		"del %TEMP%\\commit_msg.txt\n" +
+		"notepad \"%MSG_FILE%\"\n" +
+		"git commit -F \"%MSG_FILE%\" --no-verify\n" +
+		"del \"%MSG_FILE%\"\n" +
 		"exit /b 1\n"
 }
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestApp_Run_Body(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            RunOptions
+		config          *config.Config
+		response        *ai.GenerateResult
+		expectedMessage string
+		expectedSplit   bool
+		expectedBody    bool
+	}{
+		{
+			name:            "Flag requests a body",
+			opts:            RunOptions{Body: true},
+			response:        message("fix(api): retry 503 responses\n\nGateways return 503 during deploys."),
+			expectedMessage: "fix(api): retry 503 responses\n\nGateways return 503 during deploys.",
+			expectedBody:    true,
+		},
+		{
+			name:            "Config requests a body",
+			config:          &config.Config{IncludeBody: true},
+			response:        message("docs: fix typo"),
+			expectedMessage: "docs: fix typo",
+			expectedBody:    true,
+		},
+		{
+			name:            "Tests only forces the subject type",
+			opts:            RunOptions{Body: true, TestsOnly: true},
+			response:        message("feat: cover login\n\nAdds missing cases."),
+			expectedMessage: "test: cover login\n\nAdds missing cases.",
+			expectedBody:    true,
+		},
+		{
+			name:            "Split suggestion",
+			opts:            RunOptions{Body: true},
+			response:        splitSuggestion("Split into:\n- auth\n- docs"),
+			expectedMessage: "Split into:\n- auth\n- docs",
+			expectedSplit:   true,
+			expectedBody:    true,
+		},
+		{
+			name:            "Without a body",
+			expectedMessage: "feat: add login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			askedForBody := false
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff --git a/login_test.go b/login_test.go\n+test", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return message("feat: add login"), nil
+				},
+				GenerateCommitMessageWithBodyFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					askedForBody = true
+					return tt.response, nil
+				},
+			})
+			app.Config = tt.config
+
+			result, err := app.Run(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if askedForBody != tt.expectedBody {
+				t.Errorf("expected body requested %v, got %v", tt.expectedBody, askedForBody)
+			}
+			if result.Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, result.Message)
+			}
+			if result.IsSplitSuggestion != tt.expectedSplit {
+				t.Errorf("expected split suggestion %v, got %v", tt.expectedSplit, result.IsSplitSuggestion)
+			}
+		})
+	}
+}
+
+func TestApp_Run_BodyDryRun(t *testing.T) {
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		BuildPromptFunc:     func(diff, rules string) string { return "subject prompt" },
+		BuildBodyPromptFunc: func(diff, rules string) string { return "body prompt" },
+	})
+
+	result, err := app.Run(context.Background(), RunOptions{DryRun: true, Body: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Prompt != "body prompt" {
+		t.Errorf("expected the body prompt, got %q", result.Prompt)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/git"
+)
+
+// breakingRule asks the model to mark the change as breaking
+const breakingRule = `This change breaks backwards compatibility. Add "!" after the type or scope, e.g. "feat(api)!: remove the v1 endpoints". If you write a body, end it with a "BREAKING CHANGE: <what breaks and how to migrate>" footer.`
+
+// breakingPrefix captures the type and scope of a subject, after any
+// gitmoji, and its breaking marker
+var breakingPrefix = regexp.MustCompile(`^((?:[^\sA-Za-z0-9]+\s+)?[a-zA-Z]+(?:\([^()]*\))?)(!?):`)
+
+// markBreaking adds "!" after the type or scope of message unless it is
+// already marked as breaking. Messages that aren't Conventional Commits are
+// left alone.
+func markBreaking(message string) string {
+	if ai.IsBreaking(message) {
+		return message
+	}
+	m := breakingPrefix.FindStringSubmatchIndex(message)
+	if m == nil {
+		return message
+	}
+	return message[:m[3]] + "!" + message[m[3]:]
+}
+
+// removedExportPattern matches a removed exported Go function, method or
+// type declaration, capturing its name
+var removedExportPattern = regexp.MustCompile(`^-(?:func (?:\([^)]*\) )?([A-Z]\w*)|type ([A-Z]\w*))`)
+
+// removedExports returns the exported Go declarations that diff removes or
+// changes the signature of: a removed declaration line that isn't added
+// back unchanged. Test files are skipped.
+func removedExports(diff string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, file := range git.SplitDiff(diff) {
+		if !strings.HasSuffix(file.Path, ".go") || strings.HasSuffix(file.Path, "_test.go") {
+			continue
+		}
+		lines := strings.Split(file.Text, "\n")
+		added := map[string]bool{}
+		for _, line := range lines {
+			if content, ok := strings.CutPrefix(line, "+"); ok {
+				added[content] = true
+			}
+		}
+		for _, line := range lines {
+			m := removedExportPattern.FindStringSubmatch(line)
+			if m == nil || added[line[1:]] {
+				continue
+			}
+			name := m[1] + m[2]
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// breakingHint suggests marking the change as breaking when it removes or
+// changes exported declarations
+func breakingHint(names []string) string {
+	return fmt.Sprintf("The diff removes or changes the exported declarations %s. If callers have to change, this is a breaking change: add \"!\" after the type or scope and a \"BREAKING CHANGE: <description>\" footer.", strings.Join(names, ", "))
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+func TestMarkBreaking(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{message: "feat: drop the v1 endpoints", expected: "feat!: drop the v1 endpoints"},
+		{message: "feat(api): drop the v1 endpoints", expected: "feat(api)!: drop the v1 endpoints"},
+		{message: "✨ feat(api): drop the v1 endpoints", expected: "✨ feat(api)!: drop the v1 endpoints"},
+		{message: "feat(api): drop v1\n\nClients move to v2.", expected: "feat(api)!: drop v1\n\nClients move to v2."},
+		{message: "feat(api)!: drop the v1 endpoints", expected: "feat(api)!: drop the v1 endpoints"},
+		{message: "feat(api): drop v1\n\nBREAKING CHANGE: clients must use /v2", expected: "feat(api): drop v1\n\nBREAKING CHANGE: clients must use /v2"},
+		{message: "Drop the v1 endpoints", expected: "Drop the v1 endpoints"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := markBreaking(tt.message); got != tt.expected {
+				t.Errorf("markBreaking(%q) = %q, expected %q", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRemovedExports(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected []string
+	}{
+		{
+			name:     "Removed function",
+			diff:     "diff --git a/api.go b/api.go\n-func Fetch(url string) error {\n-}\n",
+			expected: []string{"Fetch"},
+		},
+		{
+			name:     "Changed method signature and removed type",
+			diff:     "diff --git a/client.go b/client.go\n-func (c *Client) Do(req *Request) error {\n+func (c *Client) Do(ctx context.Context, req *Request) error {\n-type Options struct {\n",
+			expected: []string{"Do", "Options"},
+		},
+		{
+			name: "Moved unchanged",
+			diff: "diff --git a/api.go b/api.go\n-func Fetch(url string) error {\n+func Fetch(url string) error {\n",
+		},
+		{
+			name: "Unexported",
+			diff: "diff --git a/api.go b/api.go\n-func fetch(url string) error {\n-type options struct {\n",
+		},
+		{
+			name: "Test file",
+			diff: "diff --git a/api_test.go b/api_test.go\n-func TestFetch(t *testing.T) {\n",
+		},
+		{
+			name: "Not Go",
+			diff: "diff --git a/api.py b/api.py\n-def Fetch(url):\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removedExports(tt.diff); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("removedExports() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApp_Run_Breaking(t *testing.T) {
+	const removal = "diff --git a/api.go b/api.go\n-func Fetch(url string) error {\n"
+	tests := []struct {
+		name            string
+		breaking        bool
+		diff            string
+		response        string
+		expectedMessage string
+		expectedRule    string
+	}{
+		{
+			name:            "Model adds the marker",
+			breaking:        true,
+			diff:            "diff",
+			response:        "feat(api)!: drop the v1 endpoints",
+			expectedMessage: "feat(api)!: drop the v1 endpoints",
+			expectedRule:    breakingRule,
+		},
+		{
+			name:            "Footer form is kept",
+			breaking:        true,
+			diff:            "diff",
+			response:        "feat(api): drop the v1 endpoints\n\nBREAKING CHANGE: clients must use /v2",
+			expectedMessage: "feat(api): drop the v1 endpoints\n\nBREAKING CHANGE: clients must use /v2",
+			expectedRule:    breakingRule,
+		},
+		{
+			name:            "Missing marker is added",
+			breaking:        true,
+			diff:            "diff",
+			response:        "feat(api): drop the v1 endpoints",
+			expectedMessage: "feat(api)!: drop the v1 endpoints",
+			expectedRule:    breakingRule,
+		},
+		{
+			name:            "Removed export is a hint",
+			diff:            removal,
+			response:        "refactor(api): remove Fetch",
+			expectedMessage: "refactor(api): remove Fetch",
+			expectedRule:    "removes or changes the exported declarations Fetch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentRules string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return tt.diff, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sentRules = rules
+					return message(tt.response), nil
+				},
+			})
+
+			result, err := app.Run(context.Background(), RunOptions{Breaking: tt.breaking})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, result.Message)
+			}
+			if !strings.Contains(sentRules, tt.expectedRule) {
+				t.Errorf("expected rules containing %q, got %q", tt.expectedRule, sentRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+const (
+	// cacheDirName is the directory in the git dir holding cached responses
+	cacheDirName = "generate-commit-cache"
+	// defaultCacheTTL is how long a cached response is reused
+	defaultCacheTTL = time.Hour
+	// maxCacheEntries bounds the number of cached responses; the oldest
+	// are removed first
+	maxCacheEntries = 100
+)
+
+// responseCache stores model responses on disk, one file per key
+type responseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// cacheEntry is the stored form of a response
+type cacheEntry struct {
+	Kind    string `json:"kind"`
+	Content string `json:"content"`
+}
+
+// responseCache returns the cache for this repository, or nil if caching
+// is disabled or there is no repository to keep it in
+func (a *App) responseCache() *responseCache {
+	if a.Config == nil || !a.Config.Cache {
+		return nil
+	}
+	dir, err := a.Git.GitPath(cacheDirName)
+	if err != nil || dir == "" {
+		return nil
+	}
+	ttl := a.Config.GetCacheTTL()
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &responseCache{dir: dir, ttl: ttl}
+}
+
+// cacheKey identifies a request by everything that shapes the response:
+// the diff, the rules, the model and its endpoint, the sampling options,
+// and the prompt settings
+func (a *App) cacheKey(diff, rules string, withBody bool) string {
+	parts := []string{diff, rules, fmt.Sprint(withBody)}
+	if a.Config != nil {
+		// Map keys are marshaled in sorted order, so equal options match
+		extraOptions, _ := json.Marshal(a.Config.ExtraOptions)
+		parts = append(parts, a.Config.Provider, a.Config.Model, a.Config.BaseURL,
+			fmt.Sprint(a.Config.Temperature), fmt.Sprint(a.Config.TopP), string(extraOptions),
+			a.Config.Style, a.Config.Language, a.Config.PromptTemplate)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the cached response for key, if there is one younger than
+// the TTL
+func (c *responseCache) get(key string) (*ai.GenerateResult, bool) {
+	path := filepath.Join(c.dir, key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Content == "" {
+		return nil, false
+	}
+	return &ai.GenerateResult{Kind: entry.Kind, Content: entry.Content}, true
+}
+
+// put stores result under key, then drops expired entries and the oldest
+// ones beyond maxCacheEntries
+func (c *responseCache) put(key string, result *ai.GenerateResult) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(cacheEntry{Kind: result.Kind, Content: result.Content})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, key), data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	c.prune()
+	return nil
+}
+
+// prune removes expired entries and the oldest entries beyond
+// maxCacheEntries
+func (c *responseCache) prune() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	type file struct {
+		path    string
+		modTime time.Time
+	}
+	var files []file
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		if time.Since(info.ModTime()) > c.ttl {
+			os.Remove(path)
+			continue
+		}
+		files = append(files, file{path: path, modTime: info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	for _, f := range files[min(len(files), maxCacheEntries):] {
+		os.Remove(f.path)
+	}
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestApp_Run_Cache(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        *config.Config
+		diffs         []string
+		noCache       bool
+		expectedCalls int
+	}{
+		{
+			name:          "Disabled",
+			config:        &config.Config{},
+			diffs:         []string{"diff a", "diff a"},
+			expectedCalls: 2,
+		},
+		{
+			name:          "Hit for the same diff",
+			config:        &config.Config{Cache: true},
+			diffs:         []string{"diff a", "diff a"},
+			expectedCalls: 1,
+		},
+		{
+			name:          "Miss when the diff changes",
+			config:        &config.Config{Cache: true},
+			diffs:         []string{"diff a", "diff b", "diff a"},
+			expectedCalls: 2,
+		},
+		{
+			name:          "No cache flag",
+			config:        &config.Config{Cache: true},
+			diffs:         []string{"diff a", "diff a"},
+			noCache:       true,
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			calls := 0
+			var diff string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return diff, nil },
+				GetRepoRootFunc:      func() (string, error) { return root, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					calls++
+					return message(fmt.Sprintf("feat: describe %s", diff)), nil
+				},
+			})
+			app.Config = tt.config
+
+			for _, diff = range tt.diffs {
+				result, err := app.Run(context.Background(), RunOptions{NoCache: tt.noCache})
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if expected := "feat: describe " + diff; result.Message != expected {
+					t.Errorf("expected message %q, got %q", expected, result.Message)
+				}
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestApp_CacheKey(t *testing.T) {
+	base := config.Config{Provider: "ollama", Model: "m", BaseURL: "http://a", ExtraOptions: map[string]any{"num_ctx": 8192, "seed": 1}}
+	app := &App{Config: &base}
+	key := app.cacheKey("diff", "rules", false)
+
+	same := base
+	same.ExtraOptions = map[string]any{"seed": 1, "num_ctx": 8192}
+	app.Config = &same
+	if got := app.cacheKey("diff", "rules", false); got != key {
+		t.Error("expected equal extra options to give the same key")
+	}
+
+	changes := map[string]func(c *config.Config){
+		"base_url":      func(c *config.Config) { c.BaseURL = "http://b" },
+		"temperature":   func(c *config.Config) { c.Temperature = 0.2 },
+		"top_p":         func(c *config.Config) { c.TopP = 0.9 },
+		"extra_options": func(c *config.Config) { c.ExtraOptions = map[string]any{"num_ctx": 4096} },
+	}
+	for name, change := range changes {
+		changed := base
+		change(&changed)
+		app.Config = &changed
+		if app.cacheKey("diff", "rules", false) == key {
+			t.Errorf("expected a different key when %s changes", name)
+		}
+	}
+}
+
+func TestApp_ResponseCache_UsesGitDir(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), "worktrees", "feature")
+	app := NewApp(&MockGit{
+		GitPathFunc: func(name string) (string, error) { return filepath.Join(gitDir, name), nil },
+	}, nil, nil, nil)
+	app.Config = &config.Config{Cache: true}
+
+	cache := app.responseCache()
+	if cache == nil {
+		t.Fatal("expected a cache")
+	}
+	if expected := filepath.Join(gitDir, cacheDirName); cache.dir != expected {
+		t.Errorf("expected cache in %q, got %q", expected, cache.dir)
+	}
+}
+
+func TestResponseCache_Expiry(t *testing.T) {
+	cache := &responseCache{dir: t.TempDir(), ttl: time.Hour}
+	if err := cache.put("key", splitSuggestion("Split into auth and docs")); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+
+	cached, ok := cache.get("key")
+	if !ok {
+		t.Fatal("expected a hit")
+	}
+	if cached.Kind != ai.ResultSplit || cached.Content != "Split into auth and docs" {
+		t.Errorf("unexpected cached result %+v", cached)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(cache.dir, "key"), old, old); err != nil {
+		t.Fatalf("failed to age entry: %v", err)
+	}
+	if _, ok := cache.get("key"); ok {
+		t.Error("expected an expired entry to miss")
+	}
+}
+
+func TestResponseCache_Prune(t *testing.T) {
+	cache := &responseCache{dir: t.TempDir(), ttl: time.Hour}
+	start := time.Now().Add(-30 * time.Minute)
+	for i := 0; i < maxCacheEntries+5; i++ {
+		if err := cache.put(fmt.Sprintf("key%03d", i), message("feat: x")); err != nil {
+			t.Fatalf("failed to put: %v", err)
+		}
+		modTime := start.Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(filepath.Join(cache.dir, fmt.Sprintf("key%03d", i)), modTime, modTime); err != nil {
+			t.Fatalf("failed to set time: %v", err)
+		}
+	}
+	cache.prune()
+
+	entries, err := os.ReadDir(cache.dir)
+	if err != nil {
+		t.Fatalf("failed to read cache dir: %v", err)
+	}
+	if len(entries) != maxCacheEntries {
+		t.Errorf("expected %d entries, got %d", maxCacheEntries, len(entries))
+	}
+	if _, ok := cache.get("key000"); ok {
+		t.Error("expected the oldest entry to be pruned")
+	}
+	if _, ok := cache.get(fmt.Sprintf("key%03d", maxCacheEntries+4)); !ok {
+		t.Error("expected the newest entry to be kept")
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import "os"
+
+// ANSI colors used when printing results
+const (
+	ColorCyan   = "\033[36m"
+	ColorYellow = "\033[33m"
+	ColorGreen  = "\033[32m"
+	ColorRed    = "\033[31m"
+	colorReset  = "\033[0m"
+)
+
+// ColorEnabled reports whether output written to f should be colored. Color
+// is off when noColor is set, when the NO_COLOR environment variable is set
+// to any non-empty value, or when f is not a terminal.
+func ColorEnabled(noColor bool, f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Colorize wraps s in the given ANSI color if enabled, and returns s
+// unchanged otherwise
+func Colorize(enabled bool, color, s string) string {
+	if !enabled {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// colorize colors s for the App's output
+func (a *App) colorize(color, s string) string {
+	return Colorize(a.Color, color, s)
+}
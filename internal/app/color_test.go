@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestColorize(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected string
+	}{
+		{name: "Enabled", enabled: true, expected: "\033[36mfeat: add login\033[0m"},
+		{name: "Disabled", enabled: false, expected: "feat: add login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Colorize(tt.enabled, ColorCyan, "feat: add login"); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		name    string
+		noColor bool
+		env     string
+	}{
+		{name: "Not a terminal"},
+		{name: "Flag", noColor: true},
+		{name: "NO_COLOR", env: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.env)
+			if ColorEnabled(tt.noColor, file) {
+				t.Error("expected color to be disabled")
+			}
+		})
+	}
+}
+
+func TestApp_Review_NoColor(t *testing.T) {
+	app := NewApp(&MockGit{}, &MockConfig{}, nil, &MockAI{})
+	app.Input = strings.NewReader("q\n")
+
+	output := captureStdout(t, func() {
+		if err := app.review(context.Background(), &RunResult{Message: "feat: add login"}, "diff", "", RunOptions{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "feat: add login") {
+		t.Errorf("expected the message in output, got %q", output)
+	}
+	if strings.Contains(output, "\033[") {
+		t.Errorf("expected no color codes, got %q", output)
+	}
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// conflictMarkerPrefixes start the lines git writes around a merge
+// conflict. "|||||||" opens the base section of diff3-style conflicts.
+var conflictMarkerPrefixes = []string{"<<<<<<<", "|||||||", "=======", ">>>>>>>"}
+
+// isConflictMarker reports whether an added diff line is a conflict
+// marker: the marker alone, or followed by a space and a label
+func isConflictMarker(line string) bool {
+	content, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "+")
+	if !ok {
+		return false
+	}
+	for _, prefix := range conflictMarkerPrefixes {
+		if rest, ok := strings.CutPrefix(content, prefix); ok {
+			return rest == "" || (rest[0] == ' ' && prefix != "=======")
+		}
+	}
+	return false
+}
+
+// hasConflict reports whether a file's diff adds an opening or closing
+// conflict marker. A lone "=======" is also a Markdown heading underline,
+// so it doesn't count on its own.
+func hasConflict(text string) bool {
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if isConflictMarker(line) && !strings.HasPrefix(line, "+=======") && !strings.HasPrefix(line, "+|||||||") {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictedFiles returns the paths of the files in diff that add
+// conflict markers
+func conflictedFiles(diff string) []string {
+	var paths []string
+	for _, file := range git.SplitDiff(diff) {
+		if hasConflict(file.Text) {
+			paths = append(paths, file.Path)
+		}
+	}
+	return paths
+}
+
+// stripConflictMarkers removes the added marker lines from the files that
+// have conflicts, keeping both sides of each conflict. Hunk line counts
+// are not adjusted, as the diff is only read by the model.
+func stripConflictMarkers(diff string) string {
+	files := git.SplitDiff(diff)
+	for i, file := range files {
+		if !hasConflict(file.Text) {
+			continue
+		}
+		var kept strings.Builder
+		for _, line := range strings.SplitAfter(file.Text, "\n") {
+			if !isConflictMarker(line) {
+				kept.WriteString(line)
+			}
+		}
+		files[i].Text = kept.String()
+	}
+	return git.JoinDiff(files)
+}
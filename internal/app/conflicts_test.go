@@ -0,0 +1,126 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+const conflictDiff = `diff --git a/auth.go b/auth.go
+--- a/auth.go
++++ b/auth.go
+@@ -1,2 +1,7 @@
+ package auth
++<<<<<<< HEAD
++const timeout = 30
++=======
++const timeout = 60
++>>>>>>> feature/login
+ func Login() {}
+diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1 +1,3 @@
++Usage
++=======
+ Run the tool.
+`
+
+func TestConflictedFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected []string
+	}{
+		{name: "Markers in one file", diff: conflictDiff, expected: []string{"auth.go"}},
+		{name: "Heading underline only", diff: "diff --git a/a.md b/a.md\n+Title\n+=======\n"},
+		{name: "Removed markers", diff: "diff --git a/a.go b/a.go\n-<<<<<<< HEAD\n-=======\n->>>>>>> main\n"},
+		{name: "Context markers", diff: "diff --git a/a.go b/a.go\n <<<<<<< HEAD\n"},
+		{name: "Longer run of brackets", diff: "diff --git a/a.txt b/a.txt\n+<<<<<<<<<<\n"},
+		{name: "Closing marker only", diff: "diff --git a/a.go b/a.go\n+>>>>>>>\n", expected: []string{"a.go"}},
+		{name: "No diff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conflictedFiles(tt.diff); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("conflictedFiles() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripConflictMarkers(t *testing.T) {
+	got := stripConflictMarkers(conflictDiff)
+	for _, marker := range []string{"+<<<<<<< HEAD\n", "+=======\n+const", "+>>>>>>> feature/login\n"} {
+		if strings.Contains(got, marker) {
+			t.Errorf("expected %q to be stripped, got:\n%s", marker, got)
+		}
+	}
+	// Both sides of the conflict are kept, and files without conflicts are
+	// left alone
+	for _, kept := range []string{"+const timeout = 30\n", "+const timeout = 60\n", "+Usage\n+=======\n"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("expected %q to be kept, got:\n%s", kept, got)
+		}
+	}
+}
+
+func TestApp_Run_ConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           string
+		expectedErr    string
+		expectedStatus string
+	}{
+		{name: "Refuse by default", expectedErr: "conflict markers found in auth.go"},
+		{name: "Refuse", mode: "error", expectedErr: "conflict markers found in auth.go"},
+		{name: "Strip", mode: "strip", expectedStatus: "Warning: stripped conflict markers from auth.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentDiff string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return conflictDiff, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sentDiff = diff
+					return message("fix(auth): raise login timeout"), nil
+				},
+			})
+			app.Config = &config.Config{ConflictMarkers: tt.mode}
+			var status bytes.Buffer
+			app.Status = &status
+
+			_, err := app.Run(context.Background(), RunOptions{})
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				if sentDiff != "" {
+					t.Error("expected no diff to be sent to the model")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Contains(sentDiff, "<<<<<<<") || strings.Contains(sentDiff, ">>>>>>>") {
+				t.Errorf("expected the markers to be stripped, got:\n%s", sentDiff)
+			}
+			if !strings.Contains(status.String(), tt.expectedStatus) {
+				t.Errorf("expected status containing %q, got %q", tt.expectedStatus, status.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+// ensureConventional regenerates a message once if it doesn't follow the
+// Conventional Commits grammar, with an instruction naming the problem.
+// If the second attempt is still invalid it is kept with a warning.
+// Messages from a custom prompt template may use any format and are not
+// checked.
+func (a *App) ensureConventional(ctx context.Context, generated *ai.GenerateResult, diff, rules string, opts RunOptions) (*ai.GenerateResult, error) {
+	if generated.Kind != ai.ResultMessage || (a.Config != nil && a.Config.PromptTemplate != "") {
+		return generated, nil
+	}
+	invalid := ai.ValidateConventional(generated.Content, a.allowedTypes())
+	if invalid == nil {
+		return generated, nil
+	}
+
+	fmt.Fprintf(a.status(), "Warning: %v, regenerating...\n", invalid)
+	retryRules := appendRule(rules, fmt.Sprintf("A previous attempt, %q, was rejected: %v. Reply with only the commit message in the format <type>(<scope>): <description>.", subjectLine(generated.Content), invalid))
+	regenerated, err := a.generateMessage(ctx, diff, retryRules, opts)
+	if err != nil {
+		return nil, err
+	}
+	if regenerated.Kind == ai.ResultMessage {
+		if err := ai.ValidateConventional(regenerated.Content, a.allowedTypes()); err != nil {
+			fmt.Fprintf(a.status(), "Warning: regenerated message is still not a Conventional Commit: %v\n", err)
+		}
+	}
+	return regenerated, nil
+}
+
+// allowedTypes returns the configured conventional types, or nil for the
+// defaults
+func (a *App) allowedTypes() []string {
+	if a.Config == nil {
+		return nil
+	}
+	return a.Config.AllowedTypes
+}
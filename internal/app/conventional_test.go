@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestApp_Run_Conventional(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          *config.Config
+		responses       []string
+		expectedMessage string
+		expectedCalls   int
+	}{
+		{
+			name:            "Valid message",
+			responses:       []string{"feat(auth): add login"},
+			expectedMessage: "feat(auth): add login",
+			expectedCalls:   1,
+		},
+		{
+			name:            "Prose is regenerated",
+			responses:       []string{"This commit adds a login form", "feat(auth): add login form"},
+			expectedMessage: "feat(auth): add login form",
+			expectedCalls:   2,
+		},
+		{
+			name:            "Still invalid after retry",
+			responses:       []string{"This commit adds a login form", "Adds a login form"},
+			expectedMessage: "Adds a login form",
+			expectedCalls:   2,
+		},
+		{
+			name:            "Custom template is not checked",
+			config:          &config.Config{PromptTemplate: "{{.Diff}}"},
+			responses:       []string{"Adds a login form"},
+			expectedMessage: "Adds a login form",
+			expectedCalls:   1,
+		},
+		{
+			name:            "Configured type",
+			config:          &config.Config{AllowedTypes: []string{"feat", "fix", "perf"}},
+			responses:       []string{"perf(index): cache lookups"},
+			expectedMessage: "perf(index): cache lookups",
+			expectedCalls:   1,
+		},
+		{
+			name:            "Type not configured is regenerated",
+			config:          &config.Config{AllowedTypes: []string{"feat", "fix", "build"}},
+			responses:       []string{"chore: bump deps", "build(deps): bump deps"},
+			expectedMessage: "build(deps): bump deps",
+			expectedCalls:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					calls = append(calls, rules)
+					return message(tt.responses[len(calls)-1]), nil
+				},
+			})
+			app.Config = tt.config
+
+			result, err := app.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, result.Message)
+			}
+			if len(calls) != tt.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", tt.expectedCalls, len(calls))
+			}
+			if tt.expectedCalls == 2 && !strings.Contains(calls[1], fmt.Sprintf("A previous attempt, %q, was rejected", tt.responses[0])) {
+				t.Errorf("expected a corrective rule on retry, got %q", calls[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// dependencyFiles are the manifests and lockfiles recognised as dependency
+// changes, by base name
+var dependencyFiles = map[string]bool{
+	"go.mod": true, "go.sum": true,
+	"package.json": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"requirements.txt": true,
+}
+
+// dependencyUpdate is a dependency whose version changed. From is empty for
+// added dependencies and To is empty for removed ones.
+type dependencyUpdate struct {
+	Name string
+	From string
+	To   string
+}
+
+// String formats the update as "name from -> to"
+func (d dependencyUpdate) String() string {
+	switch {
+	case d.From == "":
+		return d.Name + " " + d.To + " (added)"
+	case d.To == "":
+		return d.Name + " " + d.From + " (removed)"
+	default:
+		return d.Name + " " + d.From + " -> " + d.To
+	}
+}
+
+var (
+	// goModRequire matches a go.mod requirement, inside or outside a
+	// require block: "[require ]module vX.Y.Z [// indirect]"
+	goModRequire = regexp.MustCompile(`^\s*(?:require\s+)?(\S+)\s+(v\S+)(?:\s*//.*)?$`)
+	// packageJSONDependency matches a `"name": "^1.2.3",` line whose value
+	// looks like a version range
+	packageJSONDependency = regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*"([\^~<>=*]*\d[^"]*|\*|latest)",?\s*$`)
+	// requirementsPin matches "name==1.2.3" style requirements
+	requirementsPin = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._\-\[\]]*)\s*(?:==|>=|<=|~=|>|<)\s*([^\s;#]+)`)
+)
+
+// isDependencyOnly reports whether every file changed in diff is a
+// dependency manifest or lockfile
+func isDependencyOnly(diff string) bool {
+	files := git.SplitDiff(diff)
+	if len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		if !dependencyFiles[path.Base(file.Path)] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDependencyUpdates extracts the changed dependencies from the
+// go.mod, package.json and requirements.txt sections of diff, sorted by
+// name. Lockfiles are ignored since their manifest carries the same change.
+func parseDependencyUpdates(diff string) []dependencyUpdate {
+	updates := map[string]*dependencyUpdate{}
+	for _, file := range git.SplitDiff(diff) {
+		var pattern *regexp.Regexp
+		switch path.Base(file.Path) {
+		case "go.mod":
+			pattern = goModRequire
+		case "package.json":
+			pattern = packageJSONDependency
+		case "requirements.txt":
+			pattern = requirementsPin
+		default:
+			continue
+		}
+
+		for _, line := range strings.Split(file.Text, "\n") {
+			if line == "" || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+				continue
+			}
+			sign := line[0]
+			if sign != '+' && sign != '-' {
+				continue
+			}
+			m := pattern.FindStringSubmatch(line[1:])
+			if m == nil || m[1] == "version" {
+				continue
+			}
+
+			update := updates[m[1]]
+			if update == nil {
+				update = &dependencyUpdate{Name: m[1]}
+				updates[m[1]] = update
+			}
+			if sign == '-' {
+				update.From = m[2]
+			} else {
+				update.To = m[2]
+			}
+		}
+	}
+
+	var result []dependencyUpdate
+	for _, update := range updates {
+		if update.From != update.To {
+			result = append(result, *update)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// dependencyRule builds the prompt rule for a dependency-only change
+func dependencyRule(updates []dependencyUpdate) string {
+	rule := `This commit only updates dependencies. Use a "chore(deps)" message (or "build(deps)" if the team rules prefer it)`
+	if len(updates) == 0 {
+		return rule + "."
+	}
+	names := make([]string, len(updates))
+	for i, update := range updates {
+		names[i] = update.String()
+	}
+	return fmt.Sprintf("%s that names the updated dependencies: %s.", rule, strings.Join(names, ", "))
+}
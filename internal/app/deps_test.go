@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+const goModBump = `diff --git a/go.mod b/go.mod
+--- a/go.mod
++++ b/go.mod
+@@ -3,8 +3,9 @@ module example.com/app
+ go 1.23
+ 
+ require (
+-	github.com/go-git/go-git/v5 v5.16.3
++	github.com/go-git/go-git/v5 v5.16.4
+ 	github.com/sergi/go-diff v1.3.1
+-	golang.org/x/net v0.30.0 // indirect
++	golang.org/x/net v0.31.0 // indirect
++	golang.org/x/sync v0.8.0 // indirect
+ )
+diff --git a/go.sum b/go.sum
+--- a/go.sum
++++ b/go.sum
+@@ -1,2 +1,2 @@
+-github.com/go-git/go-git/v5 v5.16.3 h1:abc=
++github.com/go-git/go-git/v5 v5.16.4 h1:def=
+`
+
+const npmBump = `diff --git a/package.json b/package.json
+--- a/package.json
++++ b/package.json
+@@ -1,10 +1,10 @@
+ {
+   "name": "web",
+-  "version": "1.0.0",
++  "version": "1.0.1",
+   "dependencies": {
+-    "lodash": "^4.17.20",
++    "lodash": "^4.17.21",
+-    "left-pad": "1.3.0"
++    "react": "~18.2.0"
+   }
+ }
+diff --git a/package-lock.json b/package-lock.json
+--- a/package-lock.json
++++ b/package-lock.json
+@@ -1 +1 @@
+-    "lodash": "4.17.20",
++    "lodash": "4.17.21",
+`
+
+func TestParseDependencyUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected []dependencyUpdate
+	}{
+		{
+			name: "Go modules",
+			diff: goModBump,
+			expected: []dependencyUpdate{
+				{Name: "github.com/go-git/go-git/v5", From: "v5.16.3", To: "v5.16.4"},
+				{Name: "golang.org/x/net", From: "v0.30.0", To: "v0.31.0"},
+				{Name: "golang.org/x/sync", To: "v0.8.0"},
+			},
+		},
+		{
+			name: "npm",
+			diff: npmBump,
+			expected: []dependencyUpdate{
+				{Name: "left-pad", From: "1.3.0"},
+				{Name: "lodash", From: "^4.17.20", To: "^4.17.21"},
+				{Name: "react", To: "~18.2.0"},
+			},
+		},
+		{
+			name: "pip",
+			diff: "diff --git a/requirements.txt b/requirements.txt\n--- a/requirements.txt\n+++ b/requirements.txt\n@@ -1,2 +1,2 @@\n-requests==2.31.0\n+requests==2.32.3\n django>=4.2\n",
+			expected: []dependencyUpdate{
+				{Name: "requests", From: "2.31.0", To: "2.32.3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDependencyUpdates(tt.diff); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsDependencyOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected bool
+	}{
+		{name: "Go bump", diff: goModBump, expected: true},
+		{name: "npm bump", diff: npmBump, expected: true},
+		{name: "With source change", diff: goModBump + fileDiff("main.go", 3), expected: false},
+		{name: "Empty", diff: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDependencyOnly(tt.diff); got != tt.expected {
+				t.Errorf("isDependencyOnly() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApp_Run_DependencyUpdate(t *testing.T) {
+	tests := []struct {
+		name          string
+		diff          string
+		expectedRule  string
+		expectDepRule bool
+	}{
+		{
+			name:          "Go bump",
+			diff:          goModBump,
+			expectedRule:  "github.com/go-git/go-git/v5 v5.16.3 -> v5.16.4, golang.org/x/net v0.30.0 -> v0.31.0, golang.org/x/sync v0.8.0 (added)",
+			expectDepRule: true,
+		},
+		{
+			name:          "npm bump",
+			diff:          npmBump,
+			expectedRule:  "left-pad 1.3.0 (removed), lodash ^4.17.20 -> ^4.17.21, react ~18.2.0 (added)",
+			expectDepRule: true,
+		},
+		{
+			name: "Source change",
+			diff: npmBump + fileDiff("src/index.js", 5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentRules string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return tt.diff, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sentRules = rules
+					return message("chore(deps): bump dependencies"), nil
+				},
+			})
+
+			if _, err := app.Run(context.Background(), RunOptions{}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := strings.Contains(sentRules, "chore(deps)"); got != tt.expectDepRule {
+				t.Errorf("expected dependency rule %v, got rules %q", tt.expectDepRule, sentRules)
+			}
+			if !strings.Contains(sentRules, tt.expectedRule) {
+				t.Errorf("expected rules to name %q, got %q", tt.expectedRule, sentRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// defaultTestPatterns identify test files when no test_patterns are configured
+var defaultTestPatterns = []string{
+	"*_test.go",
+	"*.test.js", "*.test.ts", "*.test.jsx", "*.test.tsx",
+	"*.spec.js", "*.spec.ts", "*.spec.jsx", "*.spec.tsx",
+	"test_*.py", "*_test.py",
+	"*Test.java", "*Tests.cs",
+}
+
+// generatedPatterns identify lockfiles and generated sources whose changes
+// usually follow from a handwritten change elsewhere
+var generatedPatterns = []string{
+	"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "go.sum",
+	"Cargo.lock", "Gemfile.lock", "poetry.lock", "composer.lock",
+	"*.pb.go", "*_pb2.py", "*.generated.*", "*_generated.go", "*.min.js",
+}
+
+// mostlyGeneratedThreshold is the share of changed lines in generated
+// files above which a diff counts as mostly generated
+const mostlyGeneratedThreshold = 0.5
+
+// generatedShare returns the fraction of changed lines in diff that belong
+// to generated files, or 0 if nothing changed
+func generatedShare(diff string) float64 {
+	total, generated := 0, 0
+	for _, stat := range git.DiffStats(diff) {
+		changed := stat.Insertions + stat.Deletions
+		total += changed
+		if matchesAny(stat.Path, generatedPatterns) {
+			generated += changed
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(generated) / float64(total)
+}
+
+// isMostlyGenerated reports whether generated files account for most of
+// the changed lines in diff
+func isMostlyGenerated(diff string) bool {
+	return generatedShare(diff) > mostlyGeneratedThreshold
+}
+
+// matchesAny reports whether filePath matches one of the glob patterns.
+// Patterns without a slash match the base name, so "*_test.go" matches
+// files in any directory; patterns with a slash match the full path.
+func matchesAny(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		target := path.Base(filePath)
+		if strings.Contains(pattern, "/") {
+			target = filePath
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterDiff keeps only the file sections of diff whose path passes keep
+func filterDiff(diff string, keep func(path string) bool) string {
+	var kept []git.FileDiff
+	for _, file := range git.SplitDiff(diff) {
+		if keep(file.Path) {
+			kept = append(kept, file)
+		}
+	}
+	return git.JoinDiff(kept)
+}
+
+// conventionalPrefix matches the "<type>(<scope>)!:" prefix of a message
+var conventionalPrefix = regexp.MustCompile(`^[a-zA-Z]+(\([^)]*\))?(!)?:\s*`)
+
+// forceType rewrites the conventional commit type of message to commitType,
+// keeping any scope and breaking-change marker. Messages without a type
+// get one prepended.
+func forceType(message, commitType string) string {
+	m := conventionalPrefix.FindStringSubmatchIndex(message)
+	if m == nil {
+		return commitType + ": " + message
+	}
+	scopeAndBang := ""
+	if m[2] != -1 {
+		scopeAndBang += message[m[2]:m[3]]
+	}
+	if m[4] != -1 {
+		scopeAndBang += message[m[4]:m[5]]
+	}
+	return commitType + scopeAndBang + ": " + message[m[1]:]
+}
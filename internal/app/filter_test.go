@@ -0,0 +1,288 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		path     string
+		patterns []string
+		expected bool
+	}{
+		{path: "internal/app/app_test.go", patterns: defaultTestPatterns, expected: true},
+		{path: "web/src/login.spec.ts", patterns: defaultTestPatterns, expected: true},
+		{path: "tests/test_api.py", patterns: defaultTestPatterns, expected: true},
+		{path: "internal/app/app.go", patterns: defaultTestPatterns, expected: false},
+		{path: "web/src/login.ts", patterns: defaultTestPatterns, expected: false},
+		{path: "e2e/login.ts", patterns: []string{"e2e/*"}, expected: true},
+		{path: "src/e2e/login.ts", patterns: []string{"e2e/*"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := matchesAny(tt.path, tt.patterns); got != tt.expected {
+				t.Errorf("matchesAny(%q) = %v, expected %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestForceType(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{message: "feat: add login tests", expected: "test: add login tests"},
+		{message: "fix(auth): cover expired tokens", expected: "test(auth): cover expired tokens"},
+		{message: "refactor(api)!: rewrite fixtures", expected: "test(api)!: rewrite fixtures"},
+		{message: "test: add cases", expected: "test: add cases"},
+		{message: "add missing cases", expected: "test: add missing cases"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := forceType(tt.message, "test"); got != tt.expected {
+				t.Errorf("forceType(%q) = %q, expected %q", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
+
+const mixedDiff = `diff --git a/app.go b/app.go
+--- a/app.go
++++ b/app.go
+@@ -1 +1 @@
+-old
++new
+diff --git a/app_test.go b/app_test.go
+--- a/app_test.go
++++ b/app_test.go
+@@ -1 +1 @@
+-old test
++new test
+`
+
+func TestApp_Run_TestsOnly(t *testing.T) {
+	var sentDiff, sentRules string
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return mixedDiff, nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			sentDiff, sentRules = diff, rules
+			return message("feat(app): cover new behaviour"), nil
+		},
+	})
+
+	result, err := app.Run(context.Background(), RunOptions{TestsOnly: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(sentDiff, "diff --git a/app.go") {
+		t.Errorf("expected non-test file to be excluded, got diff %q", sentDiff)
+	}
+	if !strings.Contains(sentDiff, "diff --git a/app_test.go") {
+		t.Errorf("expected test file in diff, got %q", sentDiff)
+	}
+	if !strings.Contains(sentRules, `"test" type`) {
+		t.Errorf("expected rules to require the test type, got %q", sentRules)
+	}
+	if result.Message != "test(app): cover new behaviour" {
+		t.Errorf("expected test type to be enforced, got %q", result.Message)
+	}
+}
+
+func TestApp_Run_TestsOnlyCustomPatterns(t *testing.T) {
+	var sentDiff string
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return mixedDiff, nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			sentDiff = diff
+			return message("test: update"), nil
+		},
+	})
+	app.Config = &config.Config{TestPatterns: []string{"app.go"}}
+
+	if _, err := app.Run(context.Background(), RunOptions{TestsOnly: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(sentDiff, "diff --git a/app.go") || strings.Contains(sentDiff, "app_test.go") {
+		t.Errorf("expected only app.go in diff, got %q", sentDiff)
+	}
+}
+
+func TestApp_Run_TestsOnlyNoTestFiles(t *testing.T) {
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc: func() (string, error) {
+			return "diff --git a/app.go b/app.go\n+new\n", nil
+		},
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			t.Error("AI should not be called without test files")
+			return message(""), nil
+		},
+	})
+
+	if _, err := app.Run(context.Background(), RunOptions{TestsOnly: true}); err == nil || !strings.Contains(err.Error(), "no staged test files") {
+		t.Errorf("expected no staged test files error, got %v", err)
+	}
+}
+
+// fileDiff builds a diff section for path with the given number of added lines
+func fileDiff(path string, added int) string {
+	return "diff --git a/" + path + " b/" + path + "\n" +
+		"--- a/" + path + "\n+++ b/" + path + "\n" +
+		"@@ -0,0 +1 @@\n" + strings.Repeat("+line\n", added)
+}
+
+func TestIsMostlyGenerated(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected bool
+	}{
+		{
+			name:     "Mostly lockfile",
+			diff:     fileDiff("go.mod", 2) + fileDiff("go.sum", 40),
+			expected: true,
+		},
+		{
+			name:     "Mostly generated protobuf",
+			diff:     fileDiff("api/user.proto", 5) + fileDiff("api/user.pb.go", 200),
+			expected: true,
+		},
+		{
+			name:     "Generated name pattern",
+			diff:     fileDiff("src/schema.generated.ts", 30) + fileDiff("src/schema.ts", 10),
+			expected: true,
+		},
+		{
+			name:     "Mostly handwritten",
+			diff:     fileDiff("internal/app/app.go", 60) + fileDiff("package-lock.json", 20),
+			expected: false,
+		},
+		{
+			name:     "Only handwritten",
+			diff:     fileDiff("main.go", 3),
+			expected: false,
+		},
+		{
+			name:     "Empty",
+			diff:     "",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMostlyGenerated(tt.diff); got != tt.expected {
+				t.Errorf("isMostlyGenerated() = %v, expected %v (share %.2f)", got, tt.expected, generatedShare(tt.diff))
+			}
+		})
+	}
+}
+
+func TestApp_Run_WarnsOnGeneratedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected bool
+	}{
+		{name: "Mostly generated", diff: fileDiff("yarn.lock", 50) + fileDiff("package.json", 1), expected: true},
+		{name: "Mostly handwritten", diff: fileDiff("index.js", 50) + fileDiff("yarn.lock", 1), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentRules string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return tt.diff, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sentRules = rules
+					return message("chore(deps): bump left-pad"), nil
+				},
+			})
+			app.Config = &config.Config{}
+
+			result, err := app.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.MostlyGenerated != tt.expected {
+				t.Errorf("expected MostlyGenerated %v, got %v", tt.expected, result.MostlyGenerated)
+			}
+			if got := strings.Contains(sentRules, "generated content"); got != tt.expected {
+				t.Errorf("expected generated-content rule %v, got rules %q", tt.expected, sentRules)
+			}
+		})
+	}
+}
+
+func TestApp_Run_ExcludePaths(t *testing.T) {
+	diff := fileDiff("package-lock.json", 500) + fileDiff("src/index.js", 3)
+
+	var sentDiff string
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return diff, nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			sentDiff = diff
+			return message("feat: add index"), nil
+		},
+	})
+	app.Config = &config.Config{ExcludePaths: []string{"package-lock.json", "dist/"}}
+
+	if _, err := app.Run(context.Background(), RunOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sentDiff != fileDiff("src/index.js", 3) {
+		t.Errorf("expected only the source file in the diff, got %q", sentDiff)
+	}
+}
+
+func TestApp_Run_ExcludePathsOnlyExcluded(t *testing.T) {
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return fileDiff("go.sum", 10), nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			t.Error("AI should not be called when every file is excluded")
+			return message(""), nil
+		},
+	})
+	app.Config = &config.Config{ExcludePaths: []string{"go.sum"}}
+
+	if _, err := app.Run(context.Background(), RunOptions{}); err == nil || !strings.Contains(err.Error(), "exclude_paths") {
+		t.Errorf("expected exclude_paths error, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// recentSubjects returns the recent commit subjects to show as style
+// examples, if history_context_count is set. Failing to read them only
+// produces a warning.
+func (a *App) recentSubjects() []string {
+	if a.Config == nil || a.Config.HistoryContextCount <= 0 {
+		return nil
+	}
+	subjects, err := a.Git.GetRecentCommitSubjects(a.Config.HistoryContextCount)
+	if err != nil {
+		fmt.Fprintf(a.status(), "Warning: failed to read recent commits: %v\n", err)
+		return nil
+	}
+	return subjects
+}
+
+// historyRule shows recent subjects as examples of the repository's style
+func historyRule(subjects []string) string {
+	var sb strings.Builder
+	sb.WriteString("Recent commit style:")
+	for _, subject := range subjects {
+		sb.WriteString("\n  - " + subject)
+	}
+	sb.WriteString("\n  Match the tone and format of these recent commits, but describe only this change.")
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestApp_Run_HistoryContext(t *testing.T) {
+	subjects := []string{"feat(api): add rate limiting", "fix(db): close idle connections"}
+
+	tests := []struct {
+		name          string
+		config        *config.Config
+		source        string
+		subjectsErr   error
+		expectedCount int
+		expectSection bool
+		expectWarning bool
+	}{
+		{
+			name:          "Off by default",
+			config:        &config.Config{},
+			expectSection: false,
+		},
+		{
+			name:          "Recent subjects are included",
+			config:        &config.Config{HistoryContextCount: 5},
+			expectedCount: 5,
+			expectSection: true,
+		},
+		{
+			name:          "Patch sources skip the history",
+			config:        &config.Config{HistoryContextCount: 5},
+			source:        "stdin",
+			expectSection: false,
+		},
+		{
+			name:          "Failing to read history only warns",
+			config:        &config.Config{HistoryContextCount: 5},
+			subjectsErr:   errors.New("broken repository"),
+			expectedCount: 5,
+			expectSection: false,
+			expectWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var askedFor int
+			var status bytes.Buffer
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+				GetRecentCommitSubjectsFunc: func(n int) ([]string, error) {
+					askedFor = n
+					return subjects, tt.subjectsErr
+				},
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				BuildPromptFunc: func(diff, rules string) string { return rules },
+			})
+			app.Config = tt.config
+			app.Input = strings.NewReader("diff")
+			app.Status = &status
+
+			result, err := app.Run(context.Background(), RunOptions{DryRun: true, Source: tt.source})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if askedFor != tt.expectedCount {
+				t.Errorf("expected %d subjects requested, got %d", tt.expectedCount, askedFor)
+			}
+
+			hasSection := strings.Contains(result.Prompt, "Recent commit style:")
+			if hasSection != tt.expectSection {
+				t.Errorf("expected history section %v, got prompt:\n%s", tt.expectSection, result.Prompt)
+			}
+			if tt.expectSection {
+				for _, subject := range subjects {
+					if !strings.Contains(result.Prompt, "- "+subject) {
+						t.Errorf("expected subject %q in prompt:\n%s", subject, result.Prompt)
+					}
+				}
+			}
+			if hasWarning := strings.Contains(status.String(), "failed to read recent commits"); hasWarning != tt.expectWarning {
+				t.Errorf("expected warning %v, got %q", tt.expectWarning, status.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// hookMarker appears in the header of every hook Init generates, telling
+// them apart from hooks installed by other tools
+const hookMarker = "hook for AI commit message generator"
+
+// hookBackupSuffix is appended to another tool's hook when Init moves it
+// aside
+const hookBackupSuffix = ".backup"
+
+// Hook types that Init can install
+const (
+	// HookPreCommit generates the message before git asks for one, and
+	// commits it itself (the default)
+	HookPreCommit = "pre-commit"
+	// HookPrepareCommitMsg writes the message into the file git opens in
+	// the editor, so the normal commit flow and other hooks run as usual
+	HookPrepareCommitMsg = "prepare-commit-msg"
+)
+
+// InitOptions holds settings for Init
+type InitOptions struct {
+	// HookType is the hook to install, HookPreCommit or
+	// HookPrepareCommitMsg. Empty means HookPreCommit.
+	HookType string
+	// Force reinitializes a repository that already has a config,
+	// overwriting the config, rules, and hook files
+	Force bool
+}
+
+// hookType returns the hook to install
+func (o InitOptions) hookType() string {
+	if o.HookType == "" {
+		return HookPreCommit
+	}
+	return o.HookType
+}
+
+// hookFilePath returns where the hook of hookType lives in hooksDir
+func hookFilePath(hooksDir, hookType string) string {
+	hookPath := filepath.Join(hooksDir, hookType)
+
+	// On Windows, use .bat extension for batch files, otherwise no extension
+	if runtime.GOOS == "windows" {
+		// Try to detect if PowerShell is preferred, otherwise use batch
+		// For now, we'll create a .bat file that can call PowerShell if needed
+		hookPath = hookPath + ".bat"
+	}
+	return hookPath
+}
+
+// hookBackupPath returns where an existing hook at hookPath should be moved
+// before Init writes its own, or "" if nothing needs backing up: there is
+// no hook, it is one Init generated, or force is set. It refuses to
+// overwrite an earlier backup.
+func hookBackupPath(hookPath string, force bool) (string, error) {
+	content, err := os.ReadFile(hookPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read existing hook: %w", err)
+	}
+	if force || strings.Contains(string(content), hookMarker) {
+		return "", nil
+	}
+
+	backupPath := hookPath + hookBackupSuffix
+	if _, err := os.Stat(backupPath); err == nil {
+		return "", fmt.Errorf("%s was installed by another tool and %s already exists; move one of them aside, or use --force to overwrite the hook", hookPath, backupPath)
+	}
+	return backupPath, nil
+}
+
+// generateUnixPrepareCommitMsgHook generates a shell prepare-commit-msg
+// hook. Messages git already prepared (merges, squashes, --amend, -m) are
+// kept, and a failure never blocks the commit.
+func (a *App) generateUnixPrepareCommitMsgHook() string {
+	return `#!/bin/sh
+# prepare-commit-msg hook for AI commit message generator
+# $1 is the commit message file, $2 where the message came from (message,
+# template, merge, squash or commit), if anywhere
+
+case "$2" in
+    merge|squash|commit)
+        # Keep the message git already prepared
+        exit 0
+        ;;
+esac
+
+# Nothing staged, e.g. --allow-empty: leave the message to the user
+if git diff --staged --quiet; then
+    exit 0
+fi
+
+# Fills the file only if it has no message yet
+if ! generate-commit --interactive=false --message-file "$1" > /dev/null; then
+    echo "generate-commit failed; write the commit message yourself." >&2
+fi
+exit 0
+`
+}
+
+// generateWindowsPrepareCommitMsgHook generates a batch prepare-commit-msg
+// hook for Windows
+func (a *App) generateWindowsPrepareCommitMsgHook() string {
+	return "@echo off\n" +
+		"REM prepare-commit-msg hook for AI commit message generator (Windows)\n" +
+		"REM %1 is the commit message file, %2 where the message came from, if anywhere\n\n" +
+		"REM Keep the message git already prepared\n" +
+		"if \"%~2\"==\"merge\" exit /b 0\n" +
+		"if \"%~2\"==\"squash\" exit /b 0\n" +
+		"if \"%~2\"==\"commit\" exit /b 0\n\n" +
+		"REM Nothing staged, e.g. --allow-empty: leave the message to the user\n" +
+		"git diff --staged --quiet >nul 2>&1\n" +
+		"if %errorlevel% equ 0 exit /b 0\n\n" +
+		"REM Fills the file only if it has no message yet\n" +
+		"generate-commit --interactive=false --message-file \"%~1\" >nul\n" +
+		"if errorlevel 1 echo generate-commit failed; write the commit message yourself. 1>&2\n" +
+		"exit /b 0\n"
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApp_GeneratePrepareCommitMsgHook(t *testing.T) {
+	app := NewApp(nil, nil, nil, nil)
+
+	tests := []struct {
+		name        string
+		script      string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:   "Unix",
+			script: app.generateUnixPrepareCommitMsgHook(),
+			contains: []string{
+				"#!/bin/sh\n",
+				"merge|squash|commit)",
+				"git diff --staged --quiet",
+				`generate-commit --interactive=false --message-file "$1" > /dev/null`,
+				"exit 0\n",
+			},
+			notContains: []string{"--no-verify", "exit 1"},
+		},
+		{
+			name:   "Windows",
+			script: app.generateWindowsPrepareCommitMsgHook(),
+			contains: []string{
+				"@echo off\n",
+				`if "%~2"=="merge" exit /b 0`,
+				`if "%~2"=="squash" exit /b 0`,
+				`if "%~2"=="commit" exit /b 0`,
+				"git diff --staged --quiet",
+				`generate-commit --interactive=false --message-file "%~1" >nul`,
+				"exit /b 0\n",
+			},
+			notContains: []string{"--no-verify", "exit /b 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, expected := range tt.contains {
+				if !strings.Contains(tt.script, expected) {
+					t.Errorf("expected script to contain %q, got:\n%s", expected, tt.script)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(tt.script, unexpected) {
+					t.Errorf("expected script not to contain %q, got:\n%s", unexpected, tt.script)
+				}
+			}
+		})
+	}
+}
+
+func TestApp_GenerateHook(t *testing.T) {
+	app := NewApp(nil, nil, nil, nil)
+
+	tests := []struct {
+		hookType      string
+		expected      string
+		expectedError string
+	}{
+		{hookType: HookPreCommit, expected: "Pre-commit hook"},
+		{hookType: HookPrepareCommitMsg, expected: "prepare-commit-msg hook"},
+		{hookType: "post-commit", expectedError: `unknown hook type "post-commit"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hookType, func(t *testing.T) {
+			script, err := app.generateHook(tt.hookType)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(script, tt.expected) {
+				t.Errorf("expected script to contain %q, got:\n%s", tt.expected, script)
+			}
+		})
+	}
+}
+
+func TestInitOptions_HookType(t *testing.T) {
+	if got := (InitOptions{}).hookType(); got != HookPreCommit {
+		t.Errorf("expected default %q, got %q", HookPreCommit, got)
+	}
+	if got := (InitOptions{HookType: HookPrepareCommitMsg}).hookType(); got != HookPrepareCommitMsg {
+		t.Errorf("expected %q, got %q", HookPrepareCommitMsg, got)
+	}
+}
+
+func TestApp_GeneratePreCommitHook_SkipsMergeAndRebase(t *testing.T) {
+	app := NewApp(nil, nil, nil, nil)
+
+	for name, script := range map[string]string{
+		"Unix":    app.generateUnixHook(),
+		"Windows": app.generateWindowsHook(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			guard := strings.Index(script, "MERGE_HEAD")
+			generate := strings.Index(script, "generate-commit --interactive=false")
+			if guard == -1 || guard > generate {
+				t.Errorf("expected the merge check before generating, got:\n%s", script)
+			}
+			for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+				if !strings.Contains(script, dir) {
+					t.Errorf("expected script to check for %s, got:\n%s", dir, script)
+				}
+			}
+		})
+	}
+}
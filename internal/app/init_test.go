@@ -0,0 +1,189 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newInitRepo returns a repository root with a hooks directory, and the
+// path its pre-commit hook is written to
+func newInitRepo(t *testing.T) (root, hookPath string) {
+	t.Helper()
+	root = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git", "hooks"), 0755); err != nil {
+		t.Fatalf("failed to create hooks dir: %v", err)
+	}
+	hookPath = filepath.Join(root, ".git", "hooks", HookPreCommit)
+	if runtime.GOOS == "windows" {
+		hookPath += ".bat"
+	}
+	return root, hookPath
+}
+
+func TestApp_Init_Force(t *testing.T) {
+	tests := []struct {
+		name        string
+		configExist bool
+		force       bool
+		expectSaved bool
+		expectRules bool
+		expectHook  bool
+	}{
+		{name: "Fresh repository keeps rules", expectSaved: true, expectHook: true},
+		{name: "Already initialized", configExist: true},
+		{name: "Already initialized with force", configExist: true, force: true, expectSaved: true, expectRules: true, expectHook: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, hookPath := newInitRepo(t)
+			rulesPath := filepath.Join(root, ".git-commit-rules-for-ai")
+			for _, path := range []string{rulesPath, hookPath} {
+				if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", path, err)
+				}
+			}
+
+			saved := false
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc: func() (bool, error) { return true, nil },
+				GetRepoRootFunc:  func() (string, error) { return root, nil },
+			}, nil, &MockConfigStore{
+				ConfigExistsFunc: func() (bool, error) { return tt.configExist, nil },
+				SaveDefaultConfigFunc: func(repoRoot string) error {
+					if repoRoot != root {
+						t.Errorf("expected config saved to %q, got %q", root, repoRoot)
+					}
+					saved = true
+					return nil
+				},
+			}, nil)
+
+			if err := app.Init(InitOptions{Force: tt.force}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if saved != tt.expectSaved {
+				t.Errorf("expected config saved %v, got %v", tt.expectSaved, saved)
+			}
+
+			rules, _ := os.ReadFile(rulesPath)
+			if overwritten := string(rules) != "old\n"; overwritten != tt.expectRules {
+				t.Errorf("expected rules overwritten %v, got %q", tt.expectRules, rules)
+			}
+			hook, _ := os.ReadFile(hookPath)
+			if overwritten := string(hook) != "old\n"; overwritten != tt.expectHook {
+				t.Errorf("expected hook overwritten %v, got %q", tt.expectHook, hook)
+			}
+		})
+	}
+}
+
+func TestApp_Init_UnknownHookType(t *testing.T) {
+	root, _ := newInitRepo(t)
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc: func() (bool, error) { return true, nil },
+		GetRepoRootFunc:  func() (string, error) { return root, nil },
+	}, nil, &MockConfigStore{
+		ConfigExistsFunc: func() (bool, error) { return false, nil },
+		SaveDefaultConfigFunc: func(repoRoot string) error {
+			t.Error("expected no config to be saved")
+			return nil
+		},
+	}, nil)
+
+	err := app.Init(InitOptions{HookType: "post-commit"})
+	if err == nil || !strings.Contains(err.Error(), `unknown hook type "post-commit"`) {
+		t.Errorf("expected an unknown hook type error, got %v", err)
+	}
+}
+
+func TestApp_Init_ExistingHook(t *testing.T) {
+	foreign := "#!/bin/sh\nnpx lint-staged\n"
+
+	tests := []struct {
+		name           string
+		existing       string
+		backup         string
+		force          bool
+		expectedError  string
+		expectedBackup string
+		expectReplaced bool
+	}{
+		{
+			name:           "Another tool's hook is backed up",
+			existing:       foreign,
+			expectedBackup: foreign,
+			expectReplaced: true,
+		},
+		{
+			name:           "Generated hook is replaced in place",
+			existing:       "#!/bin/bash\n# Pre-commit hook for AI commit message generator\n",
+			expectReplaced: true,
+		},
+		{
+			name:           "Earlier backup is not overwritten",
+			existing:       foreign,
+			backup:         "#!/bin/sh\nolder\n",
+			expectedError:  "already exists",
+			expectedBackup: "#!/bin/sh\nolder\n",
+		},
+		{
+			name:           "Force overwrites without a backup",
+			existing:       foreign,
+			force:          true,
+			expectReplaced: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, hookPath := newInitRepo(t)
+			backupPath := hookPath + ".backup"
+			if err := os.WriteFile(hookPath, []byte(tt.existing), 0755); err != nil {
+				t.Fatalf("failed to write hook: %v", err)
+			}
+			if tt.backup != "" {
+				if err := os.WriteFile(backupPath, []byte(tt.backup), 0755); err != nil {
+					t.Fatalf("failed to write backup: %v", err)
+				}
+			}
+
+			saved := false
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc: func() (bool, error) { return true, nil },
+				GetRepoRootFunc:  func() (string, error) { return root, nil },
+			}, nil, &MockConfigStore{
+				ConfigExistsFunc:      func() (bool, error) { return false, nil },
+				SaveDefaultConfigFunc: func(repoRoot string) error { saved = true; return nil },
+			}, nil)
+
+			err := app.Init(InitOptions{Force: tt.force})
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				if saved {
+					t.Error("expected nothing to be written after refusing")
+				}
+			} else if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			hook, _ := os.ReadFile(hookPath)
+			if replaced := strings.Contains(string(hook), hookMarker) && string(hook) != tt.existing; replaced != tt.expectReplaced {
+				t.Errorf("expected hook replaced %v, got %q", tt.expectReplaced, hook)
+			}
+			backup, err := os.ReadFile(backupPath)
+			if tt.expectedBackup == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("expected no backup, got %q", backup)
+				}
+			} else if string(backup) != tt.expectedBackup {
+				t.Errorf("expected backup %q, got %q", tt.expectedBackup, backup)
+			}
+		})
+	}
+}
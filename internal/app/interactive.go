@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// review shows the generated message and loops until the user accepts,
+// edits, or quits. Accepted and edited messages are committed; regenerating
+// replaces the result in place.
+func (a *App) review(ctx context.Context, result *RunResult, diff, rules string, opts RunOptions) error {
+	for {
+		fmt.Println("\n" + a.colorize(ColorCyan, result.Message))
+		fmt.Println()
+
+		choice, err := a.prompt("[A]ccept, [E]dit, [R]egenerate, [Q]uit: ")
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(choice) {
+		case "a":
+			result.Outcome = OutcomeAccepted
+			return a.commitResult(result, opts)
+		case "e":
+			edited, err := a.editMessage(result.Message)
+			if err != nil {
+				return fmt.Errorf("failed to edit message: %w", err)
+			}
+			if edited == "" {
+				fmt.Println("Empty message, commit aborted")
+				result.Outcome = OutcomeRejected
+				return nil
+			}
+			result.Message = edited
+			result.Outcome = OutcomeEdited
+			return a.commitResult(result, opts)
+		case "r":
+			// Regenerating asks the model again instead of the cache
+			opts.NoCache = true
+			regenerated, err := a.generate(ctx, diff, rules, opts)
+			if err != nil {
+				return err
+			}
+			*result = *regenerated
+			if result.IsSplitSuggestion {
+				return nil
+			}
+		case "q":
+			fmt.Println("Commit aborted")
+			result.Outcome = OutcomeRejected
+			return nil
+		default:
+			fmt.Printf("Invalid choice %q\n", choice)
+		}
+	}
+}
+
+// commitResult commits the staged changes with the result's message,
+// amending HEAD if opts.Amend is set.
+// With opts.Summary, the staged stats are captured first so the result
+// can describe what landed; failing to read them only produces a warning.
+func (a *App) commitResult(result *RunResult, opts RunOptions) error {
+	var stats []git.FileStat
+	if opts.Summary {
+		var err error
+		if stats, err = a.Git.GetStagedDiffStats(); err != nil {
+			fmt.Fprintf(a.status(), "Warning: failed to read diff stats: %v\n", err)
+			opts.Summary = false
+		}
+	}
+
+	commit := a.Git.CommitWithMessage
+	if opts.Amend {
+		commit = a.Git.AmendWithMessage
+	}
+	commitOpts, err := a.commitOptions(opts)
+	if err != nil {
+		return err
+	}
+	if err := commit(result.Message, commitOpts); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	result.Committed = true
+	if opts.Summary {
+		result.Summary = &CommitSummary{Message: result.Message, Files: stats}
+	}
+	return nil
+}
+
+// editMessage lets the user edit message, using EditMessage if set
+func (a *App) editMessage(message string) (string, error) {
+	if a.EditMessage != nil {
+		return a.EditMessage(message)
+	}
+	return editInEditor(message)
+}
+
+// editInEditor opens message in $EDITOR (falling back to vi, or notepad on
+// Windows) and returns the edited text with '#' comment lines removed
+func editInEditor(message string) (string, error) {
+	file, err := os.CreateTemp("", "COMMIT_EDITMSG-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	content := message + "\n\n# Edit the commit message. Lines starting with '#' are ignored.\n"
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// EDITOR may carry arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return "", errors.New("EDITOR is empty")
+	}
+	cmd := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return stripComments(string(edited)), nil
+}
+
+// stripComments removes '#' comment lines and surrounding whitespace
+func stripComments(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
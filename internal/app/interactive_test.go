@@ -0,0 +1,205 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+	"ai-commit-message-generator/internal/git"
+)
+
+func TestApp_Run_Interactive(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		edited          string
+		expectCommitted bool
+		expectMessage   string
+		expectAICalls   int
+	}{
+		{name: "Accept", input: "a\n", expectCommitted: true, expectMessage: "feat: first", expectAICalls: 1},
+		{name: "Edit", input: "e\n", edited: "feat: edited by hand", expectCommitted: true, expectMessage: "feat: edited by hand", expectAICalls: 1},
+		{name: "Edit to empty aborts", input: "e\n", edited: "", expectCommitted: false, expectMessage: "", expectAICalls: 1},
+		{name: "Regenerate then accept", input: "r\na\n", expectCommitted: true, expectMessage: "feat: second", expectAICalls: 2},
+		{name: "Invalid then quit", input: "x\nq\n", expectCommitted: false, expectAICalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aiCalls := 0
+			var committed []string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+				CommitWithMessageFunc: func(message string, opts git.CommitOptions) error {
+					committed = append(committed, message)
+					return nil
+				},
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					aiCalls++
+					if aiCalls == 1 {
+						return message("feat: first"), nil
+					}
+					return message("feat: second"), nil
+				},
+			})
+			app.Input = strings.NewReader(tt.input)
+			app.EditMessage = func(message string) (string, error) {
+				if message != "feat: first" {
+					t.Errorf("expected editor to receive generated message, got %q", message)
+				}
+				return tt.edited, nil
+			}
+
+			result, err := app.Run(context.Background(), RunOptions{Interactive: true})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if aiCalls != tt.expectAICalls {
+				t.Errorf("expected %d AI calls, got %d", tt.expectAICalls, aiCalls)
+			}
+			if result.Committed != tt.expectCommitted {
+				t.Errorf("expected Committed %v, got %v", tt.expectCommitted, result.Committed)
+			}
+			if tt.expectCommitted {
+				if len(committed) != 1 || committed[0] != tt.expectMessage {
+					t.Errorf("expected commit %q, got %v", tt.expectMessage, committed)
+				}
+			} else if len(committed) != 0 {
+				t.Errorf("expected no commit, got %v", committed)
+			}
+		})
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	got := stripComments("feat: add login\n\nBody line\n# comment\n\n")
+	if got != "feat: add login\n\nBody line" {
+		t.Errorf("unexpected result %q", got)
+	}
+}
+
+func TestApp_Run_SignOff(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *config.Config
+		opts     RunOptions
+		expected bool
+	}{
+		{name: "Off", opts: RunOptions{Commit: true}},
+		{name: "Flag", opts: RunOptions{Commit: true, SignOff: true}, expected: true},
+		{name: "Config", config: &config.Config{SignOff: true}, opts: RunOptions{Commit: true}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *git.CommitOptions
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+				CommitWithMessageFunc: func(message string, opts git.CommitOptions) error {
+					got = &opts
+					return nil
+				},
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return message("feat: add login"), nil
+				},
+			})
+			app.Config = tt.config
+
+			if _, err := app.Run(context.Background(), tt.opts); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got == nil {
+				t.Fatal("expected a commit")
+			}
+			if got.SignOff != tt.expected {
+				t.Errorf("expected SignOff %v, got %v", tt.expected, got.SignOff)
+			}
+		})
+	}
+}
+
+func TestApp_Run_CoAuthors(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *config.Config
+		opts        RunOptions
+		expected    []string
+		expectedErr string
+	}{
+		{name: "None", opts: RunOptions{Commit: true}},
+		{
+			name:     "Several",
+			opts:     RunOptions{Commit: true, CoAuthors: []string{"Ada Lovelace <ada@example.com>", "  Alan  Turing<alan@example.com> "}},
+			expected: []string{"Ada Lovelace <ada@example.com>", "Alan Turing <alan@example.com>"},
+		},
+		{
+			name:     "Config and flag deduped by email",
+			config:   &config.Config{CoAuthors: []string{"Ada Lovelace <ada@example.com>"}},
+			opts:     RunOptions{Commit: true, CoAuthors: []string{"Ada <ADA@example.com>", "Alan Turing <alan@example.com>"}},
+			expected: []string{"Ada Lovelace <ada@example.com>", "Alan Turing <alan@example.com>"},
+		},
+		{
+			name:        "Malformed",
+			opts:        RunOptions{Commit: true, CoAuthors: []string{"ada@example.com"}},
+			expectedErr: `invalid co-author "ada@example.com"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *git.CommitOptions
+			generated := false
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+				CommitWithMessageFunc: func(message string, opts git.CommitOptions) error {
+					got = &opts
+					return nil
+				},
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					generated = true
+					return message("feat: add login"), nil
+				},
+			})
+			app.Config = tt.config
+
+			_, err := app.Run(context.Background(), tt.opts)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				if generated {
+					t.Error("expected the co-authors to be checked before asking the model")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got == nil {
+				t.Fatal("expected a commit")
+			}
+			if !reflect.DeepEqual(got.CoAuthors, tt.expected) {
+				t.Errorf("expected co-authors %q, got %q", tt.expected, got.CoAuthors)
+			}
+		})
+	}
+}
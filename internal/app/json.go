@@ -0,0 +1,35 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+// jsonResult is the machine-readable form of a RunResult
+type jsonResult struct {
+	Type      string `json:"type"`
+	Content   string `json:"content"`
+	Model     string `json:"model"`
+	ElapsedMS int64  `json:"elapsed_ms"`
+}
+
+// JSON encodes the result for scripts and editor plugins, with type
+// "message" or "split"
+func (r *RunResult) JSON() ([]byte, error) {
+	kind := ai.ResultMessage
+	if r.IsSplitSuggestion {
+		kind = ai.ResultSplit
+	}
+	data, err := json.Marshal(jsonResult{
+		Type:      kind,
+		Content:   r.Message,
+		Model:     r.Model,
+		ElapsedMS: r.Elapsed.Milliseconds(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestRunResult_JSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		generated    *ai.GenerateResult
+		expectedType string
+	}{
+		{
+			name:         "Message",
+			generated:    message("feat(auth): add login\n\nUsers asked for \"it\"."),
+			expectedType: "message",
+		},
+		{
+			name:         "Split suggestion",
+			generated:    splitSuggestion("Split into:\n1. auth\n2. docs"),
+			expectedType: "split",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					time.Sleep(5 * time.Millisecond)
+					return tt.generated, nil
+				},
+			})
+			app.Config = &config.Config{Model: "llama3"}
+
+			result, err := app.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			data, err := result.JSON()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var decoded struct {
+				Type      string `json:"type"`
+				Content   string `json:"content"`
+				Model     string `json:"model"`
+				ElapsedMS *int64 `json:"elapsed_ms"`
+			}
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("expected valid JSON, got %q: %v", data, err)
+			}
+			if decoded.Type != tt.expectedType {
+				t.Errorf("expected type %q, got %q", tt.expectedType, decoded.Type)
+			}
+			if decoded.Content != tt.generated.Content {
+				t.Errorf("expected content %q, got %q", tt.generated.Content, decoded.Content)
+			}
+			if decoded.Model != "llama3" {
+				t.Errorf("expected model %q, got %q", "llama3", decoded.Model)
+			}
+			if decoded.ElapsedMS == nil || *decoded.ElapsedMS < 5 {
+				t.Errorf("expected elapsed_ms of at least 5, got %v", decoded.ElapsedMS)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+// Lint checks an existing commit message against the Conventional Commits
+// grammar, the subject length limit, and any structured rules, returning
+// the problems found. Comment lines, as in COMMIT_EDITMSG, are ignored.
+func (a *App) Lint(message string) ([]string, error) {
+	var structured *config.StructuredRules
+	if loader, ok := a.RulesLoader.(config.StructuredLoader); ok {
+		var err error
+		structured, err = loader.LoadStructuredRules()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rules: %w", err)
+		}
+	}
+	return lintMessage(stripComments(message), a.maxSubjectLength(), a.allowedTypes(), structured), nil
+}
+
+// lintMessage returns the problems with message. The structured rules'
+// allowed types and subject length limit, if set, replace types and limit.
+func lintMessage(message string, limit int, types []string, rules *config.StructuredRules) []string {
+	if rules != nil && len(rules.AllowedTypes) > 0 {
+		types = rules.AllowedTypes
+	}
+	var problems []string
+	if err := ai.ValidateConventional(message, types); err != nil {
+		problems = append(problems, err.Error())
+	}
+	parsed, ok := ai.ParseConventional(message)
+
+	if rules != nil && rules.MaxSubjectLength > 0 {
+		limit = rules.MaxSubjectLength
+	}
+	subject := subjectLine(strings.TrimSpace(message))
+	if length := utf8.RuneCountInString(subject); limit > 0 && length > limit {
+		problems = append(problems, fmt.Sprintf("subject is %d characters, over the limit of %d", length, limit))
+	}
+
+	if !ok || rules == nil {
+		return problems
+	}
+	if rules.RequireScope && parsed.Scope == "" {
+		problems = append(problems, "subject has no scope, which the rules require: <type>(<scope>): <description>")
+	}
+	return problems
+}
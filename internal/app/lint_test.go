@@ -0,0 +1,127 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestApp_Lint(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		config   *config.Config
+		rules    *config.StructuredRules
+		expected []string
+	}{
+		{
+			name:    "Passing message",
+			message: "feat(auth): add login\n\nUses the session store.",
+		},
+		{
+			name:    "Comments ignored",
+			message: "fix: handle nil config\n# Please enter the commit message for your changes.\n# On branch main",
+		},
+		{
+			name:     "Not conventional",
+			message:  "Added the login form",
+			expected: []string{`subject "Added the login form" does not match <type>(<scope>): <description>`},
+		},
+		{
+			name:     "Empty",
+			message:  "# only a comment\n",
+			expected: []string{"message is empty"},
+		},
+		{
+			name:     "Subject over the default limit",
+			message:  "feat: " + strings.Repeat("a", 70),
+			expected: []string{"subject is 76 characters, over the limit of 72"},
+		},
+		{
+			name:     "Subject over the configured limit",
+			message:  "feat: add a login form",
+			config:   &config.Config{MaxSubjectLength: 20},
+			expected: []string{"subject is 22 characters, over the limit of 20"},
+		},
+		{
+			name:    "Structured limit replaces the config",
+			message: "feat: add a login form",
+			config:  &config.Config{MaxSubjectLength: 20},
+			rules:   &config.StructuredRules{MaxSubjectLength: 30},
+		},
+		{
+			name:     "Type not allowed by the rules",
+			message:  "docs: describe login",
+			rules:    &config.StructuredRules{AllowedTypes: []string{"feat", "fix"}},
+			expected: []string{`type "docs" is not one of feat, fix`},
+		},
+		{
+			name:    "Rules allow a custom type",
+			message: "perf: cache the index",
+			rules:   &config.StructuredRules{AllowedTypes: []string{"feat", "fix", "perf"}},
+		},
+		{
+			name:     "Type not in allowed_types",
+			message:  "chore: bump deps",
+			config:   &config.Config{AllowedTypes: []string{"feat", "fix", "build"}},
+			expected: []string{`type "chore" is not one of feat, fix, build`},
+		},
+		{
+			name:    "Structured types replace allowed_types",
+			message: "chore: bump deps",
+			config:  &config.Config{AllowedTypes: []string{"feat", "fix", "build"}},
+			rules:   &config.StructuredRules{AllowedTypes: []string{"chore"}},
+		},
+		{
+			name:     "Scope required",
+			message:  "fix: handle nil config",
+			rules:    &config.StructuredRules{RequireScope: true},
+			expected: []string{"subject has no scope, which the rules require: <type>(<scope>): <description>"},
+		},
+		{
+			name:     "Several problems",
+			message:  "style: reformat everything in the repository",
+			rules:    &config.StructuredRules{AllowedTypes: []string{"feat"}, MaxSubjectLength: 30, RequireScope: true},
+			expected: []string{`type "style" is not one of feat`, "subject is 44 characters, over the limit of 30", "subject has no scope, which the rules require: <type>(<scope>): <description>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := &MockStructuredConfig{
+				MockConfig:              MockConfig{LoadRulesFunc: func() (string, error) { return "", nil }},
+				LoadStructuredRulesFunc: func() (*config.StructuredRules, error) { return tt.rules, nil },
+			}
+			a := NewApp(&MockGit{}, rules, nil, nil)
+			a.Config = tt.config
+
+			problems, err := a.Lint(tt.message)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(problems, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, problems)
+			}
+		})
+	}
+}
+
+func TestApp_Lint_RulesError(t *testing.T) {
+	rules := &MockStructuredConfig{
+		LoadStructuredRulesFunc: func() (*config.StructuredRules, error) { return nil, errors.New("invalid yaml") },
+	}
+	a := NewApp(&MockGit{}, rules, nil, nil)
+	if _, err := a.Lint("feat: add login"); err == nil || !strings.Contains(err.Error(), "invalid yaml") {
+		t.Errorf("expected the rules error, got %v", err)
+	}
+
+	// A plain rules loader has no structured rules to check
+	a = NewApp(&MockGit{}, &MockConfig{}, nil, nil)
+	problems, err := a.Lint("feat: add login")
+	if err != nil || len(problems) != 0 {
+		t.Errorf("expected no problems, got %v, %v", problems, err)
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// commentPrefix marks the lines git strips from a commit message, such as
+// the instructions and status it adds to COMMIT_EDITMSG
+const commentPrefix = "#"
+
+// readMessageFile reads a commit message file such as COMMIT_EDITMSG. A
+// missing file reads as empty.
+func readMessageFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read message file: %w", err)
+	}
+	return string(data), nil
+}
+
+// isBlankMessage reports whether content holds only comments and
+// whitespace, i.e. whether the user hasn't written a message yet
+func isBlankMessage(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, commentPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// fillMessageFile writes message to the top of a blank message file,
+// keeping the comments git put there for the editor
+func fillMessageFile(path, message, existing string) error {
+	content := message + "\n"
+	if strings.TrimSpace(existing) != "" {
+		content += "\n" + strings.TrimLeft(existing, "\n")
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write message file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+func TestIsBlankMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{name: "Empty", content: "", expected: true},
+		{name: "Whitespace", content: "\n  \n\t\n", expected: true},
+		{name: "Comments", content: "\n# Please enter the commit message for your changes.\n#\n# On branch main\n", expected: true},
+		{name: "Message", content: "fix: handle errors\n", expected: false},
+		{name: "Message above comments", content: "fix: handle errors\n\n# Please enter the commit message\n", expected: false},
+		{name: "Template text", content: "Ticket: \n# Describe the change\n", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBlankMessage(tt.content); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApp_Run_MessageFile(t *testing.T) {
+	comments := "\n# Please enter the commit message for your changes.\n# On branch main\n"
+
+	tests := []struct {
+		name          string
+		existing      *string
+		generated     *ai.GenerateResult
+		expectedFile  string
+		expectedCalls int
+		expectKept    bool
+	}{
+		{
+			name:          "Missing file",
+			generated:     message("feat: add login"),
+			expectedFile:  "feat: add login\n",
+			expectedCalls: 1,
+		},
+		{
+			name:          "Empty file",
+			existing:      ptr(""),
+			generated:     message("feat: add login"),
+			expectedFile:  "feat: add login\n",
+			expectedCalls: 1,
+		},
+		{
+			name:          "Only comments",
+			existing:      ptr(comments),
+			generated:     message("feat: add login"),
+			expectedFile:  "feat: add login\n\n# Please enter the commit message for your changes.\n# On branch main\n",
+			expectedCalls: 1,
+		},
+		{
+			name:          "Existing message",
+			existing:      ptr("fix: my own words\n" + comments),
+			expectedFile:  "fix: my own words\n" + comments,
+			expectedCalls: 0,
+			expectKept:    true,
+		},
+		{
+			name:          "Split suggestion",
+			existing:      ptr(comments),
+			generated:     splitSuggestion("Split into auth and docs"),
+			expectedFile:  comments,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+			if tt.existing != nil {
+				if err := os.WriteFile(path, []byte(*tt.existing), 0644); err != nil {
+					t.Fatalf("failed to write message file: %v", err)
+				}
+			}
+
+			calls := 0
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					calls++
+					return tt.generated, nil
+				},
+			})
+
+			result, err := app.Run(context.Background(), RunOptions{MessageFile: path})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+			if result.KeptMessageFile != tt.expectKept {
+				t.Errorf("expected KeptMessageFile %v, got %v", tt.expectKept, result.KeptMessageFile)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil && tt.expectedFile != "" {
+				t.Fatalf("failed to read message file: %v", err)
+			}
+			if string(data) != tt.expectedFile {
+				t.Errorf("expected file %q, got %q", tt.expectedFile, string(data))
+			}
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
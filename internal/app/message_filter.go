@@ -0,0 +1,31 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-commit-message-generator/internal/shell"
+)
+
+// messageFilterTimeout bounds how long a message filter command may run
+const messageFilterTimeout = 10 * time.Second
+
+// runMessageFilter pipes message through the shell command and returns its
+// output as the new message. A non-zero exit rejects the message, with the
+// command's stderr as the reason.
+func runMessageFilter(command, message string, timeout time.Duration) (string, error) {
+	filtered, err := shell.Run("message filter", command, strings.NewReader(message), timeout)
+	var exitErr *shell.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("message rejected by filter: %s", exitErr.Reason)
+	}
+	if err != nil {
+		return "", err
+	}
+	if filtered == "" {
+		return "", errors.New("message filter returned an empty message")
+	}
+	return filtered, nil
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+	"ai-commit-message-generator/internal/git"
+)
+
+func TestRunMessageFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("filter scripts use sh")
+	}
+
+	tests := []struct {
+		name        string
+		command     string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "Rewrites message",
+			command:  `sed 's/^feat/feature/'`,
+			expected: "feature: add login",
+		},
+		{
+			name:     "Appends trailer",
+			command:  `cat; printf '\n\nReviewed-by: bot'`,
+			expected: "feat: add login\n\nReviewed-by: bot",
+		},
+		{
+			name:        "Rejects message",
+			command:     `echo "missing ticket reference" >&2; exit 1`,
+			expectedErr: "message rejected by filter: missing ticket reference",
+		},
+		{
+			name:        "Empty output",
+			command:     `cat > /dev/null`,
+			expectedErr: "empty message",
+		},
+		{
+			name:        "Times out",
+			command:     `sleep 5`,
+			expectedErr: "timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runMessageFilter(tt.command, "feat: add login", 200*time.Millisecond)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApp_Run_MessageFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("filter scripts use sh")
+	}
+
+	tests := []struct {
+		name        string
+		command     string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "Rewrite", command: `tr '[:lower:]' '[:upper:]'`, expected: "FIX: HANDLE NIL"},
+		{name: "Reject", command: `exit 3`, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			committed := false
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:      func() (bool, error) { return true, nil },
+				HasStagedChangesFunc:  func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:     func() (string, error) { return "diff", nil },
+				CommitWithMessageFunc: func(message string, opts git.CommitOptions) error { committed = true; return nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return message("fix: handle nil"), nil
+				},
+			})
+			app.Config = &config.Config{MessageFilterCommand: tt.command}
+
+			result, err := app.Run(context.Background(), RunOptions{Commit: true})
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if committed {
+					t.Error("expected a rejected message not to be committed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Message != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, result.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Outcome is what became of a generated message
+type Outcome string
+
+const (
+	// OutcomeGenerated means the message was only printed
+	OutcomeGenerated Outcome = "generated"
+	// OutcomeCommitted means the message was committed without review
+	OutcomeCommitted Outcome = "committed"
+	// OutcomeAccepted means the message was accepted in interactive review
+	OutcomeAccepted Outcome = "accepted"
+	// OutcomeEdited means the message was edited, then committed
+	OutcomeEdited Outcome = "edited"
+	// OutcomeRejected means the user quit interactive review, or emptied
+	// the message while editing it
+	OutcomeRejected Outcome = "rejected"
+	// OutcomeSplit means the model suggested splitting the changes
+	OutcomeSplit Outcome = "split"
+)
+
+// logEntry is one line of the message log. The diff is only recorded as a
+// hash, so the log doesn't copy source code around.
+type logEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Model     string    `json:"model"`
+	Outcome   Outcome   `json:"outcome"`
+	Message   string    `json:"message"`
+	DiffHash  string    `json:"diff_sha256"`
+}
+
+// logResult appends result to the configured log file, if any. A failure
+// to write it only produces a warning.
+func (a *App) logResult(result *RunResult, diff string) {
+	if a.Config == nil || a.Config.LogFile == "" {
+		return
+	}
+	if err := appendLogEntry(a.Config.LogFile, newLogEntry(result, diff, time.Now())); err != nil {
+		fmt.Fprintf(a.status(), "Warning: failed to write log file: %v\n", err)
+	}
+}
+
+// newLogEntry describes result for the message log
+func newLogEntry(result *RunResult, diff string, now time.Time) logEntry {
+	sum := sha256.Sum256([]byte(diff))
+	return logEntry{
+		Timestamp: now.UTC(),
+		Model:     result.Model,
+		Outcome:   result.Outcome,
+		Message:   result.Message,
+		DiffHash:  hex.EncodeToString(sum[:]),
+	}
+}
+
+// appendLogEntry writes entry as a single JSON line at the end of path,
+// creating the file if needed
+func appendLogEntry(path string, entry logEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+	"ai-commit-message-generator/internal/git"
+)
+
+func TestApp_Run_LogFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            RunOptions
+		input           string
+		expectedOutcome Outcome
+		expectedMessage string
+	}{
+		{name: "Printed", expectedOutcome: OutcomeGenerated, expectedMessage: "feat: add login"},
+		{name: "Committed", opts: RunOptions{Commit: true}, expectedOutcome: OutcomeCommitted, expectedMessage: "feat: add login"},
+		{name: "Accepted", opts: RunOptions{Interactive: true}, input: "a\n", expectedOutcome: OutcomeAccepted, expectedMessage: "feat: add login"},
+		{name: "Edited", opts: RunOptions{Interactive: true}, input: "e\n", expectedOutcome: OutcomeEdited, expectedMessage: "feat: add login form"},
+		{name: "Rejected", opts: RunOptions{Interactive: true}, input: "q\n", expectedOutcome: OutcomeRejected, expectedMessage: "feat: add login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "messages.jsonl")
+			// An existing line is appended to, not replaced
+			if err := os.WriteFile(logPath, []byte("{}\n"), 0644); err != nil {
+				t.Fatalf("failed to write log: %v", err)
+			}
+
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:      func() (bool, error) { return true, nil },
+				HasStagedChangesFunc:  func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:     func() (string, error) { return "the-diff", nil },
+				CommitWithMessageFunc: func(message string, opts git.CommitOptions) error { return nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return message("feat: add login"), nil
+				},
+			})
+			app.Config = &config.Config{Model: "test-model", LogFile: logPath}
+			app.Input = strings.NewReader(tt.input)
+			app.EditMessage = func(message string) (string, error) { return "feat: add login form", nil }
+
+			before := time.Now().Add(-time.Second)
+			result, err := app.Run(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Outcome != tt.expectedOutcome {
+				t.Errorf("expected outcome %q, got %q", tt.expectedOutcome, result.Outcome)
+			}
+
+			data, err := os.ReadFile(logPath)
+			if err != nil {
+				t.Fatalf("failed to read log: %v", err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if len(lines) != 2 || lines[0] != "{}" {
+				t.Fatalf("expected one line appended to the log, got:\n%s", data)
+			}
+			var entry struct {
+				Timestamp time.Time `json:"timestamp"`
+				Model     string    `json:"model"`
+				Outcome   Outcome   `json:"outcome"`
+				Message   string    `json:"message"`
+				DiffHash  string    `json:"diff_sha256"`
+			}
+			if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+				t.Fatalf("expected a JSON line, got %q: %v", lines[1], err)
+			}
+			sum := sha256.Sum256([]byte("the-diff"))
+			if entry.Model != "test-model" || entry.Outcome != tt.expectedOutcome || entry.Message != tt.expectedMessage || entry.DiffHash != hex.EncodeToString(sum[:]) {
+				t.Errorf("unexpected log entry %+v", entry)
+			}
+			if entry.Timestamp.Before(before) || entry.Timestamp.After(time.Now()) {
+				t.Errorf("unexpected timestamp %v", entry.Timestamp)
+			}
+			if strings.Contains(lines[1], "the-diff") {
+				t.Errorf("expected the diff itself to stay out of the log, got %q", lines[1])
+			}
+		})
+	}
+}
+
+func TestApp_Run_LogFileDisabled(t *testing.T) {
+	dir := t.TempDir()
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return "the-diff", nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			return message("feat: add login"), nil
+		},
+	})
+	app.Config = &config.Config{}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	if _, err := app.Run(context.Background(), RunOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no log to be written, got %v", entries)
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestApp_Run_OfflineFallback(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	diff := "diff --git a/cmd/main.go b/cmd/main.go\n--- a/cmd/main.go\n+++ b/cmd/main.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	tests := []struct {
+		name          string
+		config        *config.Config
+		aiErr         error
+		expectedMsg   string
+		expectedError string
+	}{
+		{
+			name:        "Falls back on a connection error",
+			config:      &config.Config{AllowOfflineFallback: true},
+			aiErr:       dialErr,
+			expectedMsg: "chore(cmd): update main.go",
+		},
+		{
+			name:          "Fails when the fallback is off",
+			config:        &config.Config{},
+			aiErr:         dialErr,
+			expectedError: "connection refused",
+		},
+		{
+			name:          "Other errors are not masked",
+			config:        &config.Config{AllowOfflineFallback: true},
+			aiErr:         errors.New("API error (status 401)"),
+			expectedError: "status 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status bytes.Buffer
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return diff, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return nil, tt.aiErr
+				},
+			})
+			app.Config = tt.config
+			app.Status = &status
+
+			result, err := app.Run(context.Background(), RunOptions{})
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Message != tt.expectedMsg {
+				t.Errorf("expected message %q, got %q", tt.expectedMsg, result.Message)
+			}
+			if !strings.Contains(status.String(), "offline") {
+				t.Errorf("expected an offline warning, got %q", status.String())
+			}
+		})
+	}
+}
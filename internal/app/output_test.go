@@ -0,0 +1,115 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+func TestApp_Run_Output(t *testing.T) {
+	tests := []struct {
+		name          string
+		generated     *ai.GenerateResult
+		interactive   bool
+		input         string
+		expectedFile  string
+		expectWritten bool
+	}{
+		{
+			name:          "Message",
+			generated:     message("feat(auth): add login\n\nUsers asked for it."),
+			expectedFile:  "feat(auth): add login\n\nUsers asked for it.",
+			expectWritten: true,
+		},
+		{
+			name:      "Split suggestion",
+			generated: splitSuggestion("Split into auth and docs"),
+		},
+		{
+			name:        "Rejected in review",
+			generated:   message("feat(auth): add login"),
+			interactive: true,
+			input:       "q\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "msg.txt")
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return tt.generated, nil
+				},
+			})
+
+			app.Input = strings.NewReader(tt.input)
+			messageFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+
+			captureStdout(t, func() {
+				if _, err := app.Run(context.Background(), RunOptions{Output: path, MessageFile: messageFile, Interactive: tt.interactive}); err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			})
+
+			data, err := os.ReadFile(path)
+			if !tt.expectWritten {
+				if !os.IsNotExist(err) {
+					t.Errorf("expected no file, got %q (err %v)", data, err)
+				}
+				if data, err := os.ReadFile(messageFile); !os.IsNotExist(err) {
+					t.Errorf("expected no message file, got %q (err %v)", data, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if string(data) != tt.expectedFile {
+				t.Errorf("expected file %q, got %q", tt.expectedFile, string(data))
+			}
+		})
+	}
+}
+
+func TestApp_Run_StatusOutput(t *testing.T) {
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", errors.New("permission denied") },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			return message("feat(auth): add login"), nil
+		},
+	})
+	var status bytes.Buffer
+	app.Status = &status
+
+	stdout := captureStdout(t, func() {
+		if _, err := app.Run(context.Background(), RunOptions{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	for _, expected := range []string{"Warning: failed to load rules", "Generating commit message..."} {
+		if !strings.Contains(status.String(), expected) {
+			t.Errorf("expected status output to contain %q, got %q", expected, status.String())
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"slices"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// vendored matches third-party code checked into the repository
+var vendored = git.NewIgnoreMatcher([]string{"vendor/", "node_modules/", "third_party/"})
+
+// filePriority ranks how much a file's diff tells the model about the
+// change. Lower priorities are left out first when the diff is too large.
+type filePriority int
+
+const (
+	priorityVendored filePriority = iota
+	priorityGenerated
+	prioritySource
+)
+
+// classifyFile returns the priority of the file at path: vendored code,
+// then lockfiles and generated sources, then everything else
+func classifyFile(path string) filePriority {
+	if vendored.Ignored(path) {
+		return priorityVendored
+	}
+	if matchesAny(path, generatedPatterns) {
+		return priorityGenerated
+	}
+	return prioritySource
+}
+
+// dropOrder returns the indexes of files in the order they should be left
+// out of a diff: lowest priority first, and the largest first within a
+// priority
+func dropOrder(files []git.FileDiff) []int {
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(x, y int) int {
+		if px, py := classifyFile(files[x].Path), classifyFile(files[y].Path); px != py {
+			return int(px - py)
+		}
+		return len(files[y].Text) - len(files[x].Text)
+	})
+	return order
+}
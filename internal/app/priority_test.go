@@ -0,0 +1,92 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected filePriority
+	}{
+		{path: "internal/app/app.go", expected: prioritySource},
+		{path: "README.md", expected: prioritySource},
+		{path: "go.sum", expected: priorityGenerated},
+		{path: "web/package-lock.json", expected: priorityGenerated},
+		{path: "api/v1/service.pb.go", expected: priorityGenerated},
+		{path: "vendor/github.com/pkg/errors/errors.go", expected: priorityVendored},
+		{path: "web/node_modules/left-pad/index.js", expected: priorityVendored},
+		{path: "third_party/zlib/zlib.h", expected: priorityVendored},
+		{path: "internal/vendors/client.go", expected: prioritySource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := classifyFile(tt.path); got != tt.expected {
+				t.Errorf("classifyFile(%q) = %d, expected %d", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateDiff_Priority(t *testing.T) {
+	tests := []struct {
+		name    string
+		diff    string
+		kept    []string
+		omitted string
+	}{
+		{
+			name:    "Lockfile dropped before a larger Go file",
+			diff:    fileDiff("main.go", 50) + fileDiff("go.sum", 20),
+			kept:    []string{"main.go"},
+			omitted: "go.sum +20 -0",
+		},
+		{
+			name:    "Vendored code dropped before generated code",
+			diff:    fileDiff("vendor/lib/lib.go", 10) + fileDiff("package-lock.json", 20) + fileDiff("app.go", 10),
+			kept:    []string{"package-lock.json", "app.go"},
+			omitted: "vendor/lib/lib.go +10 -0",
+		},
+		{
+			name:    "Largest source file dropped first",
+			diff:    fileDiff("a.go", 10) + fileDiff("b.go", 50) + fileDiff("c.go", 30),
+			kept:    []string{"a.go", "c.go"},
+			omitted: "b.go +50 -0",
+		},
+		{
+			name:    "Order of the kept files is unchanged",
+			diff:    fileDiff("z.go", 2) + fileDiff("yarn.lock", 80) + fileDiff("a.go", 2),
+			kept:    []string{"z.go", "a.go"},
+			omitted: "yarn.lock +80 -0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One byte over the limit, so one file has to go
+			got := truncateDiff(tt.diff, len(tt.diff)-1)
+			if paths := changedPaths(got); strings.Join(paths, ",") != strings.Join(tt.kept, ",") {
+				t.Errorf("expected files %v to be kept, got %v", tt.kept, paths)
+			}
+			if !strings.HasSuffix(got, "\n...[OMITTED: "+tt.omitted+"]") {
+				t.Errorf("expected %q to be listed as omitted, got %q", tt.omitted, got)
+			}
+			if strings.Contains(got, "[TRUNCATED]") {
+				t.Errorf("expected whole files to be left out, not cut, got %q", got)
+			}
+		})
+	}
+}
+
+func TestTruncateDiff_CutsLastFile(t *testing.T) {
+	diff := fileDiff("main.go", 100) + fileDiff("go.sum", 20)
+	got := truncateDiff(diff, 100)
+	if !strings.HasPrefix(got, "diff --git a/main.go b/main.go\n") {
+		t.Errorf("expected main.go to be kept, got %q", got)
+	}
+	if !strings.HasSuffix(got, "\n...[TRUNCATED]\n...[OMITTED: go.sum +20 -0]") {
+		t.Errorf("expected main.go to be cut and go.sum omitted, got %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/git"
+)
+
+// buildPrompt returns the prompt the model is sent for diff and rules
+func (a *App) buildPrompt(diff, rules string, opts RunOptions) string {
+	if a.includeBody(opts) {
+		return a.AI.BuildBodyPrompt(diff, rules)
+	}
+	return a.AI.BuildPrompt(diff, rules)
+}
+
+// estimateTokens returns the approximate token count of text
+func (a *App) estimateTokens(text string) int {
+	if a.EstimateTokens == nil {
+		return ai.EstimateTokens(text)
+	}
+	return a.EstimateTokens(text)
+}
+
+// fitPrompt leaves files out of diff, in dropOrder, until the prompt fits
+// max_prompt_tokens. The omitted files are listed at the end of the diff,
+// so the model still knows they changed. It fails if the prompt is too
+// large even with a single file left.
+func (a *App) fitPrompt(diff, rules string, opts RunOptions) (string, error) {
+	if a.Config == nil || a.Config.MaxPromptTokens <= 0 {
+		return diff, nil
+	}
+	limit := a.Config.MaxPromptTokens
+	tokens := a.estimateTokens(a.buildPrompt(diff, rules, opts))
+	if tokens <= limit {
+		return diff, nil
+	}
+
+	kept, note, omitted, ok := trimFiles(diff, func(candidate string) bool {
+		return a.estimateTokens(a.buildPrompt(candidate, rules, opts)) <= limit
+	})
+	if ok {
+		fmt.Fprintf(a.status(), "Warning: the prompt is about %d tokens, over max_prompt_tokens (%d); left %s out of the diff\n", tokens, limit, strings.Join(omitted, ", "))
+		return kept + note, nil
+	}
+	return "", fmt.Errorf("the prompt is about %d tokens, over max_prompt_tokens (%d), even when leaving files out; stage fewer changes, add exclude_paths, lower max_diff_bytes, or raise max_prompt_tokens", tokens, limit)
+}
+
+// trimFiles leaves files out of diff in dropOrder until fits accepts the
+// kept files followed by the note listing the omitted ones and their line
+// counts, which the prompt's diff stats no longer cover. At least one file
+// is kept; if the result never fits, ok is false and kept has a single
+// file.
+func trimFiles(diff string, fits func(diff string) bool) (kept, note string, omitted []string, ok bool) {
+	files := git.SplitDiff(diff)
+	dropped := make([]bool, len(files))
+	var counts []string
+	kept = diff
+	for _, i := range dropOrder(files)[:max(len(files)-1, 0)] {
+		dropped[i] = true
+		omitted = append(omitted, files[i].Path)
+		counts = append(counts, lineCounts(files[i]))
+
+		var keptFiles []git.FileDiff
+		for j, file := range files {
+			if !dropped[j] {
+				keptFiles = append(keptFiles, file)
+			}
+		}
+		kept, note = git.JoinDiff(keptFiles), omittedFilesNote(counts)
+		if fits(kept + note) {
+			return kept, note, omitted, true
+		}
+	}
+	return kept, note, omitted, false
+}
+
+// lineCounts describes a file and the lines it changed, e.g. "go.sum +12 -3"
+func lineCounts(file git.FileDiff) string {
+	stat := git.DiffStats(file.Text)[0]
+	if stat.Binary {
+		return file.Path + " binary"
+	}
+	return fmt.Sprintf("%s +%d -%d", file.Path, stat.Insertions, stat.Deletions)
+}
+
+// omittedFilesNote lists the files left out of the diff
+func omittedFilesNote(files []string) string {
+	return "\n...[OMITTED: " + strings.Join(files, ", ") + "]"
+}
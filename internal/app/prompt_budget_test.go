@@ -0,0 +1,105 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+// budgetDiff has a small, a large and a medium file, in that order
+var budgetDiff = "diff --git a/small.go b/small.go\n@@ -0,0 +1 @@\n+" + strings.Repeat("s", 40) + "\n" +
+	"diff --git a/large.go b/large.go\n@@ -0,0 +1 @@\n+" + strings.Repeat("l", 400) + "\n" +
+	"diff --git a/medium.go b/medium.go\n@@ -0,0 +1 @@\n+" + strings.Repeat("m", 200) + "\n"
+
+func TestApp_FitPrompt(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxTokens   int
+		expected    []string
+		omitted     []string
+		expectedErr string
+	}{
+		{name: "Unlimited", expected: []string{"small.go", "large.go", "medium.go"}},
+		{name: "Fits", maxTokens: 1000, expected: []string{"small.go", "large.go", "medium.go"}},
+		{name: "Largest dropped first", maxTokens: 150, expected: []string{"small.go", "medium.go"}, omitted: []string{"large.go +1 -0"}},
+		{name: "Drops until it fits", maxTokens: 60, expected: []string{"small.go"}, omitted: []string{"large.go +1 -0", "medium.go +1 -0"}},
+		{name: "Nothing fits", maxTokens: 5, expectedErr: "over max_prompt_tokens (5)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp(&MockGit{}, &MockConfig{}, nil, &MockAI{
+				BuildPromptFunc: func(diff, rules string) string { return rules + diff },
+			})
+			app.Config = &config.Config{MaxPromptTokens: tt.maxTokens}
+			var status bytes.Buffer
+			app.Status = &status
+
+			got, err := app.fitPrompt(budgetDiff, "- Use the imperative mood", RunOptions{})
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			paths := changedPaths(got)
+			if strings.Join(paths, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected files %v to be kept, got %v", tt.expected, paths)
+			}
+			if len(tt.omitted) == 0 {
+				if got != budgetDiff || status.Len() != 0 {
+					t.Errorf("expected the diff unchanged and no warning, got %q and %q", got, status.String())
+				}
+				return
+			}
+			note := "[OMITTED: " + strings.Join(tt.omitted, ", ") + "]"
+			if !strings.Contains(got, note) {
+				t.Errorf("expected the diff to end with %q, got %q", note, got)
+			}
+			if !strings.Contains(status.String(), "Warning: the prompt is about") {
+				t.Errorf("expected a warning, got %q", status.String())
+			}
+		})
+	}
+}
+
+func TestApp_Run_PromptTokens(t *testing.T) {
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return budgetDiff, nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		BuildPromptFunc: func(diff, rules string) string { return diff },
+	})
+	// A custom estimator counts bytes instead of characters
+	app.EstimateTokens = func(text string) int { return len(text) }
+
+	result, err := app.Run(context.Background(), RunOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.PromptTokens != len(result.Prompt) {
+		t.Errorf("expected %d prompt tokens, got %d", len(result.Prompt), result.PromptTokens)
+	}
+
+	// The model is never called with a prompt over the limit
+	app.Config = &config.Config{MaxDiffBytes: config.DefaultMaxDiffBytes, MaxPromptTokens: 10}
+	app.Status = &bytes.Buffer{}
+	app.AI.(*MockAI).GenerateCommitMessageFunc = func(diff, rules string) (*ai.GenerateResult, error) {
+		t.Error("expected the model not to be called")
+		return message("feat: add files"), nil
+	}
+	if _, err := app.Run(context.Background(), RunOptions{}); err == nil || !strings.Contains(err.Error(), "max_prompt_tokens") {
+		t.Errorf("expected a max_prompt_tokens error, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// defaultBulkRenameThreshold is how many files must move between the same
+// two directories before their renames are summarized
+const defaultBulkRenameThreshold = 3
+
+// directoryMove is the pair of directories a rename moved a file between
+type directoryMove struct {
+	from, to string
+}
+
+// summarizeRenames collapses pure renames that move at least threshold
+// files between the same two directories into a single "Renamed N files
+// from A/ to B/" line, placed where the first of them was. Other renames
+// and all content changes are kept as they are. A threshold below 1
+// leaves the diff unchanged.
+func summarizeRenames(diff string, threshold int) string {
+	if threshold < 1 {
+		return diff
+	}
+
+	files := git.SplitDiff(diff)
+	moves := make([]directoryMove, len(files))
+	counts := map[directoryMove]int{}
+	for i, file := range files {
+		if move, ok := renameMove(file.Text); ok {
+			moves[i] = move
+			counts[move]++
+		}
+	}
+
+	bulk := false
+	for move, count := range counts {
+		bulk = bulk || count >= threshold && move != (directoryMove{})
+	}
+	if !bulk {
+		return diff
+	}
+
+	var sb strings.Builder
+	summarized := map[directoryMove]bool{}
+	for i, file := range files {
+		move := moves[i]
+		if counts[move] < threshold || move == (directoryMove{}) {
+			sb.WriteString(file.Text)
+			continue
+		}
+		if !summarized[move] {
+			fmt.Fprintf(&sb, "Renamed %d %s from %s to %s\n", counts[move], plural(counts[move], "file", "files"), dirLabel(move.from), dirLabel(move.to))
+			summarized[move] = true
+		}
+	}
+	return sb.String()
+}
+
+// renameMove returns the directory move of a diff section that is a pure
+// rename (no content changes) keeping the file's name. The directories are
+// what remains of the old and new paths after their common trailing path
+// components are removed.
+func renameMove(section string) (directoryMove, bool) {
+	var from, to string
+	for _, line := range strings.Split(section, "\n") {
+		switch {
+		case strings.HasPrefix(line, "rename from "):
+			from = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			to = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "@@"):
+			return directoryMove{}, false
+		}
+	}
+	if from == "" || to == "" || path.Base(from) != path.Base(to) {
+		return directoryMove{}, false
+	}
+
+	fromParts := strings.Split(from, "/")
+	toParts := strings.Split(to, "/")
+	for len(fromParts) > 0 && len(toParts) > 0 && fromParts[len(fromParts)-1] == toParts[len(toParts)-1] {
+		fromParts = fromParts[:len(fromParts)-1]
+		toParts = toParts[:len(toParts)-1]
+	}
+	return directoryMove{from: strings.Join(fromParts, "/"), to: strings.Join(toParts, "/")}, true
+}
+
+// dirLabel formats a directory for a rename summary, with "./" for the
+// repository root
+func dirLabel(dir string) string {
+	if dir == "" {
+		return "./"
+	}
+	return dir + "/"
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+// renameDiff builds a pure rename diff section
+func renameDiff(from, to string) string {
+	return "diff --git a/" + from + " b/" + to + "\nrename from " + from + "\nrename to " + to + "\n"
+}
+
+func TestSummarizeRenames(t *testing.T) {
+	modified := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	tests := []struct {
+		name      string
+		diff      string
+		threshold int
+		expected  string
+	}{
+		{
+			name: "Bulk package move",
+			diff: modified +
+				renameDiff("pkg/util/a.go", "internal/util/a.go") +
+				renameDiff("pkg/util/b.go", "internal/util/b.go") +
+				renameDiff("pkg/util/sub/c.go", "internal/util/sub/c.go"),
+			threshold: 3,
+			expected:  modified + "Renamed 3 files from pkg/ to internal/\n",
+		},
+		{
+			name: "Mixed renames",
+			diff: renameDiff("old/a.go", "new/a.go") +
+				renameDiff("README", "docs/README") +
+				renameDiff("old/b.go", "new/b.go") +
+				renameDiff("util.go", "helpers.go") +
+				renameDiff("old/c.go", "new/c.go"),
+			threshold: 3,
+			expected: "Renamed 3 files from old/ to new/\n" +
+				renameDiff("README", "docs/README") +
+				renameDiff("util.go", "helpers.go"),
+		},
+		{
+			name: "Below threshold",
+			diff: renameDiff("old/a.go", "new/a.go") +
+				renameDiff("old/b.go", "new/b.go"),
+			threshold: 3,
+			expected: renameDiff("old/a.go", "new/a.go") +
+				renameDiff("old/b.go", "new/b.go"),
+		},
+		{
+			name: "Move out of the root",
+			diff: renameDiff("a.go", "src/a.go") +
+				renameDiff("b.go", "src/b.go"),
+			threshold: 2,
+			expected:  "Renamed 2 files from ./ to src/\n",
+		},
+		{
+			name: "Disabled",
+			diff: renameDiff("old/a.go", "new/a.go") +
+				renameDiff("old/b.go", "new/b.go"),
+			threshold: -1,
+			expected: renameDiff("old/a.go", "new/a.go") +
+				renameDiff("old/b.go", "new/b.go"),
+		},
+		{
+			name: "Renames with content changes are kept",
+			diff: renameDiff("old/a.go", "new/a.go") + "@@ -1 +1 @@\n-a\n+b\n" +
+				renameDiff("old/b.go", "new/b.go"),
+			threshold: 1,
+			expected: renameDiff("old/a.go", "new/a.go") + "@@ -1 +1 @@\n-a\n+b\n" +
+				"Renamed 1 file from old/ to new/\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeRenames(tt.diff, tt.threshold); got != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApp_Run_SummarizesBulkRenames(t *testing.T) {
+	var diff strings.Builder
+	for _, name := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		diff.WriteString(renameDiff("pkg/"+name, "internal/"+name))
+	}
+
+	tests := []struct {
+		name      string
+		threshold int
+		expected  string
+	}{
+		{name: "Default threshold", threshold: 0, expected: "Renamed 4 files from pkg/ to internal/\n"},
+		{name: "Higher threshold", threshold: 5, expected: diff.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentDiff string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return diff.String(), nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sentDiff = diff
+					return message("refactor: move packages to internal"), nil
+				},
+			})
+			app.Config = &config.Config{BulkRenameThreshold: tt.threshold}
+
+			if _, err := app.Run(context.Background(), RunOptions{}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if sentDiff != tt.expected {
+				t.Errorf("expected diff %q, got %q", tt.expected, sentDiff)
+			}
+		})
+	}
+}
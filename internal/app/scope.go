@@ -0,0 +1,90 @@
+package app
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// maxScopeHints caps the candidate scopes passed to the model, so a diff
+// touching many directories doesn't drown the prompt
+const maxScopeHints = 5
+
+// changedPaths returns the paths of the files changed in diff
+func changedPaths(diff string) []string {
+	var paths []string
+	for _, file := range git.SplitDiff(diff) {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+// changedTopLevelDirs returns the top-level directories containing the
+// given paths, most changed first. Files in the repository root have none.
+func changedTopLevelDirs(paths []string) []string {
+	return rankByCount(paths, func(p string) string {
+		dir, _, found := strings.Cut(p, "/")
+		if !found {
+			return ""
+		}
+		return dir
+	})
+}
+
+// changedPackages returns the names of the directories directly containing
+// the given paths (the Go package, for Go files), most changed first
+func changedPackages(paths []string) []string {
+	return rankByCount(paths, func(p string) string {
+		dir := path.Dir(p)
+		if dir == "." {
+			return ""
+		}
+		return path.Base(dir)
+	})
+}
+
+// rankByCount maps each path to a name and returns the distinct non-empty
+// names, most frequent first and then alphabetically
+func rankByCount(paths []string, name func(string) string) []string {
+	counts := make(map[string]int)
+	var names []string
+	for _, p := range paths {
+		n := name(p)
+		if n == "" {
+			continue
+		}
+		if counts[n] == 0 {
+			names = append(names, n)
+		}
+		counts[n]++
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// suggestedScopes returns candidate conventional commit scopes for the
+// changed paths: the containing packages, then the top-level directories
+func suggestedScopes(paths []string) []string {
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, scope := range append(changedPackages(paths), changedTopLevelDirs(paths)...) {
+		if seen[scope] || len(scopes) == maxScopeHints {
+			continue
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
+
+// scopeRule builds the prompt rule suggesting scopes
+func scopeRule(scopes []string) string {
+	return "Candidate scopes, from the changed paths: " + strings.Join(scopes, ", ") + ". Use the one that fits the change best, if any."
+}
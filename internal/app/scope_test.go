@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+func TestSuggestedScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{name: "Nested package", paths: []string{"internal/ai/foo.go"}, expected: []string{"ai", "internal"}},
+		{name: "Top-level package", paths: []string{"docs/usage.md"}, expected: []string{"docs"}},
+		{name: "Root files only", paths: []string{"README.md", "go.mod"}, expected: nil},
+		{
+			name:     "Most changed first",
+			paths:    []string{"internal/git/client.go", "internal/app/app.go", "internal/git/diff.go", "cmd/generate-commit/main.go"},
+			expected: []string{"git", "app", "generate-commit", "internal", "cmd"},
+		},
+		{
+			name:     "Capped",
+			paths:    []string{"a/x.go", "b/x.go", "c/x.go", "d/x.go", "e/x.go", "f/x.go"},
+			expected: []string{"a", "b", "c", "d", "e"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestedScopes(tt.paths); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestChangedTopLevelDirs(t *testing.T) {
+	paths := []string{"internal/ai/foo.go", "internal/app/app.go", "cmd/generate-commit/main.go", "README.md"}
+	expected := []string{"internal", "cmd"}
+	if got := changedTopLevelDirs(paths); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestApp_Run_ScopeHints(t *testing.T) {
+	var sentRules string
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc: func() (string, error) {
+			return "diff --git a/internal/ai/foo.go b/internal/ai/foo.go\n--- a/internal/ai/foo.go\n+++ b/internal/ai/foo.go\n@@ -1 +1 @@\n-old\n+new\n", nil
+		},
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			sentRules = rules
+			return message("fix(ai): handle empty response"), nil
+		},
+	})
+
+	if _, err := app.Run(context.Background(), RunOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(sentRules, "Candidate scopes, from the changed paths: ai, internal.") {
+		t.Errorf("expected scope hints in rules, got %q", sentRules)
+	}
+}
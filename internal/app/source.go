@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// Diff sources accepted by RunOptions.Source. Sources that take an
+// argument are written as "name:argument", e.g. "file:changes.patch".
+const (
+	// SourceStaged describes the staged changes (the default)
+	SourceStaged = "staged"
+	// SourceAll describes all tracked changes, staged or not, against HEAD
+	SourceAll = "all"
+	// SourceStdin reads a unified diff from standard input
+	SourceStdin = "stdin"
+	// SourceFile reads a unified diff from a file: "file:PATH"
+	SourceFile = "file"
+	// SourceBase describes the commits since the branch forked from a
+	// base branch: "base:REF"
+	SourceBase = "base"
+	// SourceStash describes the most recent stash entry
+	SourceStash = "stash"
+	// SourceRange describes the changes between two revisions:
+	// "range:FROM..TO". TO defaults to HEAD.
+	SourceRange = "range"
+)
+
+// DiffProvider supplies the raw diff that a run describes
+type DiffProvider interface {
+	Diff() (string, error)
+}
+
+// stagedProvider reads the staged changes
+type stagedProvider struct {
+	git git.Client
+}
+
+func (p stagedProvider) Diff() (string, error) {
+	return p.git.GetStagedDiff()
+}
+
+// worktreeProvider reads all tracked changes against HEAD
+type worktreeProvider struct {
+	git git.Client
+}
+
+func (p worktreeProvider) Diff() (string, error) {
+	return p.git.GetWorktreeDiff()
+}
+
+// readerProvider reads a diff from r
+type readerProvider struct {
+	r io.Reader
+}
+
+func (p readerProvider) Diff() (string, error) {
+	data, err := io.ReadAll(p.r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read diff: %w", err)
+	}
+	return string(data), nil
+}
+
+// fileProvider reads a diff from a file
+type fileProvider struct {
+	path string
+}
+
+func (p fileProvider) Diff() (string, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read diff file: %w", err)
+	}
+	return string(data), nil
+}
+
+// revisionProvider reads the changes between two revisions
+type revisionProvider struct {
+	git      git.Client
+	from, to string
+}
+
+func (p revisionProvider) Diff() (string, error) {
+	return p.git.GetRevisionDiff(p.from, p.to)
+}
+
+// baseProvider reads the changes made since HEAD forked from base
+type baseProvider struct {
+	git  git.Client
+	base string
+}
+
+func (p baseProvider) Diff() (string, error) {
+	mergeBase, err := p.git.GetMergeBase(p.base, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return p.git.GetRevisionDiff(mergeBase, "HEAD")
+}
+
+// diffProvider returns the provider for a RunOptions.Source value
+func (a *App) diffProvider(source string) (DiffProvider, error) {
+	name, arg, hasArg := strings.Cut(source, ":")
+
+	switch name {
+	case "", SourceStaged, SourceAll, SourceStdin, SourceStash:
+		if hasArg {
+			return nil, fmt.Errorf("source %q takes no argument", name)
+		}
+	}
+
+	switch name {
+	case "", SourceStaged:
+		return stagedProvider{git: a.Git}, nil
+	case SourceAll:
+		return worktreeProvider{git: a.Git}, nil
+	case SourceStdin:
+		input := a.Input
+		if input == nil {
+			input = os.Stdin
+		}
+		return readerProvider{r: input}, nil
+	case SourceStash:
+		return revisionProvider{git: a.Git, from: "stash^1", to: "stash"}, nil
+	case SourceFile:
+		if arg == "" {
+			return nil, fmt.Errorf("source %q needs a path, e.g. file:changes.patch", source)
+		}
+		return fileProvider{path: arg}, nil
+	case SourceBase:
+		if arg == "" {
+			return nil, fmt.Errorf("source %q needs a branch, e.g. base:main", source)
+		}
+		return baseProvider{git: a.Git, base: arg}, nil
+	case SourceRange:
+		from, to, ok := strings.Cut(arg, "..")
+		if !ok || from == "" {
+			return nil, fmt.Errorf("source %q needs a range, e.g. range:v1.0..v1.1", source)
+		}
+		if to == "" {
+			to = "HEAD"
+		}
+		return revisionProvider{git: a.Git, from: from, to: to}, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q (expected staged, all, stdin, file:PATH, base:REF, stash, or range:FROM..TO)", source)
+	}
+}
+
+// isStagedSource reports whether source describes the staged changes,
+// which are the only changes a run can commit
+func isStagedSource(source string) bool {
+	return source == "" || source == SourceStaged
+}
+
+// isPatchSource reports whether source reads a ready-made patch instead of
+// asking git for the diff
+func isPatchSource(source string) bool {
+	name, _, _ := strings.Cut(source, ":")
+	return name == SourceStdin || name == SourceFile
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+func TestApp_DiffProvider(t *testing.T) {
+	patchPath := filepath.Join(t.TempDir(), "changes.patch")
+	if err := os.WriteFile(patchPath, []byte("file diff"), 0644); err != nil {
+		t.Fatalf("failed to write patch: %v", err)
+	}
+
+	tests := []struct {
+		source      string
+		expected    string
+		expectedErr string
+	}{
+		{source: "", expected: "staged diff"},
+		{source: "staged", expected: "staged diff"},
+		{source: "all", expected: "worktree diff"},
+		{source: "stdin", expected: "stdin diff"},
+		{source: "file:" + patchPath, expected: "file diff"},
+		{source: "base:main", expected: "revision diff abc123..HEAD"},
+		{source: "stash", expected: "revision diff stash^1..stash"},
+		{source: "range:v1.0..v1.1", expected: "revision diff v1.0..v1.1"},
+		{source: "range:v1.0..", expected: "revision diff v1.0..HEAD"},
+		{source: "file:", expectedErr: "needs a path"},
+		{source: "base", expectedErr: "needs a branch"},
+		{source: "range:v1.0", expectedErr: "needs a range"},
+		{source: "stash:1", expectedErr: "takes no argument"},
+		{source: "index", expectedErr: "unknown source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			app := NewApp(&MockGit{
+				GetStagedDiffFunc:   func() (string, error) { return "staged diff", nil },
+				GetWorktreeDiffFunc: func() (string, error) { return "worktree diff", nil },
+				GetRevisionDiffFunc: func(from, to string) (string, error) {
+					return "revision diff " + from + ".." + to, nil
+				},
+				GetMergeBaseFunc: func(rev1, rev2 string) (string, error) {
+					if rev1 != "main" || rev2 != "HEAD" {
+						t.Errorf("unexpected merge base of %s and %s", rev1, rev2)
+					}
+					return "abc123", nil
+				},
+			}, nil, nil, nil)
+			app.Input = strings.NewReader("stdin diff")
+
+			provider, err := app.diffProvider(tt.source)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			diff, err := provider.Diff()
+			if err != nil {
+				t.Fatalf("unexpected error reading diff: %v", err)
+			}
+			if diff != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, diff)
+			}
+		})
+	}
+}
+
+func TestApp_Run_Source(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        RunOptions
+		diff        string
+		expectedErr string
+	}{
+		{name: "Range", opts: RunOptions{Source: "range:v1.0..v1.1"}, diff: "range diff"},
+		{name: "Empty range", opts: RunOptions{Source: "range:v1.0..v1.1"}, expectedErr: "no changes found"},
+		{name: "Commit", opts: RunOptions{Source: "range:v1.0..v1.1", Commit: true}, diff: "range diff", expectedErr: "only staged changes can be committed"},
+		{name: "Interactive", opts: RunOptions{Source: "all", Interactive: true}, diff: "range diff", expectedErr: "only staged changes can be committed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc: func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) {
+					t.Error("staged changes should not be checked for other sources")
+					return false, nil
+				},
+				GetRevisionDiffFunc: func(from, to string) (string, error) { return tt.diff, nil },
+				GetWorktreeDiffFunc: func() (string, error) { return tt.diff, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sent = diff
+					return message("feat: add release notes"), nil
+				},
+			})
+
+			result, err := app.Run(context.Background(), tt.opts)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sent != tt.diff {
+				t.Errorf("expected diff %q to be sent, got %q", tt.diff, sent)
+			}
+			if result.Message != "feat: add release notes" {
+				t.Errorf("unexpected message %q", result.Message)
+			}
+		})
+	}
+}
+
+func TestApp_Run_PatchSource(t *testing.T) {
+	patchPath := filepath.Join(t.TempDir(), "ci.patch")
+	if err := os.WriteFile(patchPath, []byte("patch from file"), 0644); err != nil {
+		t.Fatalf("failed to write patch: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		input    string
+		expected string
+	}{
+		{name: "Stdin", source: "stdin", input: "patch from stdin", expected: "patch from stdin"},
+		{name: "File", source: "file:" + patchPath, expected: "patch from file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent string
+			// Patch sources must not touch the repository
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc: func() (bool, error) {
+					t.Error("repository should not be checked for patch sources")
+					return false, nil
+				},
+				HasStagedChangesFunc: func() (bool, error) {
+					t.Error("staged changes should not be checked for patch sources")
+					return false, nil
+				},
+				GetStagedDiffFunc: func() (string, error) {
+					t.Error("staged diff should not be read for patch sources")
+					return "", nil
+				},
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					sent = diff
+					return message("ci: describe patch"), nil
+				},
+			})
+			app.Input = strings.NewReader(tt.input)
+
+			result, err := app.Run(context.Background(), RunOptions{Source: tt.source})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sent != tt.expected {
+				t.Errorf("expected %q to be sent, got %q", tt.expected, sent)
+			}
+			if result.Message != "ci: describe patch" {
+				t.Errorf("unexpected message %q", result.Message)
+			}
+		})
+	}
+}
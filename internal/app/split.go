@@ -0,0 +1,253 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/git"
+)
+
+// RunSplitSession asks the AI to split the staged changes into logical
+// groups, then walks through each group: it stages only that group's
+// files, generates a message for them, and commits on confirmation.
+// Skipped groups and groups left after quitting are restaged at the end,
+// so no staged change is lost. Files matching git.IgnoreFile are kept out
+// of every group and restaged at the end too.
+//
+// Files are restaged from the working tree, so the session refuses to
+// start while a staged file also has unstaged changes (`git add -p`).
+// Cancelling ctx aborts any request to the AI in progress.
+func (a *App) RunSplitSession(ctx context.Context) error {
+	isRepo, err := a.Git.IsInsideRepo()
+	if err != nil {
+		return fmt.Errorf("failed to check repository status: %w", err)
+	}
+	if !isRepo {
+		return errors.New("not a git repository")
+	}
+
+	inProgress, err := a.Git.IsMidMergeOrRebase()
+	if err != nil {
+		return fmt.Errorf("failed to check for a merge or rebase: %w", err)
+	}
+	if inProgress {
+		return ErrMidMergeOrRebase
+	}
+
+	stagedPaths, err := a.Git.GetStagedPaths()
+	if err != nil {
+		return fmt.Errorf("failed to list staged files: %w", err)
+	}
+	var staged, ignored, partial []string
+	for _, path := range stagedPaths {
+		switch {
+		case path.Unstaged:
+			partial = append(partial, path.Path)
+		case path.Ignored:
+			ignored = append(ignored, path.Path)
+		default:
+			staged = append(staged, path.Path)
+		}
+	}
+	if len(partial) > 0 {
+		return fmt.Errorf("%s also changed since being staged; split restages files from the working tree, so stage or stash those changes first", strings.Join(partial, ", "))
+	}
+	if len(staged) == 0 {
+		if len(ignored) > 0 {
+			return git.ErrOnlyIgnored
+		}
+		return errors.New("no staged changes found. Please stage your changes using 'git add'")
+	}
+
+	commitOpts, err := a.commitOptions(RunOptions{})
+	if err != nil {
+		return err
+	}
+
+	rules, err := a.RulesLoader.LoadRules()
+	if err != nil {
+		fmt.Fprintf(a.status(), "Warning: failed to load rules: %v. Proceeding without rules.\n", err)
+	}
+
+	diff, err := a.getDiff(stagedProvider{git: a.Git}, RunOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	fmt.Fprintln(a.status(), "Splitting staged changes...")
+
+	groups, err := a.AI.SplitChanges(ctx, diff, rules)
+	if err != nil {
+		return fmt.Errorf("failed to split changes: %w", err)
+	}
+	groups = assignFiles(groups, staged)
+
+	// Start from an empty index and stage one group at a time. Ignored
+	// files are unstaged too, so they aren't committed with a group.
+	if err := a.Git.UnstageFiles(append(staged, ignored...)); err != nil {
+		return fmt.Errorf("failed to unstage files: %w", err)
+	}
+
+	pending := ignored
+	defer func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := a.Git.StageFiles(pending); err != nil {
+			fmt.Fprintf(a.status(), "Warning: failed to restage files: %v\n", err)
+		}
+	}()
+
+	for i, group := range groups {
+		if err := a.Git.StageFiles(group.Files); err != nil {
+			pending = append(pending, remainingFiles(groups[i:])...)
+			return fmt.Errorf("failed to stage group %d: %w", i+1, err)
+		}
+
+		result, err := a.generateGroupMessage(ctx, rules)
+		if err != nil {
+			pending = append(pending, remainingFiles(groups[i:])...)
+			return err
+		}
+
+		fmt.Printf("\nGroup %d/%d: %s\n", i+1, len(groups), group.Description)
+		for _, path := range group.Files {
+			fmt.Printf("  %s\n", path)
+		}
+		fmt.Println("\n" + a.colorize(ColorCyan, result.Message))
+
+		choice, err := a.groupChoice(!result.IsSplitSuggestion)
+		if err != nil {
+			pending = append(pending, remainingFiles(groups[i:])...)
+			return err
+		}
+
+		switch choice {
+		case "c":
+			if err := a.Git.CommitWithMessage(result.Message, commitOpts); err != nil {
+				pending = append(pending, remainingFiles(groups[i:])...)
+				return fmt.Errorf("failed to commit group %d: %w", i+1, err)
+			}
+			fmt.Printf("✓ Committed group %d\n", i+1)
+		case "s":
+			if err := a.Git.UnstageFiles(group.Files); err != nil {
+				return fmt.Errorf("failed to unstage group %d: %w", i+1, err)
+			}
+			pending = append(pending, group.Files...)
+		case "q":
+			if err := a.Git.UnstageFiles(group.Files); err != nil {
+				return fmt.Errorf("failed to unstage group %d: %w", i+1, err)
+			}
+			pending = append(pending, remainingFiles(groups[i:])...)
+			fmt.Println("Split session aborted")
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// groupChoice asks what to do with a group until the user answers "c"
+// (only offered when canCommit), "s" or "q", and returns the answer
+func (a *App) groupChoice(canCommit bool) (string, error) {
+	question := "[C]ommit, [S]kip, [Q]uit: "
+	if !canCommit {
+		question = "[S]kip, [Q]uit: "
+	}
+	for {
+		choice, err := a.prompt(question)
+		if err != nil {
+			return "", err
+		}
+		choice = strings.ToLower(choice)
+		if choice == "s" || choice == "q" || (choice == "c" && canCommit) {
+			return choice, nil
+		}
+		fmt.Printf("Invalid choice %q\n", choice)
+	}
+}
+
+// generateGroupMessage generates a message for the currently staged group,
+// with the same rules, checks and post-processing as Run. A split
+// suggestion can't be committed, so the group can only be skipped.
+func (a *App) generateGroupMessage(ctx context.Context, rules string) (*RunResult, error) {
+	opts := RunOptions{}
+	diff, err := a.getDiff(stagedProvider{git: a.Git}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+	rules, _ = a.diffRules(diff, rules, opts)
+	diff, err = a.fitPrompt(diff, rules, opts)
+	if err != nil {
+		return nil, err
+	}
+	return a.generate(ctx, diff, rules, opts)
+}
+
+// prompt prints question and reads a trimmed line from the app's input
+func (a *App) prompt(question string) (string, error) {
+	if a.reader == nil {
+		input := a.Input
+		if input == nil {
+			input = os.Stdin
+		}
+		a.reader = bufio.NewReader(input)
+	}
+
+	fmt.Print(question)
+	line, err := a.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// assignFiles reconciles the AI's groups with the actually staged files:
+// unknown and duplicate paths are dropped, empty groups are removed, and
+// any staged file the AI left out is collected into a final group
+func assignFiles(groups []ai.ChangeGroup, staged []string) []ai.ChangeGroup {
+	unassigned := make(map[string]bool, len(staged))
+	for _, path := range staged {
+		unassigned[path] = true
+	}
+
+	var result []ai.ChangeGroup
+	for _, group := range groups {
+		var files []string
+		for _, path := range group.Files {
+			if unassigned[path] {
+				files = append(files, path)
+				delete(unassigned, path)
+			}
+		}
+		if len(files) > 0 {
+			result = append(result, ai.ChangeGroup{Description: group.Description, Files: files})
+		}
+	}
+
+	var leftover []string
+	for _, path := range staged {
+		if unassigned[path] {
+			leftover = append(leftover, path)
+		}
+	}
+	if len(leftover) > 0 {
+		result = append(result, ai.ChangeGroup{Description: "Remaining changes", Files: leftover})
+	}
+	return result
+}
+
+// remainingFiles flattens the files of the given groups
+func remainingFiles(groups []ai.ChangeGroup) []string {
+	var files []string
+	for _, group := range groups {
+		files = append(files, group.Files...)
+	}
+	return files
+}
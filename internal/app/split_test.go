@@ -0,0 +1,305 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+	"ai-commit-message-generator/internal/git"
+)
+
+// fakeIndex simulates the staging area for split session tests
+type fakeIndex struct {
+	staged map[string]bool
+	// ignored and unstaged flag paths as GetStagedPaths would
+	ignored  map[string]bool
+	unstaged map[string]bool
+	commits  []string
+	// committedFiles records the files staged at each commit
+	committedFiles [][]string
+}
+
+func newFakeIndex(paths ...string) *fakeIndex {
+	idx := &fakeIndex{staged: map[string]bool{}, ignored: map[string]bool{}, unstaged: map[string]bool{}}
+	for _, p := range paths {
+		idx.staged[p] = true
+	}
+	return idx
+}
+
+func (f *fakeIndex) paths() []string {
+	var paths []string
+	for p := range f.staged {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (f *fakeIndex) mockGit() *MockGit {
+	return &MockGit{
+		IsInsideRepoFunc: func() (bool, error) { return true, nil },
+		GetStagedPathsFunc: func() ([]git.StagedPath, error) {
+			var paths []git.StagedPath
+			for _, p := range f.paths() {
+				paths = append(paths, git.StagedPath{Path: p, Ignored: f.ignored[p], Unstaged: f.unstaged[p]})
+			}
+			return paths, nil
+		},
+		GetStagedDiffFunc: func() (string, error) {
+			var paths []string
+			for _, p := range f.paths() {
+				if !f.ignored[p] {
+					paths = append(paths, p)
+				}
+			}
+			return "diff of " + strings.Join(paths, ","), nil
+		},
+		StageFilesFunc: func(paths []string) error {
+			for _, p := range paths {
+				f.staged[p] = true
+			}
+			return nil
+		},
+		UnstageFilesFunc: func(paths []string) error {
+			for _, p := range paths {
+				delete(f.staged, p)
+			}
+			return nil
+		},
+		CommitWithMessageFunc: func(message string, opts git.CommitOptions) error {
+			f.commits = append(f.commits, message)
+			f.committedFiles = append(f.committedFiles, f.paths())
+			f.staged = map[string]bool{}
+			return nil
+		},
+	}
+}
+
+func twoGroupAI() *MockAI {
+	return &MockAI{
+		SplitChangesFunc: func(diff, rules string) ([]ai.ChangeGroup, error) {
+			return []ai.ChangeGroup{
+				{Description: "auth", Files: []string{"auth.go", "auth_test.go"}},
+				{Description: "docs", Files: []string{"README.md"}},
+			}, nil
+		},
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			if strings.Contains(diff, "auth.go") {
+				return message("feat(auth): add login"), nil
+			}
+			return message("docs: describe login"), nil
+		},
+	}
+}
+
+func TestApp_RunSplitSession_CommitsEachGroup(t *testing.T) {
+	idx := newFakeIndex("README.md", "auth.go", "auth_test.go")
+	app := NewApp(idx.mockGit(), &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, twoGroupAI())
+	app.Input = strings.NewReader("c\nc\n")
+
+	if err := app.RunSplitSession(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedCommits := []string{"feat(auth): add login", "docs: describe login"}
+	if !reflect.DeepEqual(idx.commits, expectedCommits) {
+		t.Errorf("expected commits %v, got %v", expectedCommits, idx.commits)
+	}
+	expectedFiles := [][]string{{"auth.go", "auth_test.go"}, {"README.md"}}
+	if !reflect.DeepEqual(idx.committedFiles, expectedFiles) {
+		t.Errorf("expected committed files %v, got %v", expectedFiles, idx.committedFiles)
+	}
+	if len(idx.staged) != 0 {
+		t.Errorf("expected nothing left staged, got %v", idx.paths())
+	}
+}
+
+func TestApp_RunSplitSession_SkipRestagesGroup(t *testing.T) {
+	idx := newFakeIndex("README.md", "auth.go", "auth_test.go")
+	app := NewApp(idx.mockGit(), &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, twoGroupAI())
+	app.Input = strings.NewReader("s\nc\n")
+
+	if err := app.RunSplitSession(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(idx.commits, []string{"docs: describe login"}) {
+		t.Errorf("expected only the docs commit, got %v", idx.commits)
+	}
+	if !reflect.DeepEqual(idx.paths(), []string{"auth.go", "auth_test.go"}) {
+		t.Errorf("expected skipped group to be restaged, got %v", idx.paths())
+	}
+}
+
+func TestApp_RunSplitSession_QuitRestagesRemaining(t *testing.T) {
+	idx := newFakeIndex("README.md", "auth.go", "auth_test.go")
+	app := NewApp(idx.mockGit(), &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, twoGroupAI())
+	app.Input = strings.NewReader("q\n")
+
+	if err := app.RunSplitSession(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(idx.commits) != 0 {
+		t.Errorf("expected no commits, got %v", idx.commits)
+	}
+	if !reflect.DeepEqual(idx.paths(), []string{"README.md", "auth.go", "auth_test.go"}) {
+		t.Errorf("expected all files restaged, got %v", idx.paths())
+	}
+}
+
+func TestApp_RunSplitSession_RequiresExplicitCommit(t *testing.T) {
+	idx := newFakeIndex("README.md", "auth.go", "auth_test.go")
+	app := NewApp(idx.mockGit(), &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, twoGroupAI())
+	// A bare Enter asks again instead of committing
+	app.Input = strings.NewReader("\nq\n")
+
+	if err := app.RunSplitSession(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(idx.commits) != 0 {
+		t.Errorf("expected no commits, got %v", idx.commits)
+	}
+	if !reflect.DeepEqual(idx.paths(), []string{"README.md", "auth.go", "auth_test.go"}) {
+		t.Errorf("expected all files restaged, got %v", idx.paths())
+	}
+}
+
+func TestApp_RunSplitSession_KeepsIgnoredFilesOut(t *testing.T) {
+	idx := newFakeIndex("README.md", "auth.go", "auth_test.go", "deps.lock")
+	idx.ignored["deps.lock"] = true
+	var grouped string
+	aiClient := twoGroupAI()
+	splitChanges := aiClient.SplitChangesFunc
+	aiClient.SplitChangesFunc = func(diff, rules string) ([]ai.ChangeGroup, error) {
+		grouped = diff
+		return splitChanges(diff, rules)
+	}
+	app := NewApp(idx.mockGit(), &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, aiClient)
+	app.Input = strings.NewReader("c\nc\n")
+
+	if err := app.RunSplitSession(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(grouped, "deps.lock") {
+		t.Errorf("expected ignored files to stay out of the split diff, got %q", grouped)
+	}
+	expectedFiles := [][]string{{"auth.go", "auth_test.go"}, {"README.md"}}
+	if !reflect.DeepEqual(idx.committedFiles, expectedFiles) {
+		t.Errorf("expected committed files %v, got %v", expectedFiles, idx.committedFiles)
+	}
+	if !reflect.DeepEqual(idx.paths(), []string{"deps.lock"}) {
+		t.Errorf("expected the ignored file restaged, got %v", idx.paths())
+	}
+}
+
+func TestApp_RunSplitSession_Refuses(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(idx *fakeIndex, mock *MockGit)
+		expectedErr string
+	}{
+		{
+			name:        "Partially staged file",
+			setup:       func(idx *fakeIndex, mock *MockGit) { idx.unstaged["auth.go"] = true },
+			expectedErr: "auth.go also changed since being staged",
+		},
+		{
+			name: "Mid-rebase",
+			setup: func(idx *fakeIndex, mock *MockGit) {
+				mock.IsMidMergeOrRebaseFunc = func() (bool, error) { return true, nil }
+			},
+			expectedErr: "a merge or rebase is in progress",
+		},
+		{
+			name: "Only ignored files",
+			setup: func(idx *fakeIndex, mock *MockGit) {
+				for _, path := range idx.paths() {
+					idx.ignored[path] = true
+				}
+			},
+			expectedErr: "no relevant staged changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := newFakeIndex("README.md", "auth.go", "auth_test.go")
+			mock := idx.mockGit()
+			tt.setup(idx, mock)
+			aiClient := twoGroupAI()
+			aiClient.SplitChangesFunc = func(diff, rules string) ([]ai.ChangeGroup, error) {
+				t.Error("the model should not be asked to split")
+				return nil, nil
+			}
+			app := NewApp(mock, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, aiClient)
+
+			err := app.RunSplitSession(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if !reflect.DeepEqual(idx.paths(), []string{"README.md", "auth.go", "auth_test.go"}) {
+				t.Errorf("expected the index untouched, got %v", idx.paths())
+			}
+		})
+	}
+}
+
+func TestApp_RunSplitSession_PostProcessesMessages(t *testing.T) {
+	idx := newFakeIndex("README.md", "auth.go", "auth_test.go")
+	aiClient := twoGroupAI()
+	aiClient.CheckMessageFunc = func(message, diff, rules string) (*ai.SelfCheckResult, error) {
+		return &ai.SelfCheckResult{Confidence: 10, Issues: "vague"}, nil
+	}
+	app := NewApp(idx.mockGit(), &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, aiClient)
+	app.Config = &config.Config{SelfCheck: "strict", MinConfidence: 70}
+	app.Input = strings.NewReader("c\nc\n")
+
+	err := app.RunSplitSession(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "self-check confidence 10") {
+		t.Fatalf("expected the strict self-check to reject the group message, got %v", err)
+	}
+	if len(idx.commits) != 0 {
+		t.Errorf("expected no commits, got %v", idx.commits)
+	}
+	if !reflect.DeepEqual(idx.paths(), []string{"README.md", "auth.go", "auth_test.go"}) {
+		t.Errorf("expected all files restaged, got %v", idx.paths())
+	}
+}
+
+func TestAssignFiles(t *testing.T) {
+	groups := []ai.ChangeGroup{
+		{Description: "api", Files: []string{"api.go", "ghost.go"}},
+		{Description: "dup", Files: []string{"api.go"}},
+		{Description: "ui", Files: []string{"ui.css"}},
+	}
+	staged := []string{"api.go", "main.go", "ui.css"}
+
+	expected := []ai.ChangeGroup{
+		{Description: "api", Files: []string{"api.go"}},
+		{Description: "ui", Files: []string{"ui.css"}},
+		{Description: "Remaining changes", Files: []string{"main.go"}},
+	}
+	if got := assignFiles(groups, staged); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
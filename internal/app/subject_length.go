@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+// defaultMaxSubjectLength is the longest subject line accepted without
+// complaint, in characters
+const defaultMaxSubjectLength = 72
+
+// Modes for subject_length_mode
+const (
+	subjectLengthWarn       = "warn"
+	subjectLengthTruncate   = "truncate"
+	subjectLengthRegenerate = "regenerate"
+)
+
+// maxSubjectLength returns the configured subject length limit, or 0 if
+// the limit is disabled
+func (a *App) maxSubjectLength() int {
+	if a.Config == nil || a.Config.MaxSubjectLength == 0 {
+		return defaultMaxSubjectLength
+	}
+	return max(a.Config.MaxSubjectLength, 0)
+}
+
+// subjectLengthMode returns what to do about subjects over the limit
+func (a *App) subjectLengthMode() string {
+	if a.Config == nil || a.Config.SubjectLengthMode == "" {
+		return subjectLengthWarn
+	}
+	return a.Config.SubjectLengthMode
+}
+
+// fitSubject applies the subject length limit to a generated message.
+// Depending on the mode it warns, truncates the subject at a word
+// boundary, or regenerates the message once with a stricter instruction.
+// reserved characters of the limit are kept free for text added to the
+// subject later.
+func (a *App) fitSubject(ctx context.Context, generated *ai.GenerateResult, diff, rules string, opts RunOptions, reserved int) (*ai.GenerateResult, error) {
+	limit := a.maxSubjectLength()
+	if generated.Kind != ai.ResultMessage || limit == 0 {
+		return generated, nil
+	}
+	limit = max(limit-reserved, 1)
+	subject := subjectLine(generated.Content)
+	length := utf8.RuneCountInString(subject)
+	if length <= limit {
+		return generated, nil
+	}
+
+	switch a.subjectLengthMode() {
+	case subjectLengthTruncate:
+		content := truncateSubject(subject, limit) + strings.TrimPrefix(generated.Content, subject)
+		return &ai.GenerateResult{Kind: ai.ResultMessage, Content: content}, nil
+	case subjectLengthRegenerate:
+		fmt.Fprintf(a.status(), "Warning: subject is %d characters, over the limit of %d, regenerating...\n", length, limit)
+		retryRules := appendRule(rules, fmt.Sprintf("The subject line must be at most %d characters. A previous attempt, %q, was %d characters; shorten the description.", limit, subject, length))
+		regenerated, err := a.generateMessage(ctx, diff, retryRules, opts)
+		if err != nil {
+			return nil, err
+		}
+		if regenerated.Kind == ai.ResultMessage {
+			if length := utf8.RuneCountInString(subjectLine(regenerated.Content)); length > limit {
+				fmt.Fprintf(a.status(), "Warning: regenerated subject is still %d characters, over the limit of %d\n", length, limit)
+			}
+		}
+		return regenerated, nil
+	default:
+		fmt.Fprintf(a.status(), "Warning: subject is %d characters, over the limit of %d\n", length, limit)
+		return generated, nil
+	}
+}
+
+// truncateSubject cuts subject down to limit characters, at the last word
+// boundary after the conventional commit prefix if there is one
+func truncateSubject(subject string, limit int) string {
+	runes := []rune(subject)
+	if len(runes) <= limit {
+		return subject
+	}
+	prefix := 0
+	if m := conventionalPrefix.FindString(subject); m != "" {
+		prefix = utf8.RuneCountInString(m)
+	}
+
+	cut := runes[:limit]
+	for i := limit; i > prefix; i-- {
+		if runes[i] == ' ' {
+			cut = runes[:i]
+			break
+		}
+	}
+	return strings.TrimRight(string(cut), " ,;:-")
+}
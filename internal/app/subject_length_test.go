@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestTruncateSubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		limit    int
+		expected string
+	}{
+		{name: "Short enough", subject: "fix: handle nil", limit: 20, expected: "fix: handle nil"},
+		{name: "Word boundary", subject: "feat(auth): add login with remember me", limit: 30, expected: "feat(auth): add login with"},
+		{name: "Cut right before a space", subject: "feat: add login now", limit: 15, expected: "feat: add login"},
+		{name: "Trailing punctuation", subject: "fix: retry 503s, and also timeouts", limit: 19, expected: "fix: retry 503s"},
+		{name: "No boundary after prefix", subject: "feat(auth): supercalifragilistic", limit: 20, expected: "feat(auth): supercal"},
+		{name: "Multi-byte characters", subject: "✨ feat: ajouté la connexion sécurisée", limit: 27, expected: "✨ feat: ajouté la connexion"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateSubject(tt.subject, tt.limit); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApp_Run_SubjectLength(t *testing.T) {
+	long := "feat(auth): add login with remember me and a forgotten password flow for all users"
+	tests := []struct {
+		name            string
+		config          *config.Config
+		responses       []string
+		expectedMessage string
+		expectedCalls   int
+	}{
+		{
+			name:            "Warn keeps the message",
+			config:          &config.Config{SubjectLengthMode: "warn"},
+			responses:       []string{long},
+			expectedMessage: long,
+			expectedCalls:   1,
+		},
+		{
+			name:            "Truncate",
+			config:          &config.Config{SubjectLengthMode: "truncate", MaxSubjectLength: 40},
+			responses:       []string{long + "\n\nExplains why."},
+			expectedMessage: "feat(auth): add login with remember me\n\nExplains why.",
+			expectedCalls:   1,
+		},
+		{
+			name:            "Regenerate",
+			config:          &config.Config{SubjectLengthMode: "regenerate"},
+			responses:       []string{long, "feat(auth): add login and password reset"},
+			expectedMessage: "feat(auth): add login and password reset",
+			expectedCalls:   2,
+		},
+		{
+			name:            "Regenerate keeps a still long message",
+			config:          &config.Config{SubjectLengthMode: "regenerate"},
+			responses:       []string{long, long},
+			expectedMessage: long,
+			expectedCalls:   2,
+		},
+		{
+			name:            "Limit disabled",
+			config:          &config.Config{SubjectLengthMode: "truncate", MaxSubjectLength: -1},
+			responses:       []string{long},
+			expectedMessage: long,
+			expectedCalls:   1,
+		},
+		{
+			name:            "Within the limit",
+			config:          &config.Config{SubjectLengthMode: "regenerate"},
+			responses:       []string{"feat(auth): add login"},
+			expectedMessage: "feat(auth): add login",
+			expectedCalls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					calls = append(calls, rules)
+					return message(tt.responses[len(calls)-1]), nil
+				},
+			})
+			app.Config = tt.config
+
+			result, err := app.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, result.Message)
+			}
+			if len(calls) != tt.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", tt.expectedCalls, len(calls))
+			}
+			if tt.expectedCalls == 2 && !strings.Contains(calls[1], "at most 72 characters") {
+				t.Errorf("expected a length rule on retry, got %q", calls[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"ai-commit-message-generator/internal/git"
+)
+
+// maxStatBarWidth caps the +/- graph of a summary line, like git's --stat
+const maxStatBarWidth = 40
+
+// CommitSummary describes what landed in a commit made by the tool
+type CommitSummary struct {
+	Message string
+	Files   []git.FileStat
+}
+
+// Insertions returns the total number of inserted lines
+func (s *CommitSummary) Insertions() int {
+	total := 0
+	for _, f := range s.Files {
+		total += f.Insertions
+	}
+	return total
+}
+
+// Deletions returns the total number of deleted lines
+func (s *CommitSummary) Deletions() int {
+	total := 0
+	for _, f := range s.Files {
+		total += f.Deletions
+	}
+	return total
+}
+
+// String formats the summary like a short `git show --stat`: the subject
+// line, one line per file, and a totals line
+func (s *CommitSummary) String() string {
+	var sb strings.Builder
+
+	subject, _, _ := strings.Cut(s.Message, "\n")
+	sb.WriteString(subject)
+	sb.WriteString("\n")
+
+	pathWidth, maxChanges := 0, 0
+	for _, f := range s.Files {
+		pathWidth = max(pathWidth, len(f.Path))
+		maxChanges = max(maxChanges, f.Insertions+f.Deletions)
+	}
+	countWidth := len(fmt.Sprint(maxChanges))
+
+	for _, f := range s.Files {
+		fmt.Fprintf(&sb, " %-*s | ", pathWidth, f.Path)
+		if f.Binary {
+			sb.WriteString("Bin\n")
+			continue
+		}
+		plus, minus := f.Insertions, f.Deletions
+		if maxChanges > maxStatBarWidth {
+			plus = plus * maxStatBarWidth / maxChanges
+			minus = minus * maxStatBarWidth / maxChanges
+		}
+		fmt.Fprintf(&sb, "%*d %s%s\n", countWidth, f.Insertions+f.Deletions,
+			strings.Repeat("+", plus), strings.Repeat("-", minus))
+	}
+
+	sb.WriteString(" " + git.StatSummary(s.Files))
+	return sb.String()
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/git"
+)
+
+func TestCommitSummary_String(t *testing.T) {
+	summary := &CommitSummary{
+		Message: "feat(auth): add login\n\nCloses #1",
+		Files: []git.FileStat{
+			{Path: "auth/login.go", Insertions: 10, Deletions: 2},
+			{Path: "README.md", Insertions: 1},
+			{Path: "logo.png", Binary: true},
+		},
+	}
+
+	expected := "feat(auth): add login\n" +
+		" auth/login.go | 12 ++++++++++--\n" +
+		" README.md     |  1 +\n" +
+		" logo.png      | Bin\n" +
+		" 3 files changed, 11 insertions(+), 2 deletions(-)"
+	if got := summary.String(); got != expected {
+		t.Errorf("expected summary:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestCommitSummary_StringScalesBars(t *testing.T) {
+	summary := &CommitSummary{
+		Message: "chore: regenerate",
+		Files:   []git.FileStat{{Path: "gen.go", Insertions: 100, Deletions: 100}},
+	}
+
+	expected := "chore: regenerate\n" +
+		" gen.go | 200 ++++++++++++++++++++--------------------\n" +
+		" 1 file changed, 100 insertions(+), 100 deletions(-)"
+	if got := summary.String(); got != expected {
+		t.Errorf("expected summary:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestApp_Run_CommitSummary(t *testing.T) {
+	var committed bool
+	stats := []git.FileStat{{Path: "main.go", Insertions: 3, Deletions: 1}}
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+		GetStagedDiffStatsFunc: func() ([]git.FileStat, error) {
+			if committed {
+				t.Error("expected stats to be read before committing")
+			}
+			return stats, nil
+		},
+		CommitWithMessageFunc: func(message string, opts git.CommitOptions) error {
+			committed = true
+			return nil
+		},
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			return message("fix: handle nil"), nil
+		},
+	})
+
+	result, err := app.Run(context.Background(), RunOptions{Commit: true, Summary: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Summary == nil {
+		t.Fatal("expected a summary")
+	}
+	expected := "fix: handle nil\n main.go | 4 +++-\n 1 file changed, 3 insertions(+), 1 deletion(-)"
+	if got := result.Summary.String(); got != expected {
+		t.Errorf("expected summary %q, got %q", expected, got)
+	}
+}
+
+func TestApp_Run_CommitSummaryStatsError(t *testing.T) {
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+		GetStagedDiffStatsFunc: func() ([]git.FileStat, error) {
+			return nil, errors.New("boom")
+		},
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			return message("fix: handle nil"), nil
+		},
+	})
+
+	result, err := app.Run(context.Background(), RunOptions{Commit: true, Summary: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Committed || result.Summary != nil {
+		t.Errorf("expected a commit without summary, got %+v", result)
+	}
+}
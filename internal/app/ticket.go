@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+// defaultBranchTicketTemplate adds the ticket as a footer, so the subject
+// stays a Conventional Commit and within the length limit
+const defaultBranchTicketTemplate = "{{.Message}}\n\nRefs: {{.Ticket}}"
+
+// branchTicketData is the data a branch_ticket_template is rendered with
+type branchTicketData struct {
+	Ticket  string
+	Message string
+}
+
+var (
+	// jiraTicketPattern matches tracker keys such as PROJ-123
+	jiraTicketPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+	// issueNumberPattern matches an issue number with an explicit marker
+	// in a branch segment, e.g. "fix/#123", "issue-42" or "gh-7-typo".
+	// Bare numbers such as the year in "hotfix/2024-10-cleanup" are not
+	// issue references.
+	issueNumberPattern = regexp.MustCompile(`(?i)(?:^|[/_-])(?:#|(?:issues?|gh)[-_/]?#?)([0-9]+)(?:[/_-]|$)`)
+)
+
+// ExtractTicket returns the ticket reference embedded in a branch name.
+// Tracker keys (PROJ-123) are returned as-is and marked issue numbers are
+// returned as "#123". It returns an empty string if nothing is found.
+func ExtractTicket(branch string) string {
+	if key := jiraTicketPattern.FindString(branch); key != "" {
+		return key
+	}
+	if m := issueNumberPattern.FindStringSubmatch(branch); m != nil {
+		return "#" + m[1]
+	}
+	return ""
+}
+
+// isFixMessage reports whether a conventional commit message has the fix type
+func isFixMessage(message string) bool {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(message), "fix")
+	return ok && (strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "(") || strings.HasPrefix(rest, "!"))
+}
+
+// addClosingFooter appends a "<keyword> <ticket>" footer to fix messages so
+// the issue is closed when the commit lands. Other messages are returned
+// unchanged.
+func addClosingFooter(message, ticket, keyword string) string {
+	if ticket == "" || !isFixMessage(message) {
+		return message
+	}
+	return message + "\n\n" + keyword + " " + ticket
+}
+
+// extractBranchTicket returns the ticket pattern finds in branch: its first
+// capture group if it has one, else the whole match. It returns an empty
+// string if nothing matches.
+func extractBranchTicket(branch string, pattern *regexp.Regexp) string {
+	m := pattern.FindStringSubmatch(branch)
+	switch {
+	case m == nil:
+		return ""
+	case len(m) > 1:
+		return m[1]
+	}
+	return m[0]
+}
+
+// formatTicketMessage renders tmpl to add ticket to message. A message that
+// already mentions the ticket is returned unchanged.
+func formatTicketMessage(message, ticket, tmpl string) (string, error) {
+	if ticket == "" || strings.Contains(message, ticket) {
+		return message, nil
+	}
+	if tmpl == "" {
+		tmpl = defaultBranchTicketTemplate
+	}
+	t, err := template.New("ticket").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid branch_ticket_template: %w", err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, branchTicketData{Ticket: ticket, Message: message}); err != nil {
+		return "", fmt.Errorf("failed to render branch_ticket_template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// ticketSubjectLength returns how many characters tmpl adds to the subject
+// when it adds ticket, so the subject can leave room for them
+func ticketSubjectLength(ticket, tmpl string) int {
+	const probe = "subject"
+	formatted, err := formatTicketMessage(probe, ticket, tmpl)
+	if err != nil {
+		return 0
+	}
+	return max(utf8.RuneCountInString(subjectLine(formatted))-len(probe), 0)
+}
+
+// branchTicket returns the ticket found in the current branch name, if
+// branch_ticket_pattern is set. Branch lookup failures are warned about
+// and give no ticket.
+func (a *App) branchTicket() (string, error) {
+	if a.Config == nil || a.Config.BranchTicketPattern == "" {
+		return "", nil
+	}
+	pattern, err := regexp.Compile(a.Config.BranchTicketPattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid branch_ticket_pattern: %w", err)
+	}
+	branch, err := a.Git.GetCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(a.status(), "Warning: failed to read current branch: %v\n", err)
+		return "", nil
+	}
+	return extractBranchTicket(branch, pattern), nil
+}
+
+// addBranchTicket adds ticket to message with branch_ticket_template. A
+// template that turns a Conventional Commit into something else is warned
+// about.
+func (a *App) addBranchTicket(message, ticket string) (string, error) {
+	formatted, err := formatTicketMessage(message, ticket, a.Config.BranchTicketTemplate)
+	if err != nil {
+		return "", err
+	}
+	if a.Config.PromptTemplate == "" && ai.ValidateConventional(message, a.allowedTypes()) == nil {
+		if invalid := ai.ValidateConventional(formatted, a.allowedTypes()); invalid != nil {
+			fmt.Fprintf(a.status(), "Warning: branch_ticket_template makes the message not a Conventional Commit: %v\n", invalid)
+		}
+	}
+	return formatted, nil
+}
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestExtractTicket(t *testing.T) {
+	tests := []struct {
+		branch   string
+		expected string
+	}{
+		{branch: "feature/PROJ-123-add-login", expected: "PROJ-123"},
+		{branch: "PROJ-42", expected: "PROJ-42"},
+		{branch: "fix/issue-123-null-pointer", expected: "#123"},
+		{branch: "issue-42", expected: "#42"},
+		{branch: "fix/#77", expected: "#77"},
+		{branch: "gh-7-typo", expected: "#7"},
+		{branch: "fix/Issues/15", expected: "#15"},
+		{branch: "fix/123-null-pointer", expected: ""},
+		{branch: "hotfix/2024-10-cleanup", expected: ""},
+		{branch: "main", expected: ""},
+		{branch: "release-v2", expected: ""},
+		{branch: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			if got := ExtractTicket(tt.branch); got != tt.expected {
+				t.Errorf("ExtractTicket(%q) = %q, expected %q", tt.branch, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddClosingFooter(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		ticket   string
+		keyword  string
+		expected string
+	}{
+		{
+			name:     "Fix with scope",
+			message:  "fix(api): handle nil user",
+			ticket:   "#123",
+			keyword:  "Closes",
+			expected: "fix(api): handle nil user\n\nCloses #123",
+		},
+		{
+			name:     "Fix without scope",
+			message:  "fix: handle nil user",
+			ticket:   "PROJ-9",
+			keyword:  "Resolves",
+			expected: "fix: handle nil user\n\nResolves PROJ-9",
+		},
+		{
+			name:     "Breaking fix",
+			message:  "fix!: drop legacy flag",
+			ticket:   "#5",
+			keyword:  "Fixes",
+			expected: "fix!: drop legacy flag\n\nFixes #5",
+		},
+		{
+			name:     "Feature is unchanged",
+			message:  "feat(auth): add login",
+			ticket:   "#123",
+			keyword:  "Closes",
+			expected: "feat(auth): add login",
+		},
+		{
+			name:     "Fixture type is not a fix",
+			message:  "fixture: add sample data",
+			ticket:   "#123",
+			keyword:  "Closes",
+			expected: "fixture: add sample data",
+		},
+		{
+			name:     "No ticket",
+			message:  "fix: handle nil user",
+			ticket:   "",
+			keyword:  "Closes",
+			expected: "fix: handle nil user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addClosingFooter(tt.message, tt.ticket, tt.keyword); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApp_AddIssueFooter(t *testing.T) {
+	app := NewApp(&MockGit{
+		GetCurrentBranchFunc: func() (string, error) { return "bugfix/#88-timeout", nil },
+	}, nil, nil, nil)
+	app.Config = &config.Config{IssueFooter: true, ClosingKeyword: "Fixes"}
+
+	if got := app.addIssueFooter("fix: raise timeout"); got != "fix: raise timeout\n\nFixes #88" {
+		t.Errorf("unexpected message: %q", got)
+	}
+
+	app.Git = &MockGit{
+		GetCurrentBranchFunc: func() (string, error) { return "", errors.New("no HEAD") },
+	}
+	if got := app.addIssueFooter("fix: raise timeout"); got != "fix: raise timeout" {
+		t.Errorf("expected message unchanged on branch error, got %q", got)
+	}
+
+	// Tracker keys need opting in
+	app.Git = &MockGit{
+		GetCurrentBranchFunc: func() (string, error) { return "fix/PROJ-12-timeout", nil },
+	}
+	if got := app.addIssueFooter("fix: raise timeout"); got != "fix: raise timeout" {
+		t.Errorf("expected no footer for a tracker key, got %q", got)
+	}
+	app.Config.IssueFooterKeys = true
+	if got := app.addIssueFooter("fix: raise timeout"); got != "fix: raise timeout\n\nFixes PROJ-12" {
+		t.Errorf("expected a footer for an opted-in tracker key, got %q", got)
+	}
+}
+
+func TestExtractBranchTicket(t *testing.T) {
+	tests := []struct {
+		branch   string
+		pattern  string
+		expected string
+	}{
+		{branch: "JIRA-1234-do-thing", pattern: `[A-Z]+-[0-9]+`, expected: "JIRA-1234"},
+		{branch: "feature/JIRA-1234-do-thing", pattern: `[A-Z]+-[0-9]+`, expected: "JIRA-1234"},
+		{branch: "feature/ops-77/fix", pattern: `^feature/([a-z]+-[0-9]+)`, expected: "ops-77"},
+		{branch: "main", pattern: `[A-Z]+-[0-9]+`, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			got := extractBranchTicket(tt.branch, regexp.MustCompile(tt.pattern))
+			if got != tt.expected {
+				t.Errorf("extractBranchTicket(%q, %q) = %q, expected %q", tt.branch, tt.pattern, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatTicketMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		ticket   string
+		tmpl     string
+		expected string
+	}{
+		{
+			name:     "Default template adds a footer",
+			message:  "feat(auth): add login\n\nUses OAuth2.",
+			ticket:   "JIRA-1234",
+			expected: "feat(auth): add login\n\nUses OAuth2.\n\nRefs: JIRA-1234",
+		},
+		{
+			name:     "Subject template",
+			message:  "fix: raise timeout",
+			ticket:   "JIRA-1234",
+			tmpl:     "[{{.Ticket}}] {{.Message}}",
+			expected: "[JIRA-1234] fix: raise timeout",
+		},
+		{
+			name:     "No ticket",
+			message:  "fix: raise timeout",
+			expected: "fix: raise timeout",
+		},
+		{
+			name:     "Ticket already mentioned",
+			message:  "fix: raise timeout for JIRA-1234",
+			ticket:   "JIRA-1234",
+			expected: "fix: raise timeout for JIRA-1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatTicketMessage(tt.message, tt.ticket, tt.tmpl)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := formatTicketMessage("fix: x", "JIRA-1", "[{{.Ticket}"); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
+
+func TestFormatTicketMessage_StaysConventional(t *testing.T) {
+	formatted, err := formatTicketMessage("feat(auth): add login", "JIRA-1234", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ai.ValidateConventional(formatted, nil); err != nil {
+		t.Errorf("expected %q to be a Conventional Commit, got %v", formatted, err)
+	}
+}
+
+func TestTicketSubjectLength(t *testing.T) {
+	tests := []struct {
+		tmpl     string
+		expected int
+	}{
+		{tmpl: "", expected: 0},
+		{tmpl: "{{.Message}}\n\nRefs: {{.Ticket}}", expected: 0},
+		{tmpl: "[{{.Ticket}}] {{.Message}}", expected: len("[JIRA-1234] ")},
+	}
+
+	for _, tt := range tests {
+		if got := ticketSubjectLength("JIRA-1234", tt.tmpl); got != tt.expected {
+			t.Errorf("ticketSubjectLength(%q) = %d, expected %d", tt.tmpl, got, tt.expected)
+		}
+	}
+}
+
+func TestApp_Run_BranchTicket(t *testing.T) {
+	tests := []struct {
+		name     string
+		branch   string
+		config   *config.Config
+		expected string
+	}{
+		{
+			name:     "Off by default",
+			branch:   "JIRA-1234-do-thing",
+			config:   &config.Config{},
+			expected: "feat: add login",
+		},
+		{
+			name:     "Ticket from the branch",
+			branch:   "JIRA-1234-do-thing",
+			config:   &config.Config{BranchTicketPattern: `[A-Z]+-[0-9]+`},
+			expected: "feat: add login\n\nRefs: JIRA-1234",
+		},
+		{
+			name:     "Subject template leaves room for the ticket",
+			branch:   "JIRA-1234-do-thing",
+			config:   &config.Config{BranchTicketPattern: `[A-Z]+-[0-9]+`, BranchTicketTemplate: "[{{.Ticket}}] {{.Message}}", MaxSubjectLength: 26, SubjectLengthMode: "truncate"},
+			expected: "[JIRA-1234] feat: add",
+		},
+		{
+			name:     "Branch without a ticket",
+			branch:   "main",
+			config:   &config.Config{BranchTicketPattern: `[A-Z]+-[0-9]+`},
+			expected: "feat: add login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+				GetCurrentBranchFunc: func() (string, error) { return tt.branch, nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return message("feat: add login"), nil
+				},
+			})
+			app.Config = tt.config
+
+			result, err := app.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Message != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, result.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UninstallOptions holds settings for Uninstall
+type UninstallOptions struct {
+	// Purge also removes the config and rules files
+	Purge bool
+}
+
+// Uninstall undoes Init: it removes the hooks Init generated, restoring a
+// hook it backed up, and with Purge the config and rules files. Hooks
+// installed by other tools are left alone.
+func (a *App) Uninstall(opts UninstallOptions) error {
+	isRepo, err := a.Git.IsInsideRepo()
+	if err != nil {
+		return fmt.Errorf("failed to check repository status: %w", err)
+	}
+	if !isRepo {
+		return errors.New("not a git repository. Please run this command from within a git repository")
+	}
+
+	repoRoot, err := a.Git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repository root: %w", err)
+	}
+
+	hooksDir, err := a.Git.GitPath("hooks")
+	if err != nil {
+		return fmt.Errorf("failed to find hooks directory: %w", err)
+	}
+
+	removed := false
+	for _, hookType := range []string{HookPreCommit, HookPrepareCommitMsg} {
+		hookPath := hookFilePath(hooksDir, hookType)
+		ok, err := removeHook(hookPath)
+		if err != nil {
+			return fmt.Errorf("failed to remove %s hook: %w", hookType, err)
+		}
+		if !ok {
+			continue
+		}
+		removed = true
+		fmt.Printf("✓ Removed %s hook\n", hookType)
+
+		backupPath := hookPath + hookBackupSuffix
+		if _, err := os.Stat(backupPath); err == nil {
+			if err := os.Rename(backupPath, hookPath); err != nil {
+				return fmt.Errorf("failed to restore %s hook: %w", hookType, err)
+			}
+			fmt.Printf("✓ Restored the previous %s hook from %s\n", hookType, backupPath)
+		}
+	}
+
+	if opts.Purge {
+		for _, name := range []string{".commit-generator-config", ".git-commit-rules-for-ai"} {
+			err := os.Remove(filepath.Join(repoRoot, name))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+			removed = true
+			fmt.Printf("✓ Removed %s\n", name)
+		}
+	}
+
+	if !removed {
+		fmt.Println("Nothing to remove.")
+	}
+	return nil
+}
+
+// removeHook deletes the hook at hookPath if Init generated it, and
+// reports whether it did. Missing hooks and hooks from other tools are
+// left alone.
+func removeHook(hookPath string) (bool, error) {
+	content, err := os.ReadFile(hookPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !strings.Contains(string(content), hookMarker) {
+		fmt.Printf("Warning: %s was not installed by generate-commit, leaving it alone\n", hookPath)
+		return false, nil
+	}
+	if err := os.Remove(hookPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
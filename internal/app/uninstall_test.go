@@ -0,0 +1,103 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_Uninstall(t *testing.T) {
+	generated := "#!/bin/bash\n# Pre-commit hook for AI commit message generator\n"
+	foreign := "#!/bin/sh\nnpx lint-staged\n"
+
+	tests := []struct {
+		name          string
+		hook          string
+		backup        string
+		purge         bool
+		expectedHook  string
+		expectRemoved []string
+		expectKept    []string
+	}{
+		{
+			name:       "Generated hook",
+			hook:       generated,
+			expectKept: []string{".commit-generator-config", ".git-commit-rules-for-ai"},
+		},
+		{
+			name:         "Foreign hook",
+			hook:         foreign,
+			expectedHook: foreign,
+			expectKept:   []string{".commit-generator-config", ".git-commit-rules-for-ai"},
+		},
+		{
+			name:         "Backed up hook is restored",
+			hook:         generated,
+			backup:       foreign,
+			expectedHook: foreign,
+		},
+		{
+			name:          "Purge",
+			hook:          generated,
+			purge:         true,
+			expectRemoved: []string{".commit-generator-config", ".git-commit-rules-for-ai"},
+		},
+		{
+			name:          "Purge keeps a foreign hook",
+			hook:          foreign,
+			purge:         true,
+			expectedHook:  foreign,
+			expectRemoved: []string{".commit-generator-config", ".git-commit-rules-for-ai"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, hookPath := newInitRepo(t)
+			files := map[string]string{
+				hookPath: tt.hook,
+				filepath.Join(root, ".commit-generator-config"): "{}",
+				filepath.Join(root, ".git-commit-rules-for-ai"): "# rules\n",
+			}
+			if tt.backup != "" {
+				files[hookPath+".backup"] = tt.backup
+			}
+			for path, content := range files {
+				if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+					t.Fatalf("failed to write %s: %v", path, err)
+				}
+			}
+
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc: func() (bool, error) { return true, nil },
+				GetRepoRootFunc:  func() (string, error) { return root, nil },
+			}, nil, nil, nil)
+
+			if err := app.Uninstall(UninstallOptions{Purge: tt.purge}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			hook, err := os.ReadFile(hookPath)
+			if tt.expectedHook == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("expected the hook to be removed, got %q", hook)
+				}
+			} else if string(hook) != tt.expectedHook {
+				t.Errorf("expected hook %q, got %q", tt.expectedHook, hook)
+			}
+			if _, err := os.Stat(hookPath + ".backup"); !os.IsNotExist(err) {
+				t.Error("expected no backup left behind")
+			}
+			for _, name := range tt.expectKept {
+				if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+					t.Errorf("expected %s to be kept: %v", name, err)
+				}
+			}
+			for _, name := range tt.expectRemoved {
+				if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+					t.Errorf("expected %s to be removed", name)
+				}
+			}
+		})
+	}
+}
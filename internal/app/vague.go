@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultVaguePhrases are rejected when ForbidVague is enabled and no
+// vague_phrases are configured
+var defaultVaguePhrases = []string{
+	"update code", "updated code", "update files", "updated files",
+	"minor changes", "minor fixes", "small fixes", "some changes",
+	"various changes", "misc changes", "fix stuff", "fix bug", "fix issues",
+	"wip",
+}
+
+// vaguePhrases returns the configured phrase list, or the defaults
+func (a *App) vaguePhrases() []string {
+	if a.Config != nil && len(a.Config.VaguePhrases) > 0 {
+		return a.Config.VaguePhrases
+	}
+	return defaultVaguePhrases
+}
+
+// forbidVague reports whether vague messages should be rejected
+func (a *App) forbidVague() bool {
+	return a.Config != nil && a.Config.ForbidVague
+}
+
+// vagueRule builds the prompt rule forbidding the given phrases
+func vagueRule(phrases []string) string {
+	quoted := make([]string, len(phrases))
+	for i, phrase := range phrases {
+		quoted[i] = fmt.Sprintf("%q", phrase)
+	}
+	return "Be specific about what changed. Never use vague phrases such as " + strings.Join(quoted, ", ") + "."
+}
+
+// findVaguePhrase returns the first phrase found as whole words in the
+// description of message (ignoring case), or "" if there is none
+func findVaguePhrase(message string, phrases []string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	description := conventionalPrefix.ReplaceAllString(subject, "")
+	for _, phrase := range phrases {
+		re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(phrase) + `\b`)
+		if err != nil {
+			continue
+		}
+		if re.MatchString(description) {
+			return phrase
+		}
+	}
+	return ""
+}
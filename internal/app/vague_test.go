@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestFindVaguePhrase(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{message: "chore: update code", expected: "update code"},
+		{message: "fix(api): Minor Changes", expected: "minor changes"},
+		{message: "WIP", expected: "wip"},
+		{message: "feat(auth): add OAuth2 login support", expected: ""},
+		{message: "fix: handle wiping of caches", expected: ""},
+		{message: "refactor: update codec registry", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := findVaguePhrase(tt.message, defaultVaguePhrases); got != tt.expected {
+				t.Errorf("findVaguePhrase(%q) = %q, expected %q", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApp_Run_ForbidVague(t *testing.T) {
+	tests := []struct {
+		name          string
+		responses     []string
+		phrases       []string
+		expected      string
+		expectedCalls int
+		expectedVague string
+	}{
+		{
+			name:          "Specific message",
+			responses:     []string{"feat(auth): add token refresh"},
+			expected:      "feat(auth): add token refresh",
+			expectedCalls: 1,
+		},
+		{
+			name:          "Vague then specific",
+			responses:     []string{"chore: update code", "fix(cache): evict expired entries"},
+			expected:      "fix(cache): evict expired entries",
+			expectedCalls: 2,
+		},
+		{
+			name:          "Still vague after retry",
+			responses:     []string{"chore: update code", "chore: minor changes"},
+			expected:      "chore: minor changes",
+			expectedCalls: 2,
+			expectedVague: "minor changes",
+		},
+		{
+			name:          "Custom phrases",
+			responses:     []string{"fix: tweak things", "fix(ui): align header"},
+			phrases:       []string{"tweak things"},
+			expected:      "fix(ui): align header",
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					calls = append(calls, rules)
+					return message(tt.responses[len(calls)-1]), nil
+				},
+			})
+			app.Config = &config.Config{ForbidVague: true, VaguePhrases: tt.phrases}
+
+			result, err := app.Run(context.Background(), RunOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(calls) != tt.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", tt.expectedCalls, len(calls))
+			}
+			if !strings.Contains(calls[0], "Never use vague phrases") {
+				t.Errorf("expected rules to forbid vague phrases, got %q", calls[0])
+			}
+			if tt.expectedCalls > 1 && !strings.Contains(calls[1], tt.responses[0]) {
+				t.Errorf("expected retry rules to mention the rejected message, got %q", calls[1])
+			}
+			if result.Message != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, result.Message)
+			}
+			if result.VaguePhrase != tt.expectedVague {
+				t.Errorf("expected vague phrase %q, got %q", tt.expectedVague, result.VaguePhrase)
+			}
+		})
+	}
+}
+
+func TestApp_Run_VagueAllowedByDefault(t *testing.T) {
+	calls := 0
+	app := NewApp(&MockGit{
+		IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+		HasStagedChangesFunc: func() (bool, error) { return true, nil },
+		GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+	}, &MockConfig{
+		LoadRulesFunc: func() (string, error) { return "", nil },
+	}, nil, &MockAI{
+		GenerateCommitMessageFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+			calls++
+			return message("chore: update code"), nil
+		},
+	})
+
+	if _, err := app.Run(context.Background(), RunOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call without forbid_vague, got %d", calls)
+	}
+}
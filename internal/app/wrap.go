@@ -0,0 +1,78 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultBodyWrapWidth is the git convention for body lines, in characters
+const defaultBodyWrapWidth = 72
+
+// bulletPrefix matches the marker of a list item, e.g. "- ", "* ", "1. "
+// or "2) ", including any indentation before it
+var bulletPrefix = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+
+// bodyWrapWidth returns the configured body width, or 0 if wrapping is
+// disabled
+func (a *App) bodyWrapWidth() int {
+	if a.Config == nil || a.Config.BodyWrapWidth == 0 {
+		return defaultBodyWrapWidth
+	}
+	return max(a.Config.BodyWrapWidth, 0)
+}
+
+// wrapMessageBody wraps the body of message, leaving the subject alone
+func wrapMessageBody(message string, width int) string {
+	subject, body, ok := strings.Cut(message, "\n")
+	if !ok || width <= 0 {
+		return message
+	}
+	return subject + "\n" + wrapBody(body, width)
+}
+
+// wrapBody wraps each line of body at width characters. Existing line
+// breaks and blank lines are kept, list items wrap under their text
+// instead of their marker, and indented lines such as code are left as is.
+func wrapBody(body string, width int) string {
+	lines := strings.Split(body, "\n")
+	var wrapped []string
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) <= width {
+			wrapped = append(wrapped, line)
+			continue
+		}
+		if marker := bulletPrefix.FindString(line); marker != "" {
+			indent := strings.Repeat(" ", utf8.RuneCountInString(marker))
+			wrapped = append(wrapped, wrapLine(line[len(marker):], marker, indent, width)...)
+			continue
+		}
+		if strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			wrapped = append(wrapped, line)
+			continue
+		}
+		wrapped = append(wrapped, wrapLine(line, "", "", width)...)
+	}
+	return strings.Join(wrapped, "\n")
+}
+
+// wrapLine breaks text at spaces into lines of at most width characters,
+// the first starting with first and the rest with indent. Words longer
+// than a line, such as URLs, are not broken.
+func wrapLine(text, first, indent string, width int) []string {
+	var lines []string
+	current := first
+	empty := true
+	for _, word := range strings.Fields(text) {
+		if !empty && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, current)
+			current, empty = indent, true
+		}
+		if !empty {
+			current += " "
+		}
+		current += word
+		empty = false
+	}
+	return append(lines, current)
+}
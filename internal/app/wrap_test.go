@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/config"
+)
+
+func TestWrapBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		width    int
+		expected string
+	}{
+		{
+			name:     "Short lines are unchanged",
+			body:     "Gateways return 503 during deploys.",
+			width:    72,
+			expected: "Gateways return 503 during deploys.",
+		},
+		{
+			name:  "Long paragraph",
+			body:  "Gateways return 503 while a deploy drains the old pods, so requests made during a rollout failed even though a retry a second later would have worked.",
+			width: 72,
+			expected: "Gateways return 503 while a deploy drains the old pods, so requests made\n" +
+				"during a rollout failed even though a retry a second later would have\n" +
+				"worked.",
+		},
+		{
+			name:     "Existing line breaks and blank lines are kept",
+			body:     "First line.\nSecond line.\n\nNew paragraph.",
+			width:    20,
+			expected: "First line.\nSecond line.\n\nNew paragraph.",
+		},
+		{
+			name:  "Bullets wrap under their text and are not merged",
+			body:  "- retry 503 responses from the gateway with backoff\n- log each retry\n* keep the original error when giving up",
+			width: 30,
+			expected: "- retry 503 responses from the\n" +
+				"  gateway with backoff\n" +
+				"- log each retry\n" +
+				"* keep the original error when\n" +
+				"  giving up",
+		},
+		{
+			name:  "Numbered and nested items",
+			body:  "1. parse the Retry-After header first\n  - fall back to exponential backoff",
+			width: 24,
+			expected: "1. parse the Retry-After\n" +
+				"   header first\n" +
+				"  - fall back to\n" +
+				"    exponential backoff",
+		},
+		{
+			name:     "Long words are not broken",
+			body:     "See https://example.com/a/very/long/path/that/does/not/fit for details",
+			width:    20,
+			expected: "See\nhttps://example.com/a/very/long/path/that/does/not/fit\nfor details",
+		},
+		{
+			name:     "Indented code is left alone",
+			body:     "    if err := client.Do(request); err != nil { return err }",
+			width:    20,
+			expected: "    if err := client.Do(request); err != nil { return err }",
+		},
+		{
+			name:     "Width counts characters, not bytes",
+			body:     "café café café",
+			width:    9,
+			expected: "café café\ncafé",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapBody(tt.body, tt.width)
+			if got != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApp_Run_BodyWrap(t *testing.T) {
+	long := "fix(api): retry 503 responses\n\n" + strings.Repeat("Gateways return 503 during deploys. ", 5)
+
+	tests := []struct {
+		name     string
+		config   *config.Config
+		maxWidth int
+	}{
+		{name: "Default width", config: &config.Config{}, maxWidth: 72},
+		{name: "Configured width", config: &config.Config{BodyWrapWidth: 40}, maxWidth: 40},
+		{name: "Disabled", config: &config.Config{BodyWrapWidth: -1}, maxWidth: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp(&MockGit{
+				IsInsideRepoFunc:     func() (bool, error) { return true, nil },
+				HasStagedChangesFunc: func() (bool, error) { return true, nil },
+				GetStagedDiffFunc:    func() (string, error) { return "diff", nil },
+			}, &MockConfig{
+				LoadRulesFunc: func() (string, error) { return "", nil },
+			}, nil, &MockAI{
+				GenerateCommitMessageWithBodyFunc: func(diff, rules string) (*ai.GenerateResult, error) {
+					return message(long), nil
+				},
+			})
+			app.Config = tt.config
+
+			result, err := app.Run(context.Background(), RunOptions{Body: true})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			subject, _, _ := strings.Cut(result.Message, "\n")
+			if subject != "fix(api): retry 503 responses" {
+				t.Errorf("expected the subject to be kept, got %q", subject)
+			}
+			if tt.maxWidth == 0 {
+				if result.Message != long {
+					t.Errorf("expected the body unwrapped, got %q", result.Message)
+				}
+				return
+			}
+			for _, line := range strings.Split(result.Message, "\n") {
+				if utf8.RuneCountInString(line) > tt.maxWidth {
+					t.Errorf("line over %d characters: %q", tt.maxWidth, line)
+				}
+			}
+		})
+	}
+}
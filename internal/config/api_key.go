@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ai-commit-message-generator/internal/shell"
+)
+
+// apiKeyCommandTimeout bounds how long api_key_command may run, leaving
+// time to unlock a password manager
+const apiKeyCommandTimeout = 30 * time.Second
+
+// resolveAPIKey sets APIKey from APIKeyFile or APIKeyCommand when it is
+// not set, returning where the key came from. A relative APIKeyFile is
+// relative to repoRoot.
+func (c *Config) resolveAPIKey(repoRoot string) (string, error) {
+	switch {
+	case c.APIKey != "":
+		return "", nil
+	case c.APIKeyFile != "":
+		path := c.APIKeyFile
+		if !filepath.IsAbs(path) && repoRoot != "" {
+			path = filepath.Join(repoRoot, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read api_key_file: %w", err)
+		}
+		c.APIKey = strings.TrimSpace(string(data))
+		if c.APIKey == "" {
+			return "", fmt.Errorf("api_key_file %s is empty", path)
+		}
+		return path, nil
+	case c.APIKeyCommand != "":
+		key, err := runAPIKeyCommand(c.APIKeyCommand, apiKeyCommandTimeout)
+		if err != nil {
+			return "", err
+		}
+		c.APIKey = key
+		return "api_key_command", nil
+	}
+	return "", nil
+}
+
+// runAPIKeyCommand runs command in the shell and returns its trimmed
+// output
+func runAPIKeyCommand(command string, timeout time.Duration) (string, error) {
+	key, err := shell.Run("api_key_command", command, nil, timeout)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", errors.New("api_key_command printed no key")
+	}
+	return key, nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_APIKeySources(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("key commands use sh")
+	}
+	tests := []struct {
+		name           string
+		configData     string
+		keyFile        string
+		envKey         string
+		expectedKey    string
+		expectedSource string
+		expectedErr    string
+	}{
+		{
+			name:           "Key file",
+			configData:     `{"api_key_file": "secrets/key"}`,
+			keyFile:        "sk-from-file\n",
+			expectedKey:    "sk-from-file",
+			expectedSource: filepath.Join("secrets", "key"),
+		},
+		{
+			name:           "Key command",
+			configData:     `{"api_key_command": "printf 'sk-from-command\\n'"}`,
+			expectedKey:    "sk-from-command",
+			expectedSource: "api_key_command",
+		},
+		{
+			name:           "Key file over environment",
+			configData:     `{"api_key_file": "secrets/key"}`,
+			keyFile:        "sk-from-file",
+			envKey:         "sk-from-env",
+			expectedKey:    "sk-from-file",
+			expectedSource: filepath.Join("secrets", "key"),
+		},
+		{
+			name:           "API key over key command",
+			configData:     `{"api_key": "sk-inline", "api_key_command": "exit 1"}`,
+			expectedKey:    "sk-inline",
+			expectedSource: ".commit-generator-config",
+		},
+		{
+			name:        "Missing key file",
+			configData:  `{"api_key_file": "secrets/key"}`,
+			expectedErr: "failed to read api_key_file",
+		},
+		{
+			name:        "Empty key file",
+			configData:  `{"api_key_file": "secrets/key"}`,
+			keyFile:     "\n",
+			expectedErr: "is empty",
+		},
+		{
+			name:        "Failing key command",
+			configData:  `{"api_key_command": "echo vault is sealed >&2; exit 1"}`,
+			expectedErr: "api_key_command failed: vault is sealed",
+		},
+		{
+			name:        "Silent key command",
+			configData:  `{"api_key_command": "true"}`,
+			expectedErr: "printed no key",
+		},
+		{
+			name:        "Both file and command",
+			configData:  `{"api_key_file": "secrets/key", "api_key_command": "printf sk"}`,
+			keyFile:     "sk-from-file",
+			expectedErr: "set only one",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("OLLAMA_API_KEY", tt.envKey)
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			if tt.keyFile != "" {
+				if err := os.MkdirAll(filepath.Join(tmpDir, "secrets"), 0700); err != nil {
+					t.Fatalf("Failed to create secrets dir: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(tmpDir, "secrets", "key"), []byte(tt.keyFile), 0600); err != nil {
+					t.Fatalf("Failed to write key file: %v", err)
+				}
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, source, err := NewConfigLoader().LoadConfigWithSource()
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.APIKey != tt.expectedKey {
+				t.Errorf("Expected api_key %q, got %q", tt.expectedKey, config.APIKey)
+			}
+			if !strings.HasSuffix(source.APIKeySource, tt.expectedSource) {
+				t.Errorf("Expected the key to come from %q, got %q", tt.expectedSource, source.APIKeySource)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// coAuthorPattern matches "Name <email>"
+var coAuthorPattern = regexp.MustCompile(`^([^<>]*[^<>\s])\s*<([^<>\s@]+@[^<>\s@]+\.[^<>\s@]+)>$`)
+
+// ParseCoAuthor checks that s is in the "Name <email>" form of a
+// Co-authored-by trailer, returning it with the spacing normalized
+func ParseCoAuthor(s string) (string, error) {
+	m := coAuthorPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", fmt.Errorf("invalid co-author %q: must be \"Name <email>\"", s)
+	}
+	return fmt.Sprintf("%s <%s>", strings.Join(strings.Fields(m[1]), " "), m[2]), nil
+}
+
+// MergeCoAuthors parses the co-authors from each list in turn, dropping
+// any whose email was already seen, ignoring case
+func MergeCoAuthors(lists ...[]string) ([]string, error) {
+	var merged []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, s := range list {
+			coAuthor, err := ParseCoAuthor(s)
+			if err != nil {
+				return nil, err
+			}
+			email := strings.ToLower(coAuthor[strings.LastIndex(coAuthor, "<"):])
+			if seen[email] {
+				continue
+			}
+			seen[email] = true
+			merged = append(merged, coAuthor)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCoAuthor(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr bool
+	}{
+		{input: "Ada Lovelace <ada@example.com>", expected: "Ada Lovelace <ada@example.com>"},
+		{input: "  Ada   Lovelace<ada@example.com>  ", expected: "Ada Lovelace <ada@example.com>"},
+		{input: "Jean-Luc O'Brien <jl.obrien+git@mail.example.org>", expected: "Jean-Luc O'Brien <jl.obrien+git@mail.example.org>"},
+		{input: "ada@example.com", expectedErr: true},
+		{input: "<ada@example.com>", expectedErr: true},
+		{input: "Ada Lovelace", expectedErr: true},
+		{input: "Ada <ada>", expectedErr: true},
+		{input: "Ada <ada@example.com", expectedErr: true},
+		{input: "Ada <ada@example.com> extra", expectedErr: true},
+		{input: "Ada <a b@example.com>", expectedErr: true},
+		{input: "", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCoAuthor(tt.input)
+			if tt.expectedErr {
+				if err == nil || !strings.Contains(err.Error(), "must be \"Name <email>\"") {
+					t.Errorf("expected a format error, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMergeCoAuthors(t *testing.T) {
+	got, err := MergeCoAuthors(
+		[]string{"Ada Lovelace <ada@example.com>", "Alan Turing <alan@example.com>"},
+		nil,
+		[]string{"Ada L. <Ada@Example.com>", "Grace Hopper <grace@example.com>", "Grace Hopper <grace@example.com>"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"Ada Lovelace <ada@example.com>", "Alan Turing <alan@example.com>", "Grace Hopper <grace@example.com>"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if _, err := MergeCoAuthors([]string{"Ada Lovelace <ada@example.com>", "not a co-author"}); err == nil {
+		t.Error("expected an error for a malformed co-author")
+	}
+}
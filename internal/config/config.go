@@ -1,54 +1,857 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+// DefaultMaxDiffBytes is the default cap on the diff size sent to the AI
+const DefaultMaxDiffBytes = 10000
+
+// ConfigPathEnv names the environment variable that points LoadConfig at an
+// explicit config file instead of the repo-root default
+const ConfigPathEnv = "GENERATE_COMMIT_CONFIG"
+
+// PromptTemplateFile is the repo-root file read as the prompt template when
+// prompt_template is not set
+const PromptTemplateFile = ".git-commit-prompt-template"
+
+// Environment variables that override the config files for a single
+// run. A command line Overrides field takes precedence over them.
+const (
+	ModelEnv   = "GENERATE_COMMIT_MODEL"
+	BaseURLEnv = "GENERATE_COMMIT_BASE_URL"
+	TimeoutEnv = "GENERATE_COMMIT_TIMEOUT"
 )
 
+// DebugEnv names the environment variable that turns on logging of the
+// raw model requests and responses, like --debug
+const DebugEnv = "GENERATE_COMMIT_DEBUG"
+
+// conventionalType matches the conventional commit types allowed_types may
+// list, which the message validator only accepts in lowercase
+var conventionalType = regexp.MustCompile(`^[a-z]+$`)
+
+// headerName matches a valid HTTP header name
+var headerName = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
 // Config represents the application configuration
 type Config struct {
-	APIKey         string `json:"api_key"`
+	Provider string `json:"provider"`
+	APIKey   string `json:"api_key"`
+
+	// APIKeyFile and APIKeyCommand supply the API key when APIKey is
+	// empty: the contents of a file (relative to the repository root), or
+	// the output of a shell command such as a secrets manager CLI. Only
+	// one may be set. Both take precedence over the environment.
+	APIKeyFile    string `json:"api_key_file,omitempty"`
+	APIKeyCommand string `json:"api_key_command,omitempty"`
+
 	Model          string `json:"model"`
 	BaseURL        string `json:"base_url"`
 	TimeoutSeconds int    `json:"timeout_seconds"`
+
+	// AzureResource, AzureDeployment and AzureAPIVersion build the
+	// endpoint for the azure provider when base_url is not set. The API
+	// version defaults to DefaultAzureAPIVersion.
+	AzureResource   string `json:"azure_resource,omitempty"`
+	AzureDeployment string `json:"azure_deployment,omitempty"`
+	AzureAPIVersion string `json:"azure_api_version,omitempty"`
+
+	// MaxDiffBytes caps the diff sent to the AI; longer diffs are truncated,
+	// leaving out vendored and generated files before source files.
+	// 0 means unlimited.
+	MaxDiffBytes int `json:"max_diff_bytes"`
+
+	// MaxPromptTokens caps the estimated size of the prompt, so it fits the
+	// model's context window. Files are left out of the diff until it
+	// fits, vendored and generated ones first. 0 means unlimited.
+	MaxPromptTokens int `json:"max_prompt_tokens,omitempty"`
+
+	// ExtraOptions is passed through verbatim to the provider's model options
+	// (e.g. Ollama's "options" object) so new model parameters can be used
+	// without adding a dedicated config field for each one.
+	ExtraOptions map[string]any `json:"extra_options,omitempty"`
+
+	// ExtraHeaders are sent with every API request, e.g. an organization ID
+	// a gateway needs. They may replace Authorization for custom auth, but
+	// not Content-Type.
+	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
+
+	// ProxyURL is the proxy for API requests, overriding HTTPS_PROXY,
+	// HTTP_PROXY and git's http.proxy
+	ProxyURL string `json:"proxy_url,omitempty"`
+
+	// CACertFile is a PEM file of CA certificates to trust besides the
+	// system roots, for endpoints behind a private CA. A relative path is
+	// relative to the repository root.
+	CACertFile string `json:"ca_cert_file,omitempty"`
+
+	// InsecureSkipVerify turns off TLS certificate verification. Prefer
+	// CACertFile; this is for testing only.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+
+	// Temperature and TopP control how creative the model's sampling is.
+	// 0 leaves them out of the request so the model's defaults apply.
+	Temperature float64 `json:"temperature,omitempty"`
+	TopP        float64 `json:"top_p,omitempty"`
+
+	// Style is the message style: "conventional" (default) or "gitmoji" to
+	// prefix each message with the emoji for its type
+	Style string `json:"style,omitempty"`
+
+	// MaxSubjectLength is the longest subject line accepted, in characters.
+	// 0 uses the default of 72; a negative value disables the check.
+	// SubjectLengthMode decides what happens to longer subjects: "warn"
+	// (default), "truncate" at a word boundary, or "regenerate" once.
+	MaxSubjectLength  int    `json:"max_subject_length,omitempty"`
+	SubjectLengthMode string `json:"subject_length_mode,omitempty"`
+
+	// Language is the language messages are written in, e.g. "fr"
+	// (default "en"). The conventional type stays in English.
+	Language string `json:"language,omitempty"`
+
+	// PromptTemplate is a Go text/template replacing the built-in commit
+	// message prompt, with {{.Diff}} and {{.Rules}} placeholders. When
+	// empty, the repo's PromptTemplateFile is used if it exists.
+	PromptTemplate string `json:"prompt_template,omitempty"`
+
+	// IncludeBody asks the model for a body explaining why the change was
+	// made, below the subject line
+	IncludeBody bool `json:"include_body,omitempty"`
+
+	// BodyWrapWidth is the column the body is wrapped at. 0 uses the git
+	// convention of 72; a negative value disables wrapping.
+	BodyWrapWidth int `json:"body_wrap_width,omitempty"`
+
+	// HistoryContextCount is how many recent commit subjects are shown to
+	// the model as examples of the repository's style. 0 disables it.
+	HistoryContextCount int `json:"history_context_count,omitempty"`
+
+	// SignOff adds a Signed-off-by trailer for the git user to commits made
+	// by the tool, for projects that require a DCO
+	SignOff bool `json:"sign_off,omitempty"`
+
+	// CoAuthors are "Name <email>" entries added as Co-authored-by
+	// trailers to commits made by the tool, e.g. while pairing
+	CoAuthors []string `json:"co_authors,omitempty"`
+
+	// IssueFooter appends a closing-keyword footer (e.g. "Closes #123") to
+	// fix commits when the branch name references an issue with #123,
+	// issue-123 or gh-123. IssueFooterKeys also adds it for tracker keys
+	// such as PROJ-123.
+	IssueFooter     bool   `json:"issue_footer,omitempty"`
+	IssueFooterKeys bool   `json:"issue_footer_keys,omitempty"`
+	ClosingKeyword  string `json:"closing_keyword,omitempty"`
+
+	// BranchTicketPattern is a regular expression finding a ticket ID in
+	// the branch name, e.g. "[A-Z]+-[0-9]+" for JIRA-1234-do-thing. Its
+	// first capture group is the ticket, or the whole match without one.
+	// BranchTicketTemplate is a Go text/template adding the ticket to the
+	// message, with {{.Ticket}} and {{.Message}} placeholders; empty adds a
+	// "Refs: <ticket>" footer.
+	BranchTicketPattern  string `json:"branch_ticket_pattern,omitempty"`
+	BranchTicketTemplate string `json:"branch_ticket_template,omitempty"`
+
+	// SelfCheck runs a second pass where the model grades its own message:
+	// "off" (default), "warn" to flag messages below MinConfidence, or
+	// "strict" to reject them. MinConfidence defaults to 70; 0 accepts
+	// every message.
+	SelfCheck     string `json:"self_check,omitempty"`
+	MinConfidence int    `json:"min_confidence"`
+
+	// JitterMillis is the maximum random delay before the first API request,
+	// so simultaneous commits don't all hit a shared gateway at once.
+	// It is also added to retries. 0 disables it.
+	JitterMillis int `json:"jitter_millis,omitempty"`
+
+	// MaxRetries is how often a rate-limited, failing (5xx) or unreachable
+	// request is retried. 0 uses the default of 3; a negative value
+	// disables retries.
+	// RetryBaseDelayMillis is the first backoff, doubled on each retry;
+	// 0 uses the default of 2000. A Retry-After header takes precedence.
+	MaxRetries           int `json:"max_retries,omitempty"`
+	RetryBaseDelayMillis int `json:"retry_base_delay_ms,omitempty"`
+
+	// AllowOfflineFallback builds a heuristic message from the diff when
+	// the API can't be reached, instead of failing
+	AllowOfflineFallback bool `json:"allow_offline_fallback,omitempty"`
+
+	// Cache reuses the model's response when the same diff is described
+	// again with the same rules and model, for CacheTTLMinutes (0 uses the
+	// default of 60)
+	Cache           bool `json:"cache,omitempty"`
+	CacheTTLMinutes int  `json:"cache_ttl_minutes,omitempty"`
+
+	// LogFile, if set, is a file each run appends a JSON line to, with the
+	// generated message, the model, what became of the message, and a hash
+	// of the diff. A relative path is relative to the repository root.
+	LogFile string `json:"log_file,omitempty"`
+
+	// BulkRenameThreshold is how many files must move between the same two
+	// directories before their renames are summarized in a single line.
+	// 0 uses the default of 3; a negative value lists every rename.
+	BulkRenameThreshold int `json:"bulk_rename_threshold,omitempty"`
+
+	// Profiles holds named provider settings, e.g. a local Ollama and a
+	// cloud endpoint. The selected profile's non-empty fields override the
+	// flat fields above.
+	Profiles      map[string]Profile `json:"profiles,omitempty"`
+	ActiveProfile string             `json:"active_profile,omitempty"`
+
+	// FallbackProviders are tried in order when the provider fails with a
+	// rate limit, server error or network failure. Each is a profile name,
+	// or a provider name used with its default endpoint and the key from
+	// its environment variable.
+	FallbackProviders []string `json:"fallback_providers,omitempty"`
+
+	// ForbidVague tells the model to be specific and regenerates once if
+	// the message still contains one of VaguePhrases (built-in defaults
+	// such as "update code" when empty)
+	ForbidVague  bool     `json:"forbid_vague,omitempty"`
+	VaguePhrases []string `json:"vague_phrases,omitempty"`
+
+	// MessageFilterCommand is a shell command that receives the generated
+	// message on stdin and prints the (possibly rewritten) message. A
+	// non-zero exit rejects the message.
+	MessageFilterCommand string `json:"message_filter_command,omitempty"`
+
+	// ExcludePaths are .gitignore-style globs for staged files to leave out
+	// of the diff sent to the model (e.g. lockfiles and build output)
+	ExcludePaths []string `json:"exclude_paths,omitempty"`
+
+	// RedactPatterns are extra regular expressions for secrets to scrub
+	// from the diff, on top of the built-in ones (AWS keys, bearer tokens,
+	// passwords, private keys). Only the first capture group is redacted
+	// if the pattern has one.
+	RedactPatterns []string `json:"redact_patterns,omitempty"`
+
+	// RulesMergeStrategy combines the rules files found from the working
+	// directory up to the repo root: "nearest" (default) uses the closest
+	// one, "concat" joins them all, root first
+	RulesMergeStrategy string `json:"rules_merge_strategy,omitempty"`
+
+	// TestPatterns are the globs identifying test files for --tests-only.
+	// Empty uses built-in patterns such as *_test.go and *.spec.ts.
+	TestPatterns []string `json:"test_patterns,omitempty"`
+
+	// AllowedTypes are the conventional commit types the model may use and
+	// generated messages are checked against. Empty allows the default
+	// seven: feat, fix, docs, style, refactor, test, chore.
+	AllowedTypes []string `json:"allowed_types,omitempty"`
+
+	// ConflictMarkers is what to do when a staged file still has merge
+	// conflict markers: "error" (default) refuses to generate, "strip"
+	// removes the marker lines from the diff and warns
+	ConflictMarkers string `json:"conflict_markers,omitempty"`
+}
+
+// Profile is a named set of provider settings
+type Profile struct {
+	Provider string `json:"provider,omitempty"`
+	APIKey   string `json:"api_key,omitempty"`
+	Model    string `json:"model,omitempty"`
+	BaseURL  string `json:"base_url,omitempty"`
+}
+
+// Store checks for and creates the repository config file
+type Store interface {
+	ConfigExists() (bool, error)
+	SaveDefaultConfig(repoRoot string) error
 }
 
 // ConfigLoader handles loading configuration from file, env, or defaults
-type ConfigLoader struct{}
+type ConfigLoader struct {
+	// Profile selects a profile by name, overriding active_profile
+	Profile string
+	// Dir is the directory whose repository config is loaded. Empty uses
+	// the current working directory.
+	Dir string
+	// Overrides are settings from the command line, which take precedence
+	// over the environment and the config files
+	Overrides Overrides
+}
+
+// Overrides replace config settings for a single run. Empty fields are
+// left alone.
+type Overrides struct {
+	Model          string
+	BaseURL        string
+	TimeoutSeconds int
+}
+
+// applyOverrides sets the model, base URL and timeout from the
+// environment, then from overrides
+func (c *Config) applyOverrides(overrides Overrides) error {
+	env := Overrides{
+		Model:   os.Getenv(ModelEnv),
+		BaseURL: os.Getenv(BaseURLEnv),
+	}
+	if timeout := os.Getenv(TimeoutEnv); timeout != "" {
+		seconds, err := strconv.Atoi(timeout)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: must be a number of seconds", TimeoutEnv, timeout)
+		}
+		env.TimeoutSeconds = seconds
+	}
+
+	for _, o := range []Overrides{env, overrides} {
+		if o.Model != "" {
+			c.Model = o.Model
+		}
+		if o.BaseURL != "" {
+			c.BaseURL = o.BaseURL
+		}
+		if o.TimeoutSeconds != 0 {
+			c.TimeoutSeconds = o.TimeoutSeconds
+		}
+	}
+	return nil
+}
+
+// repoRoot returns the root of the repository containing c.Dir
+func (c *ConfigLoader) repoRoot() (string, error) {
+	dir, err := workDir(c.Dir)
+	if err != nil {
+		return "", err
+	}
+	return findRepoRoot(dir)
+}
 
 // NewConfigLoader creates a new config loader
 func NewConfigLoader() *ConfigLoader {
 	return &ConfigLoader{}
 }
 
-// LoadConfig loads configuration with priority: file > env > defaults
+// ConfigSource records where the effective configuration came from
+type ConfigSource struct {
+	// GlobalPath is the user-level config file, if one was read
+	GlobalPath string
+	// Path is the repo config file ($GENERATE_COMMIT_CONFIG or the repo
+	// root file), if one was read
+	Path string
+	// APIKeySource describes where the API key came from: a config file
+	// path, a profile, an environment variable, or "" if no key is set
+	APIKeySource string
+}
+
+// LoadConfig loads configuration with priority: file > env > defaults,
+// except that Overrides and the model, base URL and timeout environment
+// variables take precedence over the files
 func (c *ConfigLoader) LoadConfig() (*Config, error) {
+	config, _, err := c.LoadConfigWithSource()
+	return config, err
+}
+
+// LoadConfigWithSource loads the configuration like LoadConfig and also
+// reports which files and environment variables it came from
+func (c *ConfigLoader) LoadConfigWithSource() (*Config, *ConfigSource, error) {
+	// Defaults that a config may set to zero, such as min_confidence, are
+	// set here so only an absent key keeps them
 	config := &Config{
-		Model:          "gpt-oss:120b",
-		BaseURL:        "http://localhost:11434/api/generate",
+		Provider:       "ollama",
 		TimeoutSeconds: 60,
+		MaxDiffBytes:   DefaultMaxDiffBytes,
+		MinConfidence:  70,
 	}
+	source := &ConfigSource{}
 
-	// Try to load from config file
-	repoRoot, err := findRepoRoot()
-	if err == nil {
+	// The user-level config provides defaults shared by all repositories
+	if globalPath, err := GlobalConfigPath(); err == nil {
+		if fileData, err := os.ReadFile(globalPath); err == nil {
+			if err := overlayConfig(config, fileData); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse global config file: %w", err)
+			}
+			source.GlobalPath = globalPath
+			if config.APIKey != "" {
+				source.APIKeySource = globalPath
+			}
+		}
+	}
+
+	// The repo config is overlaid on top. An explicit config path must
+	// exist; the repo-root file is optional.
+	globalKey := config.APIKey
+	if configPath := os.Getenv(ConfigPathEnv); configPath != "" {
+		fileData, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file from %s: %w", ConfigPathEnv, err)
+		}
+		if err := overlayConfig(config, fileData); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		source.Path = configPath
+	} else if repoRoot, err := c.repoRoot(); err == nil {
 		configPath := filepath.Join(repoRoot, ".commit-generator-config")
 		if fileData, err := os.ReadFile(configPath); err == nil {
-			if err := json.Unmarshal(fileData, config); err != nil {
-				return nil, fmt.Errorf("failed to parse config file: %w", err)
+			if err := overlayConfig(config, fileData); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
 			}
+			source.Path = configPath
 		}
 	}
+	if config.PromptTemplate == "" {
+		if repoRoot, err := c.repoRoot(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(repoRoot, PromptTemplateFile)); err == nil {
+				config.PromptTemplate = string(data)
+			}
+		}
+	}
+	for _, path := range []*string{&config.CACertFile, &config.LogFile} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			if repoRoot, err := c.repoRoot(); err == nil {
+				*path = filepath.Join(repoRoot, *path)
+			}
+		}
+	}
+	if config.APIKey != globalKey {
+		source.APIKeySource = source.Path
+	}
+
+	profileKey := config.APIKey
+	if err := config.applyProfile(c.Profile); err != nil {
+		return nil, nil, err
+	}
+	if config.APIKey != profileKey {
+		source.APIKeySource = ""
+		if config.APIKey != "" {
+			source.APIKeySource = "profile " + config.ActiveProfile
+		}
+	}
+
+	if err := config.applyOverrides(c.Overrides); err != nil {
+		return nil, nil, err
+	}
+
+	// The default endpoint and model depend on the provider
+	if config.BaseURL == "" {
+		config.BaseURL = config.defaultBaseURL()
+	}
+	if config.Model == "" {
+		config.Model = config.defaultModel()
+	}
+
+	// api_key_file and api_key_command only apply without an api_key
+	repoRoot, _ := c.repoRoot()
+	keySource, err := config.resolveAPIKey(repoRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keySource != "" {
+		source.APIKeySource = keySource
+	}
 
 	// Override with environment variable if config file doesn't have it
 	if config.APIKey == "" {
-		config.APIKey = os.Getenv("OLLAMA_API_KEY")
+		var env string
+		config.APIKey, env = envAPIKey(config.Provider)
+		if config.APIKey != "" {
+			source.APIKeySource = env
+		}
+	}
+
+	if config.ClosingKeyword == "" {
+		config.ClosingKeyword = "Closes"
+	}
+	if config.SelfCheck == "" {
+		config.SelfCheck = "off"
+	}
+	if config.Style == "" {
+		config.Style = "conventional"
+	}
+	if config.Language == "" {
+		config.Language = "en"
+	}
+	if config.SubjectLengthMode == "" {
+		config.SubjectLengthMode = "warn"
+	}
+	if config.RulesMergeStrategy == "" {
+		config.RulesMergeStrategy = RulesMergeNearest
+	}
+	if config.ConflictMarkers == "" {
+		config.ConflictMarkers = "error"
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return config, source, nil
+}
+
+// Masked returns a copy of the config with API keys masked, safe to print
+func (c *Config) Masked() *Config {
+	masked := *c
+	masked.APIKey = MaskAPIKey(c.APIKey)
+	if c.Profiles != nil {
+		masked.Profiles = make(map[string]Profile, len(c.Profiles))
+		for name, profile := range c.Profiles {
+			profile.APIKey = MaskAPIKey(profile.APIKey)
+			masked.Profiles[name] = profile
+		}
 	}
+	return &masked
+}
+
+// MaskAPIKey hides all but the first few characters of an API key, e.g.
+// "sk-****". Short keys are masked entirely.
+func MaskAPIKey(key string) string {
+	const visible = 3
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 2*visible {
+		return "****"
+	}
+	return key[:visible] + "****"
+}
+
+// applyProfile resolves the named profile, or the active profile if name
+// is empty, into the flat fields. Without either, the flat fields are used
+// as they are.
+func (c *Config) applyProfile(name string) error {
+	if name == "" {
+		name = c.ActiveProfile
+	}
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	c.ActiveProfile = name
+
+	if profile.Provider != "" && profile.Provider != c.Provider {
+		c.Provider = profile.Provider
+		// The flat key, model and endpoint belong to the other provider;
+		// sending its key to this one would leak it
+		c.APIKey = ""
+		c.APIKeyFile = ""
+		c.APIKeyCommand = ""
+		c.Model = ""
+		c.BaseURL = ""
+	}
+	if profile.APIKey != "" {
+		c.APIKey = profile.APIKey
+	}
+	if profile.Model != "" {
+		c.Model = profile.Model
+	}
+	if profile.BaseURL != "" {
+		c.BaseURL = profile.BaseURL
+	}
+	return nil
+}
+
+// providerKeyEnv names the environment variable holding the API key for
+// providers other than Ollama
+var providerKeyEnv = map[string]string{
+	"openai": "OPENAI_API_KEY",
+	"azure":  "AZURE_OPENAI_API_KEY",
+	"gemini": "GEMINI_API_KEY",
+}
 
-	return config, nil
+// APIKeyEnv names the environment variable that holds provider's API key
+func APIKeyEnv(provider string) string {
+	if env, ok := providerKeyEnv[provider]; ok {
+		return env
+	}
+	return "OLLAMA_API_KEY"
+}
+
+// envAPIKey returns the API key for provider from its environment
+// variable, falling back to OLLAMA_API_KEY, and the variable it came from
+func envAPIKey(provider string) (string, string) {
+	if env, ok := providerKeyEnv[provider]; ok {
+		if key := os.Getenv(env); key != "" {
+			return key, env
+		}
+	}
+	return os.Getenv("OLLAMA_API_KEY"), "OLLAMA_API_KEY"
+}
+
+// Fallbacks returns the settings for each of FallbackProviders, in order.
+// Each is a copy of c with the named profile applied or, for a provider
+// name, with that provider, its default endpoint and its key from the
+// environment. A fallback with another provider than c doesn't inherit
+// c's key, endpoint or model.
+func (c *Config) Fallbacks() ([]*Config, error) {
+	var fallbacks []*Config
+	for _, name := range c.FallbackProviders {
+		fallback := *c
+		fallback.FallbackProviders = nil
+		profile, isProfile := c.Profiles[name]
+		provider := name
+		if isProfile {
+			provider = profile.Provider
+		}
+		if provider != "" && provider != c.Provider {
+			fallback.Provider = provider
+			fallback.APIKey = ""
+			fallback.BaseURL = ""
+			fallback.Model = ""
+		}
+		if isProfile {
+			if err := fallback.applyProfile(name); err != nil {
+				return nil, err
+			}
+		} else {
+			fallback.ActiveProfile = ""
+		}
+		if fallback.BaseURL == "" {
+			fallback.BaseURL = fallback.defaultBaseURL()
+		}
+		if fallback.Model == "" {
+			fallback.Model = fallback.defaultModel()
+		}
+		if fallback.APIKey == "" {
+			fallback.APIKey, _ = envAPIKey(fallback.Provider)
+		}
+		if err := fallback.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid fallback provider %q: %w", name, err)
+		}
+		fallbacks = append(fallbacks, &fallback)
+	}
+	return fallbacks, nil
+}
+
+// Validate checks the settings for values that cannot work, returning an
+// error naming the offending field
+func (c *Config) Validate() error {
+	if !ai.IsRegistered(c.Provider) {
+		return fmt.Errorf("invalid provider %q: must be one of %s", c.Provider, strings.Join(ai.Providers(), ", "))
+	}
+	for _, name := range c.FallbackProviders {
+		if _, ok := c.Profiles[name]; !ok && !ai.IsRegistered(name) {
+			return fmt.Errorf("invalid fallback_providers entry %q: must be a profile or one of %s", name, strings.Join(ai.Providers(), ", "))
+		}
+	}
+	if c.Provider == "azure" && c.BaseURL == "" {
+		return errors.New("invalid azure config: set azure_resource and azure_deployment, or base_url")
+	}
+	// An Azure deployment decides its model
+	if c.Model == "" && c.Provider != "azure" {
+		return errors.New("invalid model: must not be empty")
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid base_url %q: must be an absolute URL such as http://localhost:11434/api/generate", c.BaseURL)
+	}
+	if c.APIKeyFile != "" && c.APIKeyCommand != "" {
+		return errors.New("invalid api_key_file and api_key_command: set only one of them")
+	}
+	if c.ProxyURL != "" {
+		if u, err := url.Parse(c.ProxyURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid proxy_url %q: must be an absolute URL such as http://proxy.example.com:3128", c.ProxyURL)
+		}
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("invalid timeout_seconds %d: must not be negative", c.TimeoutSeconds)
+	}
+	if c.MaxDiffBytes < 0 {
+		return fmt.Errorf("invalid max_diff_bytes %d: must not be negative (0 means unlimited)", c.MaxDiffBytes)
+	}
+	if c.MaxPromptTokens < 0 {
+		return fmt.Errorf("invalid max_prompt_tokens %d: must not be negative (0 means unlimited)", c.MaxPromptTokens)
+	}
+
+	switch c.ClosingKeyword {
+	case "Closes", "Fixes", "Resolves":
+	default:
+		return fmt.Errorf("invalid closing_keyword %q: must be one of Closes, Fixes, Resolves", c.ClosingKeyword)
+	}
+
+	switch c.Style {
+	case "conventional", "gitmoji":
+	default:
+		return fmt.Errorf("invalid style %q: must be one of conventional, gitmoji", c.Style)
+	}
+
+	switch c.SubjectLengthMode {
+	case "warn", "truncate", "regenerate":
+	default:
+		return fmt.Errorf("invalid subject_length_mode %q: must be one of warn, truncate, regenerate", c.SubjectLengthMode)
+	}
+
+	switch c.RulesMergeStrategy {
+	case RulesMergeNearest, RulesMergeConcat:
+	default:
+		return fmt.Errorf("invalid rules_merge_strategy %q: must be one of nearest, concat", c.RulesMergeStrategy)
+	}
+
+	switch c.ConflictMarkers {
+	case "error", "strip":
+	default:
+		return fmt.Errorf("invalid conflict_markers %q: must be one of error, strip", c.ConflictMarkers)
+	}
+
+	switch c.SelfCheck {
+	case "off", "warn", "strict":
+	default:
+		return fmt.Errorf("invalid self_check %q: must be one of off, warn, strict", c.SelfCheck)
+	}
+	if c.MinConfidence < 0 || c.MinConfidence > 100 {
+		return fmt.Errorf("invalid min_confidence %d: must be between 0 and 100", c.MinConfidence)
+	}
+
+	if c.RetryBaseDelayMillis < 0 {
+		return fmt.Errorf("invalid retry_base_delay_ms %d: must not be negative", c.RetryBaseDelayMillis)
+	}
+
+	if c.Temperature < 0 || c.Temperature > 2 {
+		return fmt.Errorf("invalid temperature %g: must be between 0 and 2", c.Temperature)
+	}
+	if c.TopP < 0 || c.TopP > 1 {
+		return fmt.Errorf("invalid top_p %g: must be between 0 and 1", c.TopP)
+	}
+
+	if c.JitterMillis < 0 {
+		return fmt.Errorf("invalid jitter_millis %d: must not be negative", c.JitterMillis)
+	}
+
+	for _, t := range c.AllowedTypes {
+		if !conventionalType.MatchString(t) {
+			return fmt.Errorf("invalid allowed_types entry %q: must be lowercase letters, e.g. perf", t)
+		}
+	}
+
+	for _, coAuthor := range c.CoAuthors {
+		if _, err := ParseCoAuthor(coAuthor); err != nil {
+			return fmt.Errorf("invalid co_authors: %w", err)
+		}
+	}
+
+	if _, err := regexp.Compile(c.BranchTicketPattern); err != nil {
+		return fmt.Errorf("invalid branch_ticket_pattern %q: %w", c.BranchTicketPattern, err)
+	}
+	if _, err := template.New("ticket").Parse(c.BranchTicketTemplate); err != nil {
+		return fmt.Errorf("invalid branch_ticket_template %q: %w", c.BranchTicketTemplate, err)
+	}
+
+	for _, pattern := range c.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redact_patterns entry %q: %w", pattern, err)
+		}
+	}
+
+	for name := range c.ExtraHeaders {
+		if !headerName.MatchString(name) {
+			return fmt.Errorf("invalid extra_headers name %q", name)
+		}
+		// The request body is always JSON
+		if strings.EqualFold(name, "Content-Type") {
+			return fmt.Errorf("invalid extra_headers name %q: Content-Type can't be overridden", name)
+		}
+	}
+
+	// temperature and top_p replace the same keys in extra_options, so
+	// setting both would silently drop one
+	if _, ok := c.ExtraOptions["temperature"]; ok && c.Temperature != 0 {
+		return errors.New(`invalid extra_options: "temperature" is also set by temperature; set it in one place`)
+	}
+	if _, ok := c.ExtraOptions["top_p"]; ok && c.TopP != 0 {
+		return errors.New(`invalid extra_options: "top_p" is also set by top_p; set it in one place`)
+	}
+	return nil
+}
+
+// GlobalConfigPath returns the path of the user-level config file,
+// $XDG_CONFIG_HOME/generate-commit/config.json or
+// ~/.config/generate-commit/config.json. The file may not exist.
+func GlobalConfigPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "generate-commit", "config.json"), nil
+}
+
+// overlayConfig applies the fields set in the JSON data on top of config.
+// Empty values ("", null, [] and {}) are skipped so they inherit the
+// current value instead of clearing it.
+func overlayConfig(config *Config, data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key, value := range fields {
+		switch string(bytes.TrimSpace(value)) {
+		case `""`, "null", "[]", "{}":
+			delete(fields, key)
+		}
+	}
+
+	// Re-encoding the remaining fields lets encoding/json apply them
+	filtered, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(filtered, config); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return fmt.Errorf("invalid %s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		return err
+	}
+	return nil
+}
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when
+// azure_api_version is not set
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// defaultBaseURL returns the default API endpoint for the provider. For
+// azure it is built from the resource and deployment, and is empty unless
+// both are set.
+func (c *Config) defaultBaseURL() string {
+	switch c.Provider {
+	case "azure":
+		if c.AzureResource == "" || c.AzureDeployment == "" {
+			return ""
+		}
+		version := c.AzureAPIVersion
+		if version == "" {
+			version = DefaultAzureAPIVersion
+		}
+		return fmt.Sprintf("https://%s.openai.azure.com/openai/deployments/%s/chat/completions?api-version=%s",
+			c.AzureResource, url.PathEscape(c.AzureDeployment), url.QueryEscape(version))
+	case "openai":
+		return "https://api.openai.com/v1/chat/completions"
+	case "gemini":
+		return "https://generativelanguage.googleapis.com/v1beta"
+	}
+	return "http://localhost:11434/api/generate"
+}
+
+// defaultModel returns the default model for the provider. For azure it is
+// the deployment, which decides the model.
+func (c *Config) defaultModel() string {
+	switch c.Provider {
+	case "azure":
+		return c.AzureDeployment
+	case "openai":
+		return ai.DefaultOpenAIModel
+	case "gemini":
+		return ai.DefaultGeminiModel
+	}
+	return ai.DefaultOllamaModel
 }
 
 // GetTimeout returns the timeout as a time.Duration
@@ -56,13 +859,32 @@ func (c *Config) GetTimeout() time.Duration {
 	return time.Duration(c.TimeoutSeconds) * time.Second
 }
 
+// GetJitter returns the maximum startup jitter as a time.Duration
+func (c *Config) GetJitter() time.Duration {
+	return time.Duration(c.JitterMillis) * time.Millisecond
+}
+
+// GetRetryBaseDelay returns the first retry backoff as a time.Duration
+func (c *Config) GetRetryBaseDelay() time.Duration {
+	return time.Duration(c.RetryBaseDelayMillis) * time.Millisecond
+}
+
+// GetCacheTTL returns how long cached responses are reused as a
+// time.Duration
+func (c *Config) GetCacheTTL() time.Duration {
+	return time.Duration(c.CacheTTLMinutes) * time.Minute
+}
+
 // SaveDefaultConfig saves a default config file to the repo root
 func (c *ConfigLoader) SaveDefaultConfig(repoRoot string) error {
 	config := &Config{
+		Provider:       "ollama",
 		APIKey:         os.Getenv("OLLAMA_API_KEY"), // Pre-fill from env if available
-		Model:          "gpt-oss:120b",
+		Model:          ai.DefaultOllamaModel,
 		BaseURL:        "http://localhost:11434/api/generate",
 		TimeoutSeconds: 60,
+		MaxDiffBytes:   DefaultMaxDiffBytes,
+		MinConfidence:  70,
 	}
 
 	configPath := filepath.Join(repoRoot, ".commit-generator-config")
@@ -80,7 +902,7 @@ func (c *ConfigLoader) SaveDefaultConfig(repoRoot string) error {
 
 // ConfigExists checks if a config file already exists
 func (c *ConfigLoader) ConfigExists() (bool, error) {
-	repoRoot, err := findRepoRoot()
+	repoRoot, err := c.repoRoot()
 	if err != nil {
 		return false, err
 	}
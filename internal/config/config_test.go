@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -44,6 +46,46 @@ func TestLoadConfig(t *testing.T) {
 	if config.TimeoutSeconds != 60 {
 		t.Errorf("Expected default timeout 60, got %d", config.TimeoutSeconds)
 	}
+
+	if config.MaxDiffBytes != DefaultMaxDiffBytes {
+		t.Errorf("Expected default max diff bytes %d, got %d", DefaultMaxDiffBytes, config.MaxDiffBytes)
+	}
+}
+
+func TestLoadConfig_MaxDiffBytes(t *testing.T) {
+	tests := []struct {
+		name       string
+		configData string
+		expected   int
+	}{
+		{name: "Custom", configData: `{"max_diff_bytes": 2048}`, expected: 2048},
+		{name: "Unlimited", configData: `{"max_diff_bytes": 0}`, expected: 0},
+		{name: "Omitted", configData: `{"model": "llama3"}`, expected: DefaultMaxDiffBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.MaxDiffBytes != tt.expected {
+				t.Errorf("Expected max diff bytes %d, got %d", tt.expected, config.MaxDiffBytes)
+			}
+		})
+	}
 }
 
 func TestSaveAndLoadConfig(t *testing.T) {
@@ -127,3 +169,1159 @@ func TestConfigExists(t *testing.T) {
 		t.Error("Config should exist after saving")
 	}
 }
+
+func TestLoadConfig_ExtraOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create .git directory to make it a repo
+	if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	configData := `{"model": "llama3", "extra_options": {"num_ctx": 4096, "seed": 42}}`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(configData), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Change to temp directory
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	config, err := NewConfigLoader().LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if got := config.ExtraOptions["num_ctx"]; got != float64(4096) {
+		t.Errorf("Expected extra_options.num_ctx 4096, got %v", got)
+	}
+	if got := config.ExtraOptions["seed"]; got != float64(42) {
+		t.Errorf("Expected extra_options.seed 42, got %v", got)
+	}
+}
+
+func TestLoadConfig_OpenAIProviderDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create .git directory to make it a repo
+	if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	configData := `{"provider": "openai", "model": "gpt-4o-mini"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(configData), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Change to temp directory
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	config, err := NewConfigLoader().LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Provider != "openai" {
+		t.Errorf("Expected provider 'openai', got '%s'", config.Provider)
+	}
+	if config.BaseURL != "https://api.openai.com/v1/chat/completions" {
+		t.Errorf("Expected OpenAI default base URL, got '%s'", config.BaseURL)
+	}
+}
+
+func TestLoadConfig_DefaultModel(t *testing.T) {
+	tests := []struct {
+		name          string
+		configData    string
+		expectedModel string
+	}{
+		{name: "Ollama", configData: `{}`, expectedModel: "gpt-oss:120b"},
+		{name: "OpenAI", configData: `{"provider": "openai"}`, expectedModel: "gpt-4o-mini"},
+		{name: "Gemini", configData: `{"provider": "gemini"}`, expectedModel: "gemini-2.0-flash"},
+		{name: "Azure uses the deployment", configData: `{"provider": "azure", "azure_resource": "acme", "azure_deployment": "prod-4o"}`, expectedModel: "prod-4o"},
+		{name: "Explicit model", configData: `{"provider": "openai", "model": "gpt-4.1"}`, expectedModel: "gpt-4.1"},
+		{name: "Profile switching provider", configData: `{"profiles": {"cloud": {"provider": "gemini"}}, "active_profile": "cloud"}`, expectedModel: "gemini-2.0-flash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("HOME", t.TempDir())
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.Model != tt.expectedModel {
+				t.Errorf("Expected model %q, got %q", tt.expectedModel, config.Model)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_GeminiProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	configData := `{"provider": "gemini", "model": "gemini-2.0-flash"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(configData), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OLLAMA_API_KEY", "ollama-key")
+	t.Setenv("GEMINI_API_KEY", "gemini-key")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	config, source, err := NewConfigLoader().LoadConfigWithSource()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.BaseURL != "https://generativelanguage.googleapis.com/v1beta" {
+		t.Errorf("Expected Gemini default base URL, got '%s'", config.BaseURL)
+	}
+	if config.APIKey != "gemini-key" || source.APIKeySource != "GEMINI_API_KEY" {
+		t.Errorf("Expected the key from GEMINI_API_KEY, got '%s' from '%s'", config.APIKey, source.APIKeySource)
+	}
+}
+
+func TestLoadConfig_AzureProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		configData  string
+		expectedURL string
+		expectedErr string
+	}{
+		{
+			name:        "Default API version",
+			configData:  `{"provider": "azure", "azure_resource": "acme", "azure_deployment": "gpt-4o"}`,
+			expectedURL: "https://acme.openai.azure.com/openai/deployments/gpt-4o/chat/completions?api-version=" + DefaultAzureAPIVersion,
+		},
+		{
+			name:        "Custom API version",
+			configData:  `{"provider": "azure", "azure_resource": "acme", "azure_deployment": "gpt-4o", "azure_api_version": "2025-01-01-preview"}`,
+			expectedURL: "https://acme.openai.azure.com/openai/deployments/gpt-4o/chat/completions?api-version=2025-01-01-preview",
+		},
+		{
+			name:        "Explicit base URL",
+			configData:  `{"provider": "azure", "base_url": "https://gateway.example.com/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21"}`,
+			expectedURL: "https://gateway.example.com/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21",
+		},
+		{
+			name:        "Missing deployment",
+			configData:  `{"provider": "azure", "azure_resource": "acme"}`,
+			expectedErr: "invalid azure config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("OLLAMA_API_KEY", "")
+			t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.BaseURL != tt.expectedURL {
+				t.Errorf("Expected base URL %q, got %q", tt.expectedURL, config.BaseURL)
+			}
+			if config.APIKey != "azure-key" {
+				t.Errorf("Expected the key from AZURE_OPENAI_API_KEY, got %q", config.APIKey)
+			}
+		})
+	}
+}
+
+func TestConfig_Fallbacks(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	configData := `{
+		"api_key": "ollama-key",
+		"base_url": "http://gpu-box:11434/api/generate",
+		"profiles": {
+			"cloud": {"provider": "openai", "model": "gpt-4o-mini", "api_key": "cloud-key"},
+			"local": {"model": "llama3"}
+		},
+		"fallback_providers": ["cloud", "local", "gemini"]
+	}`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(configData), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OLLAMA_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "gemini-env-key")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	cfg, err := NewConfigLoader().LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	fallbacks, err := cfg.Fallbacks()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Config{
+		{Provider: "openai", APIKey: "cloud-key", Model: "gpt-4o-mini", BaseURL: "https://api.openai.com/v1/chat/completions", ActiveProfile: "cloud"},
+		{Provider: "ollama", APIKey: "ollama-key", Model: "llama3", BaseURL: "http://gpu-box:11434/api/generate", ActiveProfile: "local"},
+		{Provider: "gemini", APIKey: "gemini-env-key", Model: "gemini-2.0-flash", BaseURL: "https://generativelanguage.googleapis.com/v1beta"},
+	}
+	if len(fallbacks) != len(expected) {
+		t.Fatalf("Expected %d fallbacks, got %d", len(expected), len(fallbacks))
+	}
+	for i, fallback := range fallbacks {
+		got := Config{Provider: fallback.Provider, APIKey: fallback.APIKey, Model: fallback.Model, BaseURL: fallback.BaseURL, ActiveProfile: fallback.ActiveProfile}
+		if !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("Fallback %d: expected %+v, got %+v", i, expected[i], got)
+		}
+		if len(fallback.FallbackProviders) != 0 {
+			t.Errorf("Fallback %d: expected no nested fallbacks, got %v", i, fallback.FallbackProviders)
+		}
+	}
+
+	cfg.FallbackProviders = []string{"nowhere"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid fallback_providers entry") {
+		t.Errorf("Expected an invalid fallback error, got %v", err)
+	}
+}
+
+func TestLoadConfig_ClosingKeyword(t *testing.T) {
+	tests := []struct {
+		name        string
+		configData  string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "Default", configData: `{"issue_footer": true}`, expected: "Closes"},
+		{name: "Custom", configData: `{"issue_footer": true, "closing_keyword": "Resolves"}`, expected: "Resolves"},
+		{name: "Invalid", configData: `{"closing_keyword": "Kills"}`, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("Expected error for invalid closing_keyword, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.ClosingKeyword != tt.expected {
+				t.Errorf("Expected closing keyword %q, got %q", tt.expected, config.ClosingKeyword)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_PromptTemplate(t *testing.T) {
+	tests := []struct {
+		name         string
+		configData   string
+		templateFile string
+		expected     string
+	}{
+		{name: "None", configData: `{}`, expected: ""},
+		{name: "Config field", configData: `{"prompt_template": "Describe {{.Diff}}"}`, expected: "Describe {{.Diff}}"},
+		{name: "Template file", configData: `{}`, templateFile: "From file {{.Diff}}", expected: "From file {{.Diff}}"},
+		{name: "Config field wins", configData: `{"prompt_template": "Config {{.Diff}}"}`, templateFile: "File {{.Diff}}", expected: "Config {{.Diff}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			if tt.templateFile != "" {
+				if err := os.WriteFile(filepath.Join(tmpDir, PromptTemplateFile), []byte(tt.templateFile), 0644); err != nil {
+					t.Fatalf("Failed to write template: %v", err)
+				}
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.PromptTemplate != tt.expected {
+				t.Errorf("Expected prompt template %q, got %q", tt.expected, config.PromptTemplate)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_SelfCheck(t *testing.T) {
+	tests := []struct {
+		name               string
+		configData         string
+		expectedMode       string
+		expectedConfidence int
+		expectedErr        bool
+	}{
+		{name: "Defaults", configData: `{}`, expectedMode: "off", expectedConfidence: 70},
+		{name: "Custom", configData: `{"self_check": "strict", "min_confidence": 85}`, expectedMode: "strict", expectedConfidence: 85},
+		{name: "Zero confidence", configData: `{"self_check": "warn", "min_confidence": 0}`, expectedMode: "warn", expectedConfidence: 0},
+		{name: "Null confidence", configData: `{"min_confidence": null}`, expectedMode: "off", expectedConfidence: 70},
+		{name: "Invalid mode", configData: `{"self_check": "maybe"}`, expectedErr: true},
+		{name: "Invalid confidence", configData: `{"self_check": "warn", "min_confidence": 120}`, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.SelfCheck != tt.expectedMode {
+				t.Errorf("Expected self_check %q, got %q", tt.expectedMode, config.SelfCheck)
+			}
+			if config.MinConfidence != tt.expectedConfidence {
+				t.Errorf("Expected min_confidence %d, got %d", tt.expectedConfidence, config.MinConfidence)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_EnvPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(`{"model": "repo-model"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	explicitPath := filepath.Join(t.TempDir(), "ci-config.json")
+	if err := os.WriteFile(explicitPath, []byte(`{"model": "ci-model"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	t.Run("Override", func(t *testing.T) {
+		t.Setenv(ConfigPathEnv, explicitPath)
+
+		config, err := NewConfigLoader().LoadConfig()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if config.Model != "ci-model" {
+			t.Errorf("Expected model from %s, got %q", ConfigPathEnv, config.Model)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		t.Setenv(ConfigPathEnv, filepath.Join(tmpDir, "missing.json"))
+
+		if _, err := NewConfigLoader().LoadConfig(); err == nil {
+			t.Fatal("Expected error for missing config file, got nil")
+		}
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		t.Setenv(ConfigPathEnv, "")
+
+		config, err := NewConfigLoader().LoadConfig()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if config.Model != "repo-model" {
+			t.Errorf("Expected model from repo config, got %q", config.Model)
+		}
+	})
+}
+
+func TestLoadConfig_GlobalMerge(t *testing.T) {
+	tests := []struct {
+		name            string
+		globalData      string
+		repoData        string
+		expectedKey     string
+		expectedModel   string
+		expectedTimeout int
+		expectedPattern []string
+	}{
+		{
+			name:            "Global only",
+			globalData:      `{"api_key": "global-key", "model": "global-model"}`,
+			expectedKey:     "global-key",
+			expectedModel:   "global-model",
+			expectedTimeout: 60,
+		},
+		{
+			name:            "Repo overrides field by field",
+			globalData:      `{"api_key": "global-key", "model": "global-model", "timeout_seconds": 30}`,
+			repoData:        `{"model": "repo-model"}`,
+			expectedKey:     "global-key",
+			expectedModel:   "repo-model",
+			expectedTimeout: 30,
+		},
+		{
+			name:            "Empty repo values inherit",
+			globalData:      `{"api_key": "global-key", "model": "global-model", "test_patterns": ["*_spec.rb"]}`,
+			repoData:        `{"api_key": "", "model": null, "test_patterns": [], "timeout_seconds": 90}`,
+			expectedKey:     "global-key",
+			expectedModel:   "global-model",
+			expectedTimeout: 90,
+			expectedPattern: []string{"*_spec.rb"},
+		},
+		{
+			name:            "Repo only",
+			repoData:        `{"api_key": "repo-key"}`,
+			expectedKey:     "repo-key",
+			expectedModel:   "gpt-oss:120b",
+			expectedTimeout: 60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configHome := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", configHome)
+			t.Setenv("OLLAMA_API_KEY", "")
+			if tt.globalData != "" {
+				globalDir := filepath.Join(configHome, "generate-commit")
+				if err := os.MkdirAll(globalDir, 0755); err != nil {
+					t.Fatalf("Failed to create global config dir: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(globalDir, "config.json"), []byte(tt.globalData), 0644); err != nil {
+					t.Fatalf("Failed to write global config: %v", err)
+				}
+			}
+
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if tt.repoData != "" {
+				if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.repoData), 0644); err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.APIKey != tt.expectedKey {
+				t.Errorf("Expected api_key %q, got %q", tt.expectedKey, config.APIKey)
+			}
+			if config.Model != tt.expectedModel {
+				t.Errorf("Expected model %q, got %q", tt.expectedModel, config.Model)
+			}
+			if config.TimeoutSeconds != tt.expectedTimeout {
+				t.Errorf("Expected timeout %d, got %d", tt.expectedTimeout, config.TimeoutSeconds)
+			}
+			if len(config.TestPatterns) != len(tt.expectedPattern) {
+				t.Errorf("Expected test_patterns %v, got %v", tt.expectedPattern, config.TestPatterns)
+			}
+		})
+	}
+}
+
+func TestGlobalConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	path, err := GlobalConfigPath()
+	if err != nil {
+		t.Fatalf("Failed to get global config path: %v", err)
+	}
+	if path != filepath.Join("/xdg", "generate-commit", "config.json") {
+		t.Errorf("Unexpected global config path %q", path)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/dev")
+	path, err = GlobalConfigPath()
+	if err != nil {
+		t.Fatalf("Failed to get global config path: %v", err)
+	}
+	if path != filepath.Join("/home/dev", ".config", "generate-commit", "config.json") {
+		t.Errorf("Unexpected global config path %q", path)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			Provider:           "ollama",
+			Model:              "gpt-oss:120b",
+			BaseURL:            "http://localhost:11434/api/generate",
+			TimeoutSeconds:     60,
+			ClosingKeyword:     "Closes",
+			SelfCheck:          "off",
+			MinConfidence:      70,
+			Style:              "conventional",
+			SubjectLengthMode:  "warn",
+			RulesMergeStrategy: "nearest",
+			ConflictMarkers:    "error",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(c *Config)
+		expectedErr string
+	}{
+		{name: "Valid", modify: func(c *Config) {}},
+		{name: "Zero timeout", modify: func(c *Config) { c.TimeoutSeconds = 0 }},
+		{name: "Proxy URL", modify: func(c *Config) { c.ProxyURL = "http://proxy.example.com:3128" }},
+		{name: "Proxy without scheme", modify: func(c *Config) { c.ProxyURL = "proxy.example.com:3128" }, expectedErr: "proxy_url"},
+		{name: "Negative timeout", modify: func(c *Config) { c.TimeoutSeconds = -5 }, expectedErr: "timeout_seconds"},
+		{name: "Empty model", modify: func(c *Config) { c.Model = "" }, expectedErr: "model"},
+		{name: "Unparseable base URL", modify: func(c *Config) { c.BaseURL = "http://[::1" }, expectedErr: "base_url"},
+		{name: "Relative base URL", modify: func(c *Config) { c.BaseURL = "localhost/api" }, expectedErr: "base_url"},
+		{name: "Unknown provider", modify: func(c *Config) { c.Provider = "acme" }, expectedErr: "provider"},
+		{name: "Negative max diff bytes", modify: func(c *Config) { c.MaxDiffBytes = -1 }, expectedErr: "max_diff_bytes"},
+		{name: "Negative max prompt tokens", modify: func(c *Config) { c.MaxPromptTokens = -1 }, expectedErr: "max_prompt_tokens"},
+		{name: "Negative jitter", modify: func(c *Config) { c.JitterMillis = -1 }, expectedErr: "jitter_millis"},
+		{name: "Negative retry delay", modify: func(c *Config) { c.RetryBaseDelayMillis = -1 }, expectedErr: "retry_base_delay_ms"},
+		{name: "Retries disabled", modify: func(c *Config) { c.MaxRetries = -1 }},
+		{name: "Temperature too high", modify: func(c *Config) { c.Temperature = 2.5 }, expectedErr: "temperature"},
+		{name: "Negative top_p", modify: func(c *Config) { c.TopP = -0.1 }, expectedErr: "top_p"},
+		{name: "Sampling options", modify: func(c *Config) { c.Temperature = 0.2; c.TopP = 0.9 }},
+		{name: "Gitmoji style", modify: func(c *Config) { c.Style = "gitmoji" }},
+		{name: "Unknown style", modify: func(c *Config) { c.Style = "emoji" }, expectedErr: "style"},
+		{name: "Truncate long subjects", modify: func(c *Config) { c.SubjectLengthMode = "truncate"; c.MaxSubjectLength = 50 }},
+		{name: "Unknown subject length mode", modify: func(c *Config) { c.SubjectLengthMode = "shorten" }, expectedErr: "subject_length_mode"},
+		{name: "Concatenated rules", modify: func(c *Config) { c.RulesMergeStrategy = "concat" }},
+		{name: "Custom allowed types", modify: func(c *Config) { c.AllowedTypes = []string{"feat", "fix", "perf", "ci"} }},
+		{name: "Capitalized allowed type", modify: func(c *Config) { c.AllowedTypes = []string{"Feat"} }, expectedErr: "allowed_types"},
+		{name: "Allowed type with scope", modify: func(c *Config) { c.AllowedTypes = []string{"feat(api)"} }, expectedErr: "allowed_types"},
+		{name: "Strip conflict markers", modify: func(c *Config) { c.ConflictMarkers = "strip" }},
+		{name: "Unknown conflict markers mode", modify: func(c *Config) { c.ConflictMarkers = "keep" }, expectedErr: "conflict_markers"},
+		{name: "Unknown rules merge strategy", modify: func(c *Config) { c.RulesMergeStrategy = "merge" }, expectedErr: "rules_merge_strategy"},
+		{name: "Branch ticket", modify: func(c *Config) {
+			c.BranchTicketPattern = `([A-Z]+-\d+)`
+			c.BranchTicketTemplate = "{{.Message}}\n\nRefs: {{.Ticket}}"
+		}},
+		{name: "Invalid branch ticket pattern", modify: func(c *Config) { c.BranchTicketPattern = "([A-Z]+" }, expectedErr: "branch_ticket_pattern"},
+		{name: "Invalid branch ticket template", modify: func(c *Config) { c.BranchTicketTemplate = "[{{.Ticket}] {{.Message}}" }, expectedErr: "branch_ticket_template"},
+		{name: "Co-authors", modify: func(c *Config) { c.CoAuthors = []string{"Ada Lovelace <ada@example.com>"} }},
+		{name: "Malformed co-author", modify: func(c *Config) { c.CoAuthors = []string{"Ada Lovelace"} }, expectedErr: "co_authors"},
+		{name: "Extra options", modify: func(c *Config) { c.ExtraOptions = map[string]any{"num_ctx": 8192, "temperature": 0.1} }},
+		{name: "Extra options clashing with temperature", modify: func(c *Config) { c.Temperature = 0.2; c.ExtraOptions = map[string]any{"temperature": 0.1} }, expectedErr: "extra_options"},
+		{name: "Extra options clashing with top_p", modify: func(c *Config) { c.TopP = 0.9; c.ExtraOptions = map[string]any{"top_p": 0.5} }, expectedErr: "extra_options"},
+		{name: "Extra headers", modify: func(c *Config) { c.ExtraHeaders = map[string]string{"X-Org-Id": "acme", "Authorization": "Token abc"} }},
+		{name: "Invalid extra header name", modify: func(c *Config) { c.ExtraHeaders = map[string]string{"X Org": "acme"} }, expectedErr: "extra_headers"},
+		{name: "Extra header overriding Content-Type", modify: func(c *Config) { c.ExtraHeaders = map[string]string{"content-type": "text/plain"} }, expectedErr: "Content-Type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.modify(config)
+
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error naming %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_WrongFieldType(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(`{"timeout_seconds": "60"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	_, err := NewConfigLoader().LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "invalid timeout_seconds: expected int, got string") {
+		t.Errorf("Expected error naming timeout_seconds, got %v", err)
+	}
+}
+
+func TestLoadConfig_Profiles(t *testing.T) {
+	const profiles = `{
+		"model": "flat-model",
+		"api_key": "flat-key",
+		"active_profile": "local",
+		"profiles": {
+			"local": {"model": "llama3"},
+			"cloud": {"provider": "openai", "model": "gpt-4o-mini", "api_key": "cloud-key"}
+		}
+	}`
+
+	tests := []struct {
+		name             string
+		configData       string
+		profile          string
+		expectedProvider string
+		expectedModel    string
+		expectedKey      string
+		expectedBaseURL  string
+		expectedErr      bool
+	}{
+		{
+			name:             "Active profile",
+			configData:       profiles,
+			expectedProvider: "ollama",
+			expectedModel:    "llama3",
+			expectedKey:      "flat-key",
+			expectedBaseURL:  "http://localhost:11434/api/generate",
+		},
+		{
+			name:             "Selected profile overrides active",
+			configData:       profiles,
+			profile:          "cloud",
+			expectedProvider: "openai",
+			expectedModel:    "gpt-4o-mini",
+			expectedKey:      "cloud-key",
+			expectedBaseURL:  "https://api.openai.com/v1/chat/completions",
+		},
+		{
+			name:             "Provider switch doesn't inherit the flat key or model",
+			configData:       `{"provider": "ollama", "model": "gpt-oss:120b", "api_key": "ollama-secret", "profiles": {"cloud": {"provider": "openai"}}}`,
+			profile:          "cloud",
+			expectedProvider: "openai",
+			expectedModel:    "gpt-4o-mini",
+			expectedKey:      "",
+			expectedBaseURL:  "https://api.openai.com/v1/chat/completions",
+		},
+		{
+			name:        "Unknown profile",
+			configData:  profiles,
+			profile:     "missing",
+			expectedErr: true,
+		},
+		{
+			name:             "Flat schema without profiles",
+			configData:       `{"model": "flat-model", "api_key": "flat-key", "base_url": "http://gpu:11434/api/generate"}`,
+			expectedProvider: "ollama",
+			expectedModel:    "flat-model",
+			expectedKey:      "flat-key",
+			expectedBaseURL:  "http://gpu:11434/api/generate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("OLLAMA_API_KEY", "")
+			t.Setenv("OPENAI_API_KEY", "")
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			loader := NewConfigLoader()
+			loader.Profile = tt.profile
+			config, err := loader.LoadConfig()
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.Provider != tt.expectedProvider {
+				t.Errorf("Expected provider %q, got %q", tt.expectedProvider, config.Provider)
+			}
+			if config.Model != tt.expectedModel {
+				t.Errorf("Expected model %q, got %q", tt.expectedModel, config.Model)
+			}
+			if config.APIKey != tt.expectedKey {
+				t.Errorf("Expected api_key %q, got %q", tt.expectedKey, config.APIKey)
+			}
+			if config.BaseURL != tt.expectedBaseURL {
+				t.Errorf("Expected base_url %q, got %q", tt.expectedBaseURL, config.BaseURL)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Overrides(t *testing.T) {
+	tests := []struct {
+		name            string
+		configData      string
+		env             map[string]string
+		overrides       Overrides
+		expectedModel   string
+		expectedBaseURL string
+		expectedTimeout int
+		expectedErr     bool
+	}{
+		{
+			name:            "Defaults",
+			configData:      `{}`,
+			expectedModel:   "gpt-oss:120b",
+			expectedBaseURL: "http://localhost:11434/api/generate",
+			expectedTimeout: 60,
+		},
+		{
+			name:            "File over default",
+			configData:      `{"model": "llama3", "base_url": "http://localhost:11434/api/generate", "timeout_seconds": 30}`,
+			expectedModel:   "llama3",
+			expectedBaseURL: "http://localhost:11434/api/generate",
+			expectedTimeout: 30,
+		},
+		{
+			name:       "Env over file",
+			configData: `{"model": "llama3", "base_url": "http://localhost:11434/api/generate", "timeout_seconds": 30}`,
+			env: map[string]string{
+				ModelEnv:   "mistral",
+				BaseURLEnv: "http://gpu-box:11434/api/generate",
+				TimeoutEnv: "120",
+			},
+			expectedModel:   "mistral",
+			expectedBaseURL: "http://gpu-box:11434/api/generate",
+			expectedTimeout: 120,
+		},
+		{
+			name:       "Flag over env",
+			configData: `{"model": "llama3", "timeout_seconds": 30}`,
+			env: map[string]string{
+				ModelEnv:   "mistral",
+				TimeoutEnv: "120",
+			},
+			overrides:       Overrides{Model: "codellama", BaseURL: "http://other:11434/api/generate", TimeoutSeconds: 5},
+			expectedModel:   "codellama",
+			expectedBaseURL: "http://other:11434/api/generate",
+			expectedTimeout: 5,
+		},
+		{
+			name:            "Env over profile",
+			configData:      `{"active_profile": "local", "profiles": {"local": {"model": "llama3"}}}`,
+			env:             map[string]string{ModelEnv: "mistral"},
+			expectedModel:   "mistral",
+			expectedBaseURL: "http://localhost:11434/api/generate",
+			expectedTimeout: 60,
+		},
+		{
+			name:        "Invalid env timeout",
+			configData:  `{}`,
+			env:         map[string]string{TimeoutEnv: "soon"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			for _, key := range []string{ModelEnv, BaseURLEnv, TimeoutEnv} {
+				t.Setenv(key, tt.env[key])
+			}
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			loader := NewConfigLoader()
+			loader.Overrides = tt.overrides
+			config, err := loader.LoadConfig()
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if config.Model != tt.expectedModel {
+				t.Errorf("Expected model %q, got %q", tt.expectedModel, config.Model)
+			}
+			if config.BaseURL != tt.expectedBaseURL {
+				t.Errorf("Expected base_url %q, got %q", tt.expectedBaseURL, config.BaseURL)
+			}
+			if config.TimeoutSeconds != tt.expectedTimeout {
+				t.Errorf("Expected timeout_seconds %d, got %d", tt.expectedTimeout, config.TimeoutSeconds)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_CACertFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		configData string
+		expected   func(repoRoot string) string
+	}{
+		{name: "Unset", configData: `{}`, expected: func(string) string { return "" }},
+		{name: "Relative to the repo root", configData: `{"ca_cert_file": "certs/ca.pem"}`, expected: func(root string) string { return filepath.Join(root, "certs", "ca.pem") }},
+		{name: "Absolute", configData: `{"ca_cert_file": "/etc/ssl/internal-ca.pem"}`, expected: func(string) string { return "/etc/ssl/internal-ca.pem" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if expected := tt.expected(tmpDir); config.CACertFile != expected {
+				t.Errorf("Expected ca_cert_file %q, got %q", expected, config.CACertFile)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_LogFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		configData string
+		expected   func(repoRoot string) string
+	}{
+		{name: "Unset", configData: `{}`, expected: func(string) string { return "" }},
+		{name: "Relative to the repo root", configData: `{"log_file": "logs/messages.jsonl"}`, expected: func(root string) string { return filepath.Join(root, "logs", "messages.jsonl") }},
+		{name: "Absolute", configData: `{"log_file": "/var/log/commits.jsonl"}`, expected: func(string) string { return "/var/log/commits.jsonl" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, ".commit-generator-config"), []byte(tt.configData), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			subDir := filepath.Join(tmpDir, "internal")
+			if err := os.Mkdir(subDir, 0755); err != nil {
+				t.Fatalf("Failed to create subdirectory: %v", err)
+			}
+
+			// Loading from a subdirectory still resolves against the root
+			oldDir, _ := os.Getwd()
+			os.Chdir(subDir)
+			defer os.Chdir(oldDir)
+
+			config, err := NewConfigLoader().LoadConfig()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if expected := tt.expected(tmpDir); config.LogFile != expected {
+				t.Errorf("Expected log_file %q, got %q", expected, config.LogFile)
+			}
+		})
+	}
+}
+
+func TestMaskAPIKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "sk-abcdef123456", expected: "sk-****"},
+		{key: "abcdefg", expected: "abc****"},
+		{key: "short", expected: "****"},
+		{key: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := MaskAPIKey(tt.key); got != tt.expected {
+				t.Errorf("MaskAPIKey(%q) = %q, expected %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_Masked(t *testing.T) {
+	config := &Config{
+		APIKey:   "sk-secret-flat",
+		Profiles: map[string]Profile{"cloud": {APIKey: "sk-secret-cloud"}},
+	}
+
+	masked := config.Masked()
+	if masked.APIKey != "sk-****" || masked.Profiles["cloud"].APIKey != "sk-****" {
+		t.Errorf("Expected masked keys, got %q and %q", masked.APIKey, masked.Profiles["cloud"].APIKey)
+	}
+	if config.APIKey != "sk-secret-flat" || config.Profiles["cloud"].APIKey != "sk-secret-cloud" {
+		t.Error("Expected the original config to be unchanged")
+	}
+}
+
+func TestLoadConfigWithSource(t *testing.T) {
+	tests := []struct {
+		name              string
+		configData        string
+		env               string
+		expectedKeySource string
+		expectedPath      bool
+	}{
+		{name: "Key from file", configData: `{"api_key": "file-key"}`, expectedKeySource: "file", expectedPath: true},
+		{name: "Key from env", configData: `{"model": "m"}`, env: "env-key", expectedKeySource: "OLLAMA_API_KEY", expectedPath: true},
+		{name: "No file", env: "env-key", expectedKeySource: "OLLAMA_API_KEY"},
+		{name: "No key", expectedKeySource: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("OLLAMA_API_KEY", tt.env)
+			tmpDir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+				t.Fatalf("Failed to create .git dir: %v", err)
+			}
+			configPath := filepath.Join(tmpDir, ".commit-generator-config")
+			if tt.configData != "" {
+				if err := os.WriteFile(configPath, []byte(tt.configData), 0644); err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+			}
+
+			oldDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(oldDir)
+
+			_, source, err := NewConfigLoader().LoadConfigWithSource()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			expectedKeySource := tt.expectedKeySource
+			if expectedKeySource == "file" {
+				expectedKeySource = source.Path
+			}
+			if source.APIKeySource != expectedKeySource {
+				t.Errorf("Expected key source %q, got %q", expectedKeySource, source.APIKeySource)
+			}
+			if got := source.Path != ""; got != tt.expectedPath {
+				t.Errorf("Expected config path set %v, got %q", tt.expectedPath, source.Path)
+			}
+			if tt.expectedPath && filepath.Base(source.Path) != ".commit-generator-config" {
+				t.Errorf("Expected repo config path, got %q", source.Path)
+			}
+		})
+	}
+}
+
+func TestLoaders_Dir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(ConfigPathEnv, "")
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+
+	repo := t.TempDir()
+	subDir := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("failed to create dirs: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git dir: %v", err)
+	}
+	files := map[string]string{
+		".commit-generator-config":              `{"model": "repo-model"}`,
+		".git-commit-rules-for-ai":              "Root rule\n",
+		"services/api/.git-commit-rules-for-ai": "API rule\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	configLoader := &ConfigLoader{Dir: subDir}
+	cfg, err := configLoader.LoadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Model != "repo-model" {
+		t.Errorf("expected the config from %s, got model %q", repo, cfg.Model)
+	}
+	exists, err := configLoader.ConfigExists()
+	if err != nil || !exists {
+		t.Errorf("expected the config to exist, got %v, %v", exists, err)
+	}
+
+	rules, err := (&FileLoader{Dir: subDir}).LoadRules()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules != "API rule\n" {
+		t.Errorf("expected the rules from %s, got %q", subDir, rules)
+	}
+
+	if wd, err := os.Getwd(); err != nil || wd != originalWd {
+		t.Errorf("expected the working directory to stay %s, got %s", originalWd, wd)
+	}
+}
+
+func TestFindRepoRoot_DotGitFile(t *testing.T) {
+	// repo/ holds a linked worktree at repo/.worktrees/feature and a
+	// submodule at repo/lib, both with .git files
+	repo := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write(".git/HEAD", "ref: refs/heads/main\n")
+	write(".git/worktrees/feature/HEAD", "ref: refs/heads/feature\n")
+	write(".worktrees/feature/.git", "gitdir: "+filepath.Join(repo, ".git/worktrees/feature")+"\n")
+	write(".worktrees/feature/src/main.go", "package main\n")
+	write(".git/modules/lib/HEAD", "ref: refs/heads/main\n")
+	write("lib/.git", "gitdir: ../.git/modules/lib\n")
+	write("lib/src/lib.go", "package lib\n")
+	write("broken/.git", "not a pointer\n")
+	write("dangling/.git", "gitdir: ../.git/modules/missing\n")
+
+	tests := []struct {
+		name        string
+		dir         string
+		expected    string
+		expectedErr string
+	}{
+		{name: "Main worktree", dir: ".", expected: "."},
+		{name: "Linked worktree", dir: ".worktrees/feature/src", expected: ".worktrees/feature"},
+		{name: "Submodule", dir: "lib/src", expected: "lib"},
+		{name: "No gitdir line", dir: "broken", expectedErr: `has no "gitdir:" line`},
+		{name: "Missing git dir", dir: "dangling", expectedErr: "points to a missing git dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := findRepoRoot(filepath.Join(repo, tt.dir))
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected := filepath.Join(repo, tt.expected); root != expected {
+				t.Errorf("expected root %s, got %s", expected, root)
+			}
+		})
+	}
+}
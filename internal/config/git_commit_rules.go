@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 )
 
@@ -11,11 +14,32 @@ type Loader interface {
 	LoadRules() (string, error)
 }
 
+// rulesFileName is the rules file looked up in each directory
+const rulesFileName = ".git-commit-rules-for-ai"
+
+// Strategies for combining the rules files found between the working
+// directory and the repo root (rules_merge_strategy)
+const (
+	// RulesMergeNearest uses only the rules file closest to the working
+	// directory (the default)
+	RulesMergeNearest = "nearest"
+	// RulesMergeConcat joins every rules file from the repo root down to
+	// the working directory, so the most specific rules come last
+	RulesMergeConcat = "concat"
+)
+
 // FileLoader implements the Loader interface
 type FileLoader struct {
-	cachedRepoRoot string
-	cachedRules    string
-	mu             sync.Mutex
+	// MergeStrategy is RulesMergeNearest or RulesMergeConcat. Empty uses
+	// RulesMergeNearest.
+	MergeStrategy string
+	// Dir is the directory rules are looked up from. Empty uses the
+	// current working directory.
+	Dir string
+
+	cachedDir   string
+	cachedRules string
+	mu          sync.Mutex
 }
 
 // NewLoader creates a new Config loader
@@ -23,63 +47,175 @@ func NewLoader() Loader {
 	return &FileLoader{}
 }
 
-// LoadRules reads the .git-commit-rules-for-ai file from the repo root.
-// It assumes the current working directory is the repo root (or we could look it up).
-// For simplicity and per requirements ("in the root of the git repository"),
-// we will try to find it in the current directory or traverse up?
-// The requirement says: "Look for a file named .git-commit-rules-for-ai in the root of the git repository."
-// Since the tool is likely run from the root, we check current dir.
-// We can also double check by finding the .git dir if needed, but 'internal/git' handles repo check.
-// We'll trust the user invokes it from within the repo.
+// LoadRules reads the .git-commit-rules-for-ai files in the working
+// directory and its parents up to the repo root, and combines them per
+// MergeStrategy. In a monorepo this lets each subproject have its own
+// rules. A .git-commit-rules-for-ai.yaml file is used instead of the plain
+// file in the same directory, rendered as text. Without a repo root, or
+// without any rules file, the rules are empty.
 func (c *FileLoader) LoadRules() (string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// 1. Try to find the root of the git repo.
-	repoRoot, err := findRepoRoot()
+	wd, err := workDir(c.Dir)
 	if err != nil {
-		// If we can't find repo root, we can't find the rules file there.
-		// Return empty, but maybe this isn't an error for the rules loader itself?
-		// The App will verify we are in a repo first.
-		// If we are, findRepoRoot should succeed.
-		return "", nil
+		return "", err
 	}
 
-	// Return cached rules if repo root hasn't changed
-	if c.cachedRepoRoot == repoRoot && c.cachedRules != "" {
+	// Return cached rules if the working directory hasn't changed
+	if c.cachedDir == wd && c.cachedRules != "" {
 		return c.cachedRules, nil
 	}
 
-	rulesPath := filepath.Join(repoRoot, ".git-commit-rules-for-ai")
-
-	content, err := os.ReadFile(rulesPath)
+	files, err := c.findRules(wd)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Cache empty result
-			c.cachedRepoRoot = repoRoot
-			c.cachedRules = ""
-			return "", nil // Optional file
-		}
 		return "", err
 	}
+	parts := make([]string, 0, len(files))
+	for _, file := range files {
+		if part := strings.TrimRight(file.text, "\n"); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	rules := strings.Join(parts, "\n\n")
+	if c.MergeStrategy != RulesMergeConcat && len(files) == 1 && files[0].structured == nil {
+		// The nearest plain file is used as written
+		rules = files[0].text
+	}
 
 	// Cache the result
-	c.cachedRepoRoot = repoRoot
-	c.cachedRules = string(content)
+	c.cachedDir = wd
+	c.cachedRules = rules
+	return rules, nil
+}
+
+// LoadStructuredRules returns the structured rules that apply in the
+// working directory, merged per MergeStrategy with the most specific file
+// winning, or nil if no .git-commit-rules-for-ai.yaml file applies.
+func (c *FileLoader) LoadStructuredRules() (*StructuredRules, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wd, err := workDir(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := c.findRules(wd)
+	if err != nil {
+		return nil, err
+	}
+	var merged *StructuredRules
+	for _, file := range files {
+		if file.structured == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &StructuredRules{}
+		}
+		merged = file.structured.overlay(merged)
+	}
+	return merged, nil
+}
 
-	return c.cachedRules, nil
+// rulesFile is the rules from one directory
+type rulesFile struct {
+	text string
+	// structured is set if the rules came from a YAML file
+	structured *StructuredRules
 }
 
-func findRepoRoot() (string, error) {
-	dir, err := os.Getwd()
+// findRules returns the rules files that apply in wd per MergeStrategy,
+// root first
+func (c *FileLoader) findRules(wd string) ([]rulesFile, error) {
+	// The App verifies we are in a repo first, so a missing root just
+	// means there are no rules
+	repoRoot, err := findRepoRoot(wd)
 	if err != nil {
-		return "", err
+		return nil, nil
 	}
 
+	// Nearest first
+	var found []rulesFile
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		file, ok, err := readRulesFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			found = append(found, file)
+		}
+		if dir == repoRoot || filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	switch c.MergeStrategy {
+	case "", RulesMergeNearest:
+		if len(found) > 1 {
+			found = found[:1]
+		}
+		return found, nil
+	case RulesMergeConcat:
+		slices.Reverse(found)
+		return found, nil
+	default:
+		return nil, fmt.Errorf("unknown rules_merge_strategy %q (expected nearest or concat)", c.MergeStrategy)
+	}
+}
+
+// readRulesFile reads the rules in dir, preferring the YAML file to the
+// plain one
+func readRulesFile(dir string) (rulesFile, bool, error) {
+	path := filepath.Join(dir, structuredRulesFileName)
+	if _, err := os.Stat(path); err == nil {
+		structured, err := loadStructuredRules(path, make(map[string]bool))
+		if err != nil {
+			return rulesFile{}, false, err
+		}
+		return rulesFile{text: structured.Text(), structured: structured}, true, nil
+	} else if !os.IsNotExist(err) {
+		return rulesFile{}, false, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, rulesFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rulesFile{}, false, nil
+		}
+		return rulesFile{}, false, err
+	}
+	return rulesFile{text: string(content)}, true, nil
+}
+
+// workDir returns dir as an absolute path, or the current working
+// directory if dir is empty
+func workDir(dir string) (string, error) {
+	if dir != "" {
+		return filepath.Abs(dir)
+	}
+	return os.Getwd()
+}
+
+// findRepoRoot returns the root of the working tree containing dir: the
+// closest directory at or above it with a .git entry, as go-git's
+// DetectDotGit finds it. In linked worktrees and submodules .git is a file
+// pointing to the repository with a "gitdir:" line; the directory holding
+// it is still the root, but the pointer must be valid.
+func findRepoRoot(dir string) (string, error) {
 	for {
-		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			if !info.IsDir() {
+				if _, err := readGitDirFile(dotGit); err != nil {
+					return "", err
+				}
+			}
 			return dir, nil
 		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
 		parent := filepath.Dir(dir)
 		if parent == dir {
 			return "", os.ErrNotExist
@@ -87,3 +223,25 @@ func findRepoRoot() (string, error) {
 		dir = parent
 	}
 }
+
+// readGitDirFile returns the git dir a .git file points to, relative
+// paths being relative to the file's directory
+func readGitDirFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	const prefix = "gitdir: "
+	line, _, _ := strings.Cut(string(data), "\n")
+	if !strings.HasPrefix(line, prefix) {
+		return "", fmt.Errorf("%s has no %q line", path, strings.TrimSpace(prefix))
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	if _, err := os.Stat(gitDir); err != nil {
+		return "", fmt.Errorf("%s points to a missing git dir: %w", path, err)
+	}
+	return gitDir, nil
+}
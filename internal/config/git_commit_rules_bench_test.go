@@ -62,9 +62,10 @@ func BenchmarkFindRepoRoot_Shallow(b *testing.B) {
 	os.Chdir(tempDir)
 	os.Mkdir(".git", 0755)
 
+	wd, _ := os.Getwd()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = findRepoRoot()
+		_, _ = findRepoRoot(wd)
 	}
 }
 
@@ -81,9 +82,10 @@ func BenchmarkFindRepoRoot_Deep(b *testing.B) {
 	// Create .git at root
 	os.Mkdir(filepath.Join(tempDir, ".git"), 0755)
 
+	wd, _ := os.Getwd()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = findRepoRoot()
+		_, _ = findRepoRoot(wd)
 	}
 }
 
@@ -100,8 +102,9 @@ func BenchmarkFindRepoRoot_VeryDeep(b *testing.B) {
 	// Create .git at root
 	os.Mkdir(filepath.Join(tempDir, ".git"), 0755)
 
+	wd, _ := os.Getwd()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = findRepoRoot()
+		_, _ = findRepoRoot(wd)
 	}
 }
@@ -95,3 +95,75 @@ func TestFileLoader_LoadRules(t *testing.T) {
 		}
 	})
 }
+
+func TestFileLoader_LoadRules_Nested(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get current working directory: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(originalWd); err != nil {
+			t.Errorf("failed to restore working directory: %v", err)
+		}
+	}()
+
+	// repo/.git-commit-rules-for-ai, repo/services/api/.git-commit-rules-for-ai,
+	// and repo/services/web without rules of its own
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git dir: %v", err)
+	}
+	for _, dir := range []string{"services/api/handlers", "services/web"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	writeRules := func(dir, content string) {
+		if err := os.WriteFile(filepath.Join(repo, dir, ".git-commit-rules-for-ai"), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write rules file: %v", err)
+		}
+	}
+	writeRules(".", "Root rule\n")
+	writeRules("services/api", "API rule\n")
+
+	tests := []struct {
+		name     string
+		dir      string
+		strategy string
+		expected string
+	}{
+		{name: "Root", dir: ".", strategy: RulesMergeNearest, expected: "Root rule\n"},
+		{name: "Nearest in a subproject", dir: "services/api", strategy: RulesMergeNearest, expected: "API rule\n"},
+		{name: "Nearest below a subproject", dir: "services/api/handlers", strategy: RulesMergeNearest, expected: "API rule\n"},
+		{name: "Nearest falls back to the root", dir: "services/web", strategy: RulesMergeNearest, expected: "Root rule\n"},
+		{name: "Default is nearest", dir: "services/api", strategy: "", expected: "API rule\n"},
+		{name: "Concat joins root first", dir: "services/api/handlers", strategy: RulesMergeConcat, expected: "Root rule\n\nAPI rule"},
+		{name: "Concat with one file", dir: "services/web", strategy: RulesMergeConcat, expected: "Root rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.Chdir(filepath.Join(repo, tt.dir)); err != nil {
+				t.Fatalf("failed to chdir: %v", err)
+			}
+
+			loader := &FileLoader{MergeStrategy: tt.strategy}
+			rules, err := loader.LoadRules()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rules != tt.expected {
+				t.Errorf("expected rules %q, got %q", tt.expected, rules)
+			}
+		})
+	}
+
+	t.Run("Unknown strategy", func(t *testing.T) {
+		if err := os.Chdir(repo); err != nil {
+			t.Fatalf("failed to chdir: %v", err)
+		}
+		if _, err := (&FileLoader{MergeStrategy: "merge"}).LoadRules(); err == nil {
+			t.Error("expected an error for an unknown strategy")
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// structuredRulesFileName is the structured rules file, preferred over the
+// plain text one in the same directory
+const structuredRulesFileName = rulesFileName + ".yaml"
+
+// StructuredRules are the rules from a .git-commit-rules-for-ai.yaml file.
+// Unlike the freeform text file, each field is checked when it is loaded.
+type StructuredRules struct {
+	// AllowedTypes limits the conventional commit types, e.g. feat and fix
+	AllowedTypes []string
+	// MaxSubjectLength is the longest subject line allowed; 0 sets no limit
+	MaxSubjectLength int
+	// RequireScope asks for a scope on every message
+	RequireScope bool
+	// ExtraInstructions are freeform rules passed to the model as written
+	ExtraInstructions string
+}
+
+// StructuredLoader is implemented by rules loaders that can return the
+// structured rules, for checks beyond the prompt
+type StructuredLoader interface {
+	// LoadStructuredRules returns nil if no structured rules file applies
+	LoadStructuredRules() (*StructuredRules, error)
+}
+
+// Text renders the rules as the bullet list sent to the model
+func (r *StructuredRules) Text() string {
+	var lines []string
+	if len(r.AllowedTypes) > 0 {
+		lines = append(lines, "- Use only these commit types: "+strings.Join(r.AllowedTypes, ", ")+".")
+	}
+	if r.RequireScope {
+		lines = append(lines, "- Always include a scope: type(scope): description.")
+	}
+	if r.MaxSubjectLength > 0 {
+		lines = append(lines, fmt.Sprintf("- Keep the subject line at most %d characters.", r.MaxSubjectLength))
+	}
+	if extra := strings.TrimSpace(r.ExtraInstructions); extra != "" {
+		lines = append(lines, extra)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// overlay returns base with the fields set in r replacing its own. Extra
+// instructions are added to the base ones instead.
+func (r *StructuredRules) overlay(base *StructuredRules) *StructuredRules {
+	merged := *base
+	if len(r.AllowedTypes) > 0 {
+		merged.AllowedTypes = r.AllowedTypes
+	}
+	if r.MaxSubjectLength > 0 {
+		merged.MaxSubjectLength = r.MaxSubjectLength
+	}
+	merged.RequireScope = merged.RequireScope || r.RequireScope
+	if extra := strings.TrimSpace(r.ExtraInstructions); extra != "" {
+		if merged.ExtraInstructions = strings.TrimSpace(merged.ExtraInstructions); merged.ExtraInstructions != "" {
+			merged.ExtraInstructions += "\n"
+		}
+		merged.ExtraInstructions += extra
+	}
+	return &merged
+}
+
+// loadStructuredRules reads a structured rules file and the files it
+// includes. Included files are read first, so the including file's fields
+// take precedence. Include paths are relative to the including file.
+func loadStructuredRules(path string, visiting map[string]bool) (*StructuredRules, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if visiting[abs] {
+		return nil, fmt.Errorf("%s includes itself", path)
+	}
+	visiting[abs] = true
+	defer delete(visiting, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, includes, err := parseStructuredRules(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	merged := &StructuredRules{}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		included, err := loadStructuredRules(include, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("failed to include %s: %w", include, err)
+		}
+		merged = included.overlay(merged)
+	}
+	return rules.overlay(merged), nil
+}
+
+// yamlRulesFile is the YAML layout of a structured rules file
+type yamlRulesFile struct {
+	AllowedTypes      []string    `yaml:"allowed_types"`
+	MaxSubjectLength  int         `yaml:"max_subject_length"`
+	RequireScope      bool        `yaml:"require_scope"`
+	ExtraInstructions string      `yaml:"extra_instructions"`
+	Include           includeList `yaml:"include"`
+}
+
+// includeList is the include field: one path or a list of them
+type includeList []string
+
+func (l *includeList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var path string
+		if err := value.Decode(&path); err != nil {
+			return err
+		}
+		if path != "" {
+			*l = includeList{path}
+		}
+		return nil
+	}
+	var paths []string
+	if err := value.Decode(&paths); err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		*l = paths
+	}
+	return nil
+}
+
+// parseStructuredRules parses a YAML rules file, rejecting fields it
+// doesn't know. It returns the rules and the paths to include.
+func parseStructuredRules(data string) (*StructuredRules, []string, error) {
+	var file yamlRulesFile
+	decoder := yaml.NewDecoder(strings.NewReader(data))
+	decoder.KnownFields(true)
+	// An empty file, or one with only comments, has no document
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+
+	for _, t := range file.AllowedTypes {
+		if t == "" || strings.ContainsAny(t, " :()!") {
+			return nil, nil, fmt.Errorf("invalid commit type %q in allowed_types", t)
+		}
+	}
+	if file.MaxSubjectLength < 0 {
+		return nil, nil, errors.New("max_subject_length must be a non-negative number")
+	}
+
+	rules := &StructuredRules{
+		AllowedTypes:      file.AllowedTypes,
+		MaxSubjectLength:  file.MaxSubjectLength,
+		RequireScope:      file.RequireScope,
+		ExtraInstructions: strings.TrimSpace(file.ExtraInstructions),
+	}
+	return rules, file.Include, nil
+}
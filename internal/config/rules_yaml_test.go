@@ -0,0 +1,282 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+func TestParseStructuredRules(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expected         *StructuredRules
+		expectedIncludes []string
+		expectedErr      string
+	}{
+		{
+			name: "All fields",
+			input: `# Team rules
+allowed_types: [feat, fix, "docs"]
+max_subject_length: 50
+require_scope: true # always
+extra_instructions: |
+  Write in the imperative mood.
+  Mention the ticket in the body.
+`,
+			expected: &StructuredRules{
+				AllowedTypes:      []string{"feat", "fix", "docs"},
+				MaxSubjectLength:  50,
+				RequireScope:      true,
+				ExtraInstructions: "Write in the imperative mood.\nMention the ticket in the body.",
+			},
+		},
+		{
+			name: "Block list and folded text",
+			input: `allowed_types:
+  - feat
+  - 'fix'
+extra_instructions: >
+  Keep it
+  short.
+`,
+			expected: &StructuredRules{
+				AllowedTypes:      []string{"feat", "fix"},
+				ExtraInstructions: "Keep it short.",
+			},
+		},
+		{
+			name:             "Includes",
+			input:            "include: [base.yaml, team.yaml]\nrequire_scope: no\n",
+			expected:         &StructuredRules{},
+			expectedIncludes: []string{"base.yaml", "team.yaml"},
+		},
+		{
+			name:             "Single include",
+			input:            "include: base.yaml\n",
+			expected:         &StructuredRules{},
+			expectedIncludes: []string{"base.yaml"},
+		},
+		{
+			name:     "Empty",
+			input:    "# nothing yet\n",
+			expected: &StructuredRules{},
+		},
+		{
+			name:     "Empty include",
+			input:    "include: []\nrequire_scope: yes\n",
+			expected: &StructuredRules{RequireScope: true},
+		},
+		{name: "Unknown field", input: "allowed_type: [feat]\n", expectedErr: "line 1: field allowed_type not found"},
+		{name: "Types not a list", input: "allowed_types: feat\n", expectedErr: "cannot unmarshal !!str `feat` into []string"},
+		{name: "Invalid type", input: "allowed_types: [feat(api)]\n", expectedErr: "invalid commit type"},
+		{name: "Invalid length", input: "max_subject_length: short\n", expectedErr: "cannot unmarshal !!str `short` into int"},
+		{name: "Negative length", input: "max_subject_length: -1\n", expectedErr: "max_subject_length must be a non-negative number"},
+		{name: "Invalid bool", input: "require_scope: maybe\n", expectedErr: "cannot unmarshal !!str `maybe` into bool"},
+		{name: "Duplicate field", input: "require_scope: true\nrequire_scope: false\n", expectedErr: `mapping key "require_scope" already defined`},
+		{name: "Unterminated list", input: "allowed_types: [feat, fix\n", expectedErr: "did not find expected ',' or ']'"},
+		{name: "Include not a path", input: "include: {base: team}\n", expectedErr: "cannot unmarshal !!map into []string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, includes, err := parseStructuredRules(tt.input)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rules, tt.expected) {
+				t.Errorf("expected rules %+v, got %+v", tt.expected, rules)
+			}
+			if !reflect.DeepEqual(includes, tt.expectedIncludes) {
+				t.Errorf("expected includes %v, got %v", tt.expectedIncludes, includes)
+			}
+		})
+	}
+}
+
+func TestStructuredRules_Text(t *testing.T) {
+	rules := &StructuredRules{
+		AllowedTypes:      []string{"feat", "fix"},
+		MaxSubjectLength:  50,
+		RequireScope:      true,
+		ExtraInstructions: "- Mention the ticket.\n",
+	}
+	expected := "- Use only these commit types: feat, fix.\n" +
+		"- Always include a scope: type(scope): description.\n" +
+		"- Keep the subject line at most 50 characters.\n" +
+		"- Mention the ticket."
+	if got := rules.Text(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := (&StructuredRules{}).Text(); got != "" {
+		t.Errorf("expected empty rules to render nothing, got %q", got)
+	}
+}
+
+func TestFileLoader_StructuredRules(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get current working directory: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(originalWd); err != nil {
+			t.Errorf("failed to restore working directory: %v", err)
+		}
+	}()
+
+	newRepo := func(t *testing.T, files map[string]string) string {
+		repo := t.TempDir()
+		if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+			t.Fatalf("failed to create .git dir: %v", err)
+		}
+		for name, content := range files {
+			path := filepath.Join(repo, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+		if err := os.Chdir(repo); err != nil {
+			t.Fatalf("failed to chdir: %v", err)
+		}
+		return repo
+	}
+
+	t.Run("YAML preferred over text", func(t *testing.T) {
+		newRepo(t, map[string]string{
+			".git-commit-rules-for-ai":      "Plain rule\n",
+			".git-commit-rules-for-ai.yaml": "allowed_types: [feat, fix]\n",
+		})
+		loader := &FileLoader{}
+		rules, err := loader.LoadRules()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rules != "- Use only these commit types: feat, fix." {
+			t.Errorf("unexpected rules %q", rules)
+		}
+		structured, err := loader.LoadStructuredRules()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if structured == nil || !reflect.DeepEqual(structured.AllowedTypes, []string{"feat", "fix"}) {
+			t.Errorf("unexpected structured rules %+v", structured)
+		}
+	})
+
+	t.Run("Falls back to text", func(t *testing.T) {
+		newRepo(t, map[string]string{".git-commit-rules-for-ai": "Plain rule\n"})
+		loader := &FileLoader{}
+		rules, err := loader.LoadRules()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rules != "Plain rule\n" {
+			t.Errorf("unexpected rules %q", rules)
+		}
+		structured, err := loader.LoadStructuredRules()
+		if err != nil || structured != nil {
+			t.Errorf("expected no structured rules, got %+v, %v", structured, err)
+		}
+	})
+
+	t.Run("Includes", func(t *testing.T) {
+		newRepo(t, map[string]string{
+			"rules/base.yaml": "allowed_types: [feat, fix, chore]\nmax_subject_length: 72\nextra_instructions: Base rule.\n",
+			".git-commit-rules-for-ai.yaml": "include: [rules/base.yaml]\n" +
+				"max_subject_length: 50\nextra_instructions: Team rule.\n",
+		})
+		structured, err := (&FileLoader{}).LoadStructuredRules()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := &StructuredRules{
+			AllowedTypes:      []string{"feat", "fix", "chore"},
+			MaxSubjectLength:  50,
+			ExtraInstructions: "Base rule.\nTeam rule.",
+		}
+		if !reflect.DeepEqual(structured, expected) {
+			t.Errorf("expected %+v, got %+v", expected, structured)
+		}
+	})
+
+	t.Run("Include cycle", func(t *testing.T) {
+		newRepo(t, map[string]string{
+			"a.yaml":                        "include: b.yaml\n",
+			"b.yaml":                        "include: a.yaml\n",
+			".git-commit-rules-for-ai.yaml": "include: a.yaml\n",
+		})
+		_, err := (&FileLoader{}).LoadRules()
+		if err == nil || !strings.Contains(err.Error(), "includes itself") {
+			t.Errorf("expected an include cycle error, got %v", err)
+		}
+	})
+
+	t.Run("Missing include", func(t *testing.T) {
+		newRepo(t, map[string]string{".git-commit-rules-for-ai.yaml": "include: missing.yaml\n"})
+		if _, err := (&FileLoader{}).LoadRules(); err == nil {
+			t.Error("expected an error for a missing include")
+		}
+	})
+
+	t.Run("Concat mixes YAML and text", func(t *testing.T) {
+		repo := newRepo(t, map[string]string{
+			".git-commit-rules-for-ai.yaml":         "require_scope: true\n",
+			"services/api/.git-commit-rules-for-ai": "API rule\n",
+		})
+		if err := os.Chdir(filepath.Join(repo, "services/api")); err != nil {
+			t.Fatalf("failed to chdir: %v", err)
+		}
+		loader := &FileLoader{MergeStrategy: RulesMergeConcat}
+		rules, err := loader.LoadRules()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "- Always include a scope: type(scope): description.\n\nAPI rule"
+		if rules != expected {
+			t.Errorf("expected %q, got %q", expected, rules)
+		}
+		structured, err := loader.LoadStructuredRules()
+		if err != nil || structured == nil || !structured.RequireScope {
+			t.Errorf("expected the root structured rules, got %+v, %v", structured, err)
+		}
+	})
+
+	t.Run("Shapes the prompt", func(t *testing.T) {
+		newRepo(t, map[string]string{
+			".git-commit-rules-for-ai.yaml": "allowed_types: [feat, fix]\nrequire_scope: true\n" +
+				"extra_instructions: Mention the ticket.\n",
+		})
+		rules, err := (&FileLoader{}).LoadRules()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client, err := ai.NewClient(ai.Options{})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		prompt := client.BuildPrompt("diff --git a/x b/x", rules)
+		for _, want := range []string{
+			"Team Rules:",
+			"- Use only these commit types: feat, fix.",
+			"- Always include a scope: type(scope): description.",
+			"Mention the ticket.",
+		} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("expected prompt to contain %q, got:\n%s", want, prompt)
+			}
+		}
+	})
+}
@@ -1,17 +1,27 @@
 package git
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-git/go-billy/v5/osfs"
 	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 )
 
 // Client defines the interface for git operations
@@ -19,8 +29,70 @@ type Client interface {
 	IsInsideRepo() (bool, error)
 	HasStagedChanges() (bool, error)
 	GetStagedDiff() (string, error)
-	CommitWithMessage(message string) error
+	GetStagedFiles() ([]StagedFile, error)
+	CommitWithMessage(message string, opts CommitOptions) error
+	AmendWithMessage(message string, opts CommitOptions) error
+	GetLastCommitMessage() (string, error)
+	GetRecentCommitSubjects(n int) ([]string, error)
 	GetRepoRoot() (string, error)
+	GetCurrentBranch() (string, error)
+	GetStagedPaths() ([]StagedPath, error)
+	StageFiles(paths []string) error
+	UnstageFiles(paths []string) error
+	GetHTTPProxy() (string, error)
+	GetUser() (name, email string, err error)
+	GetStagedDiffStats() ([]FileStat, error)
+	GetWorktreeDiff() (string, error)
+	GetRevisionDiff(from, to string) (string, error)
+	GetMergeBase(rev1, rev2 string) (string, error)
+	IsMidMergeOrRebase() (bool, error)
+	GitPath(name string) (string, error)
+}
+
+// CommitOptions adjusts how CommitWithMessage and AmendWithMessage commit
+type CommitOptions struct {
+	// SignOff appends a Signed-off-by trailer for the configured git user,
+	// like `git commit --signoff`
+	SignOff bool
+	// CoAuthors are "Name <email>" entries appended as Co-authored-by
+	// trailers, before any Signed-off-by
+	CoAuthors []string
+}
+
+// FileStatus is how a staged file changed
+type FileStatus string
+
+const (
+	StatusAdded    FileStatus = "added"
+	StatusModified FileStatus = "modified"
+	StatusDeleted  FileStatus = "deleted"
+	StatusRenamed  FileStatus = "renamed"
+	StatusCopied   FileStatus = "copied"
+)
+
+// StagedFile is a single staged file and its diff
+type StagedFile struct {
+	// Path is the repo-relative path of the file
+	Path string
+	// Status is how the file changed
+	Status FileStatus
+	// OldPath is the previous path of a renamed file, or the source of a
+	// copied one
+	OldPath string
+	// Diff is the file's section of the staged diff, starting with its
+	// "diff --git" header
+	Diff string
+}
+
+// StagedPath is a staged file as listed by GetStagedPaths
+type StagedPath struct {
+	// Path is the repo-relative path of the file
+	Path string
+	// Ignored is true when the path matches IgnoreFile
+	Ignored bool
+	// Unstaged is true when the working tree has further changes to the
+	// file that aren't staged, e.g. after `git add -p`
+	Unstaged bool
 }
 
 // ClientImpl implements the Client interface using go-git
@@ -28,20 +100,41 @@ type ClientImpl struct {
 	repo     *git.Repository
 	repoPath string
 	mu       sync.Mutex
+	// diffWorkers caps how many staged files are diffed at once; 0 uses
+	// GOMAXPROCS
+	diffWorkers int
+	// dir is the directory the repository is opened from; empty uses the
+	// current working directory
+	dir string
 }
 
-// NewClient creates a new Git client
+// NewClient creates a new Git client for the repository containing the
+// current working directory
 func NewClient() Client {
 	return &ClientImpl{}
 }
 
-// openRepo opens a git repository from the current working directory
+// NewClientAt creates a new Git client for the repository containing path,
+// without depending on the process working directory
+func NewClientAt(path string) Client {
+	return &ClientImpl{dir: path}
+}
+
+// workDir returns the directory the client works from
+func (c *ClientImpl) workDir() (string, error) {
+	if c.dir != "" {
+		return filepath.Abs(c.dir)
+	}
+	return os.Getwd()
+}
+
+// openRepo opens the git repository containing the client's directory.
 // Uses caching to avoid repeated opens
 func (c *ClientImpl) openRepo() (*git.Repository, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	wd, err := os.Getwd()
+	wd, err := c.workDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get working directory: %w", err)
 	}
@@ -51,8 +144,11 @@ func (c *ClientImpl) openRepo() (*git.Repository, error) {
 		return c.repo, nil
 	}
 
+	// In a linked worktree the objects and refs live in the main
+	// repository, which the worktree's git dir names in "commondir"
 	repo, err := git.PlainOpenWithOptions(wd, &git.PlainOpenOptions{
-		DetectDotGit: true,
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
 	})
 	if err != nil {
 		return nil, err
@@ -77,7 +173,9 @@ func (c *ClientImpl) IsInsideRepo() (bool, error) {
 	return true, nil
 }
 
-// HasStagedChanges checks if there are staged changes
+// HasStagedChanges checks if there are staged changes to files not
+// matching IgnoreFile. If only ignored files are staged it returns
+// ErrOnlyIgnored.
 func (c *ClientImpl) HasStagedChanges() (bool, error) {
 	repo, err := c.openRepo()
 	if err != nil {
@@ -94,9 +192,15 @@ func (c *ClientImpl) HasStagedChanges() (bool, error) {
 		return false, fmt.Errorf("failed to get status: %w", err)
 	}
 
+	ignore, err := readIgnoreFile(worktree.Filesystem.Root())
+	if err != nil {
+		return false, err
+	}
+
 	// Check if there are any staged changes
 	// Short-circuit: return immediately after finding first staged file
-	for _, fileStatus := range status {
+	onlyIgnored := false
+	for filePath, fileStatus := range status {
 		// Staged changes are files that have been added to the index
 		// but not yet committed. This includes:
 		// - Added files (Staging == Added)
@@ -104,44 +208,65 @@ func (c *ClientImpl) HasStagedChanges() (bool, error) {
 		// - Deleted files (Staging == Deleted)
 		// - Renamed files (Staging == Renamed)
 		// - Copied files (Staging == Copied)
-		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
-			return true, nil
+		if fileStatus.Staging == git.Unmodified || fileStatus.Staging == git.Untracked {
+			continue
 		}
+		if ignore.Ignored(filePath) {
+			onlyIgnored = true
+			continue
+		}
+		return true, nil
 	}
 
+	if onlyIgnored {
+		return false, ErrOnlyIgnored
+	}
 	return false, nil
 }
 
-// GetStagedDiff returns the diff of staged changes
+// GetStagedDiff returns the full diff of staged changes, with files in
+// path order so the same staged set always yields the same diff. Callers
+// are responsible for truncating it to fit the model's context.
 func (c *ClientImpl) GetStagedDiff() (string, error) {
+	files, err := c.GetStagedFiles()
+	if err != nil {
+		return "", err
+	}
+
+	size := 0
+	for _, file := range files {
+		size += len(file.Diff)
+	}
+	var diffBuilder strings.Builder
+	diffBuilder.Grow(size)
+	for _, file := range files {
+		diffBuilder.WriteString(file.Diff)
+	}
+	return diffBuilder.String(), nil
+}
+
+// GetStagedFiles returns each staged file with its status and diff, sorted
+// by path. Files matching IgnoreFile are left out.
+func (c *ClientImpl) GetStagedFiles() ([]StagedFile, error) {
 	repo, err := c.openRepo()
 	if err != nil {
-		return "", fmt.Errorf("failed to open repository: %w", err)
+		return nil, fmt.Errorf("failed to open repository: %w", err)
 	}
 
 	worktree, err := repo.Worktree()
 	if err != nil {
-		return "", fmt.Errorf("failed to get worktree: %w", err)
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
 	}
 
 	status, err := worktree.Status()
 	if err != nil {
-		return "", fmt.Errorf("failed to get status: %w", err)
+		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
-	// Pre-allocate builder capacity based on estimated diff size
-	// Estimate: ~100 bytes per file header + ~50 bytes per line
-	estimatedSize := len(status) * 500
-	var diffBuilder strings.Builder
-	diffBuilder.Grow(estimatedSize)
-
-	// Cache working directory
-	wd, _ := os.Getwd()
-
 	// Get HEAD commit for comparison
 	head, err := repo.Head()
 	if err != nil && err != plumbing.ErrReferenceNotFound {
-		return "", fmt.Errorf("failed to get HEAD: %w", err)
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
 	var headTree *object.Tree
@@ -150,157 +275,735 @@ func (c *ClientImpl) GetStagedDiff() (string, error) {
 		if err == nil {
 			headTree, err = headCommit.Tree()
 			if err != nil {
-				return "", fmt.Errorf("failed to get HEAD tree: %w", err)
+				return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
 			}
 		}
 	}
 
-	// Process each staged file
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	ignore, err := readIgnoreFile(worktree.Filesystem.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	// Only process staged changes, in path order so the diff is stable
+	var paths []string
 	for filePath, fileStatus := range status {
-		// Only process staged changes
-		if fileStatus.Staging == git.Unmodified || fileStatus.Staging == git.Untracked {
-			continue
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked && !ignore.Ignored(filePath) {
+			paths = append(paths, filePath)
 		}
+	}
+	sort.Strings(paths)
 
-		switch fileStatus.Staging {
-		case git.Added:
-			// New file - show all lines as additions
-			diffBuilder.WriteString("diff --git a/")
-			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString(" b/")
-			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\nnew file mode 100644\nindex 0000000..")
-			diffBuilder.WriteString(fileStatus.Extra)
-			diffBuilder.WriteString("\n--- /dev/null\n+++ b/")
-			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\n")
-
-			// Read file content
-			fullPath := filepath.Join(wd, filePath)
-			content, err := os.ReadFile(fullPath)
-			if err == nil {
-				lines := strings.Split(string(content), "\n")
-				for _, line := range lines {
-					diffBuilder.WriteString("+")
-					diffBuilder.WriteString(line)
-					diffBuilder.WriteString("\n")
-				}
+	// go-git reports a staged rename as a deletion and an addition, and a
+	// copy as an addition
+	headBlobs := &headReader{repo: repo, tree: headTree}
+	renames, err := detectRenames(headBlobs, idx, paths, status)
+	if err != nil {
+		return nil, err
+	}
+	copies, err := detectCopies(headBlobs, idx, paths, status, renames)
+	if err != nil {
+		return nil, err
+	}
+	for newPath, source := range copies {
+		status[newPath] = &git.FileStatus{Staging: git.Copied, Worktree: status[newPath].Worktree, Extra: source}
+	}
+	if len(renames) > 0 {
+		renamedFrom := make(map[string]bool, len(renames))
+		for _, oldPath := range renames {
+			renamedFrom[oldPath] = true
+		}
+		kept := paths[:0]
+		for _, filePath := range paths {
+			if renamedFrom[filePath] {
+				continue
+			}
+			if oldPath, ok := renames[filePath]; ok {
+				status[filePath] = &git.FileStatus{Staging: git.Renamed, Worktree: status[filePath].Worktree, Extra: oldPath}
 			}
+			kept = append(kept, filePath)
+		}
+		paths = kept
+	}
 
-		case git.Deleted:
-			// Deleted file
-			diffBuilder.WriteString("diff --git a/")
-			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString(" b/")
-			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\ndeleted file mode 100644\nindex ")
-			diffBuilder.WriteString(fileStatus.Extra)
-			diffBuilder.WriteString("..0000000\n--- a/")
-			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\n+++ /dev/null\n")
-
-			// Try to get content from HEAD
-			if headTree != nil {
-				entry, err := headTree.FindEntry(filePath)
-				if err == nil {
-					blob, err := repo.BlobObject(entry.Hash)
-					if err == nil {
-						reader, err := blob.Reader()
-						if err == nil {
-							content := make([]byte, blob.Size)
-							reader.Read(content)
-							reader.Close()
-							lines := strings.Split(string(content), "\n")
-							for _, line := range lines {
-								diffBuilder.WriteString("-")
-								diffBuilder.WriteString(line)
-								diffBuilder.WriteString("\n")
-							}
-						}
-					}
-				}
+	// Diff the files concurrently; each result keeps its path's position
+	results := make([]StagedFile, len(paths))
+	ok := make([]bool, len(paths))
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(c.workers(), len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], ok[i], errs[i] = stagedFile(headBlobs, idx, paths[i], status[paths[i]])
 			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var files []StagedFile
+	for i, file := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if ok[i] {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// workers returns how many staged files are diffed at once
+func (c *ClientImpl) workers() int {
+	if c.diffWorkers > 0 {
+		return c.diffWorkers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// headReader reads blobs from the HEAD tree and the index. go-git trees
+// load and cache subtrees lazily, and the object storage opens packfiles
+// and their indexes lazily, so tree and object lookups are serialized to
+// share them between workers. Reading a blob's content opens its own
+// file, so workers read and diff blobs in parallel.
+type headReader struct {
+	mu   sync.Mutex
+	repo *git.Repository
+	tree *object.Tree
+}
 
-		case git.Modified:
-			// Modified file - get diff between HEAD and staged version
-			diffBuilder.WriteString("diff --git a/")
+// blobHash returns the hash of path's blob in HEAD
+func (h *headReader) blobHash(path string) plumbing.Hash {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return headBlobHash(h.tree, path)
+}
+
+// mode returns path's mode in HEAD, or filemode.Empty if it isn't there
+func (h *headReader) mode(path string) filemode.FileMode {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return headMode(h.tree, path)
+}
+
+// pathsByHash returns the first path in HEAD, in tree order, holding each
+// blob
+func (h *headReader) pathsByHash() (map[plumbing.Hash]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	paths := make(map[plumbing.Hash]string)
+	if h.tree == nil {
+		return paths, nil
+	}
+	walker := object.NewTreeWalker(h.tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			return paths, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read HEAD tree: %w", err)
+		}
+		if _, ok := paths[entry.Hash]; entry.Mode.IsFile() && !ok {
+			paths[entry.Hash] = name
+		}
+	}
+}
+
+// content returns path's content in HEAD, or nil if it isn't there
+func (h *headReader) content(path string) ([]byte, error) {
+	blob, err := h.headBlob(path)
+	if blob == nil || err != nil {
+		return nil, err
+	}
+	content, err := readBlob(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+	}
+	return content, nil
+}
+
+// headBlob looks up path's blob in HEAD, returning nil if it isn't there
+func (h *headReader) headBlob(path string) (*object.Blob, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.tree == nil {
+		return nil, nil
+	}
+	entry, err := h.tree.FindEntry(path)
+	if err != nil && missingFromTree(h.tree, path, err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s in HEAD: %w", path, err)
+	}
+	blob, err := h.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+	}
+	return blob, nil
+}
+
+// staged returns the content of path's staged blob, or nil if it isn't
+// staged
+func (h *headReader) staged(idx *index.Index, path string) ([]byte, error) {
+	if idx == nil {
+		return nil, nil
+	}
+	entry, err := idx.Entry(path)
+	if err != nil {
+		return nil, nil
+	}
+
+	h.mu.Lock()
+	blob, err := h.repo.BlobObject(entry.Hash)
+	h.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from the index: %w", path, err)
+	}
+	content, err := readBlob(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from the index: %w", path, err)
+	}
+	return content, nil
+}
+
+// readBlob returns the whole content of blob. A single Read may return
+// only part of it, e.g. for large objects streamed from a packfile.
+func readBlob(blob *object.Blob) ([]byte, error) {
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content := make([]byte, blob.Size)
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// stagedFile builds the diff of a single staged file. It returns false for
+// statuses that produce no diff.
+func stagedFile(head *headReader, idx *index.Index, filePath string, fileStatus *git.FileStatus) (StagedFile, bool, error) {
+	file := StagedFile{Path: filePath}
+	var diffBuilder strings.Builder
+	newMode := indexMode(idx, filePath)
+
+	switch fileStatus.Staging {
+	case git.Added:
+		file.Status = StatusAdded
+
+		// New file - show all lines as additions
+		diffBuilder.WriteString("diff --git a/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString(" b/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\nnew file mode ")
+		diffBuilder.WriteString(formatMode(newMode))
+		diffBuilder.WriteString("\nindex 0000000..")
+		diffBuilder.WriteString(shortHash(indexBlobHash(idx, filePath)))
+		diffBuilder.WriteString("\n")
+
+		// Read the staged content
+		content, err := head.staged(idx, filePath)
+		if err != nil {
+			return StagedFile{}, false, err
+		}
+
+		if isBinary(content) {
+			diffBuilder.WriteString("Binary files /dev/null and b/")
 			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString(" b/")
+			diffBuilder.WriteString(" differ\n")
+			break
+		}
+
+		diffBuilder.WriteString("--- /dev/null\n+++ b/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n")
+		writeUnifiedHunks(&diffBuilder, "", string(content))
+
+	case git.Deleted:
+		file.Status = StatusDeleted
+
+		// Deleted file
+		diffBuilder.WriteString("diff --git a/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString(" b/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\ndeleted file mode ")
+		diffBuilder.WriteString(formatMode(head.mode(filePath)))
+		diffBuilder.WriteString("\nindex ")
+		diffBuilder.WriteString(shortHash(head.blobHash(filePath)))
+		diffBuilder.WriteString("..0000000\n")
+
+		// Try to get content from HEAD
+		content, err := head.content(filePath)
+		if err != nil {
+			return StagedFile{}, false, err
+		}
+
+		if isBinary(content) {
+			diffBuilder.WriteString("Binary files a/")
 			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\nindex ")
-			diffBuilder.WriteString(fileStatus.Extra)
-			diffBuilder.WriteString("..")
-			diffBuilder.WriteString(fileStatus.Extra)
-			diffBuilder.WriteString(" 100644\n--- a/")
+			diffBuilder.WriteString(" and /dev/null differ\n")
+			break
+		}
+
+		diffBuilder.WriteString("--- a/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n+++ /dev/null\n")
+		writeUnifiedHunks(&diffBuilder, string(content), "")
+
+	case git.Modified:
+		file.Status = StatusModified
+
+		// Modified file - get diff between HEAD and staged version
+		diffBuilder.WriteString("diff --git a/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString(" b/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n")
+		oldHash, newHash := head.blobHash(filePath), indexBlobHash(idx, filePath)
+		modeChanged := writeModeChange(&diffBuilder, head.mode(filePath), newMode)
+		if modeChanged && oldHash == newHash {
+			// Only the mode changed, e.g. chmod +x
+			break
+		}
+		diffBuilder.WriteString("index ")
+		diffBuilder.WriteString(shortHash(oldHash))
+		diffBuilder.WriteString("..")
+		diffBuilder.WriteString(shortHash(newHash))
+		if !modeChanged {
+			diffBuilder.WriteString(" ")
+			diffBuilder.WriteString(formatMode(newMode))
+		}
+		diffBuilder.WriteString("\n")
+
+		// Get old content from HEAD
+		oldContent, err := head.content(filePath)
+		if err != nil {
+			return StagedFile{}, false, err
+		}
+
+		// Get new content from the index, matching the hash above
+		newContent, err := head.staged(idx, filePath)
+		if err != nil {
+			return StagedFile{}, false, err
+		}
+
+		if isBinary(oldContent) || isBinary(newContent) {
+			diffBuilder.WriteString("Binary files a/")
 			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\n+++ b/")
+			diffBuilder.WriteString(" and b/")
 			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\n")
-
-			// Get old content from HEAD
-			var oldContent []byte
-			if headTree != nil {
-				entry, err := headTree.FindEntry(filePath)
-				if err == nil {
-					blob, err := repo.BlobObject(entry.Hash)
-					if err == nil {
-						reader, err := blob.Reader()
-						if err == nil {
-							oldContent = make([]byte, blob.Size)
-							reader.Read(oldContent)
-							reader.Close()
-						}
-					}
-				}
-			}
+			diffBuilder.WriteString(" differ\n")
+			break
+		}
 
-			// Get new content from working directory
-			fullPath := filepath.Join(wd, filePath)
-			newContent, err := os.ReadFile(fullPath)
-			if err != nil {
-				newContent = []byte{}
-			}
+		diffBuilder.WriteString("--- a/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n+++ b/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n")
 
-			// Simple line-by-line diff
-			oldLines := strings.Split(string(oldContent), "\n")
-			newLines := strings.Split(string(newContent), "\n")
+		// Emit only the changed hunks with surrounding context
+		writeUnifiedHunks(&diffBuilder, string(oldContent), string(newContent))
 
-			// For simplicity, show old lines as removed and new lines as added
-			// A more sophisticated diff algorithm could be used here
-			for _, line := range oldLines {
-				diffBuilder.WriteString("-")
-				diffBuilder.WriteString(line)
-				diffBuilder.WriteString("\n")
-			}
-			for _, line := range newLines {
-				diffBuilder.WriteString("+")
-				diffBuilder.WriteString(line)
-				diffBuilder.WriteString("\n")
-			}
+	case git.Renamed, git.Copied:
+		file.Status, file.OldPath = StatusRenamed, fileStatus.Extra
+		verb := "rename"
+		if fileStatus.Staging == git.Copied {
+			file.Status, verb = StatusCopied, "copy"
+		}
 
-		case git.Renamed:
-			// Renamed file
-			diffBuilder.WriteString("diff --git a/")
-			diffBuilder.WriteString(fileStatus.Extra)
-			diffBuilder.WriteString(" b/")
-			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\nrename from ")
+		oldContent, err := head.content(fileStatus.Extra)
+		if err != nil {
+			return StagedFile{}, false, err
+		}
+		newContent, err := head.staged(idx, filePath)
+		if err != nil {
+			return StagedFile{}, false, err
+		}
+
+		// Renamed or copied file, with the changes made to it if any
+		diffBuilder.WriteString("diff --git a/")
+		diffBuilder.WriteString(fileStatus.Extra)
+		diffBuilder.WriteString(" b/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n")
+		modeChanged := writeModeChange(&diffBuilder, head.mode(fileStatus.Extra), newMode)
+		diffBuilder.WriteString("similarity index ")
+		diffBuilder.WriteString(strconv.Itoa(similarity(oldContent, newContent)))
+		diffBuilder.WriteString("%\n" + verb + " from ")
+		diffBuilder.WriteString(fileStatus.Extra)
+		diffBuilder.WriteString("\n" + verb + " to ")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n")
+		if bytes.Equal(oldContent, newContent) {
+			break
+		}
+
+		diffBuilder.WriteString("index ")
+		diffBuilder.WriteString(shortHash(head.blobHash(fileStatus.Extra)))
+		diffBuilder.WriteString("..")
+		diffBuilder.WriteString(shortHash(indexBlobHash(idx, filePath)))
+		if !modeChanged {
+			diffBuilder.WriteString(" ")
+			diffBuilder.WriteString(formatMode(newMode))
+		}
+		diffBuilder.WriteString("\n")
+		if isBinary(oldContent) || isBinary(newContent) {
+			diffBuilder.WriteString("Binary files a/")
 			diffBuilder.WriteString(fileStatus.Extra)
-			diffBuilder.WriteString("\nrename to ")
+			diffBuilder.WriteString(" and b/")
 			diffBuilder.WriteString(filePath)
-			diffBuilder.WriteString("\n")
+			diffBuilder.WriteString(" differ\n")
+			break
+		}
+		diffBuilder.WriteString("--- a/")
+		diffBuilder.WriteString(fileStatus.Extra)
+		diffBuilder.WriteString("\n+++ b/")
+		diffBuilder.WriteString(filePath)
+		diffBuilder.WriteString("\n")
+		writeUnifiedHunks(&diffBuilder, string(oldContent), string(newContent))
+
+	default:
+		return StagedFile{}, false, nil
+	}
+
+	file.Diff = diffBuilder.String()
+	return file, true, nil
+}
+
+// shortHashLength is the length of the abbreviated blob hashes on a diff's
+// index line
+const shortHashLength = 7
+
+// shortHash abbreviates hash for display. Compare the full hashes: two
+// blobs can share an abbreviation.
+func shortHash(hash plumbing.Hash) string {
+	return hash.String()[:shortHashLength]
+}
+
+// headBlobHash returns the hash of path's blob in the HEAD tree, or
+// plumbing.ZeroHash if it isn't there
+func headBlobHash(tree *object.Tree, path string) plumbing.Hash {
+	if tree == nil {
+		return plumbing.ZeroHash
+	}
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return entry.Hash
+}
+
+// indexBlobHash returns the hash of path's staged blob, or
+// plumbing.ZeroHash if it isn't staged
+func indexBlobHash(idx *index.Index, path string) plumbing.Hash {
+	if idx == nil {
+		return plumbing.ZeroHash
+	}
+	entry, err := idx.Entry(path)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return entry.Hash
+}
+
+// GetStagedDiffStats returns the number of inserted and deleted lines of
+// each staged file
+func (c *ClientImpl) GetStagedDiffStats() ([]FileStat, error) {
+	diff, err := c.GetStagedDiff()
+	if err != nil {
+		return nil, err
+	}
+	return DiffStats(diff), nil
+}
+
+// GetWorktreeDiff returns the diff of all tracked changes, staged or not,
+// against HEAD, like `git diff HEAD`. Untracked files are left out.
+func (c *ClientImpl) GetWorktreeDiff() (string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var headTree *object.Tree
+	head, err := repo.Head()
+	if err != nil && err != plumbing.ErrReferenceNotFound {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if err == nil {
+		headCommit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+		}
+		headTree, err = headCommit.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get HEAD tree: %w", err)
 		}
 	}
 
-	diff := diffBuilder.String()
-	if len(diff) > 10000 {
-		return diff[:10000] + "\n...[TRUNCATED]", nil
+	paths := make([]string, 0, len(status))
+	for path, fileStatus := range status {
+		if fileStatus.Staging == git.Untracked || (fileStatus.Staging == git.Unmodified && fileStatus.Worktree == git.Unmodified) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	root := worktree.Filesystem.Root()
+	var sb strings.Builder
+	for _, path := range paths {
+		oldContent, oldExists, err := readTreeFile(repo, headTree, path)
+		if err != nil {
+			return "", err
+		}
+		oldMode := headMode(headTree, path)
+		var newContent []byte
+		newMode, err := worktreeMode(filepath.Join(root, path))
+		if err == nil {
+			newContent, err = readWorktreeFile(filepath.Join(root, path), newMode)
+		}
+		newExists := err == nil
+		if oldExists && newExists && oldMode == newMode && bytes.Equal(oldContent, newContent) {
+			continue
+		}
+		if oldExists || newExists {
+			writeFileDiff(&sb, path, oldContent, newContent, oldMode, newMode, oldExists, newExists)
+		}
 	}
-	return diff, nil
+	return sb.String(), nil
 }
 
-// CommitWithMessage executes git commit with the given message
-func (c *ClientImpl) CommitWithMessage(message string) error {
+// GetRevisionDiff returns the diff between two revisions, like
+// `git diff from to`. Revisions can be anything go-git resolves, such as
+// branch names, tags, hashes, or "stash^1".
+func (c *ClientImpl) GetRevisionDiff(from, to string) (string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	fromCommit, err := resolveCommit(repo, from)
+	if err != nil {
+		return "", err
+	}
+	toCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		return "", err
+	}
+
+	patch, err := fromCommit.Patch(toCommit)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s..%s: %w", from, to, err)
+	}
+	return patch.String(), nil
+}
+
+// GetMergeBase returns the hash of the best common ancestor of two
+// revisions
+func (c *ClientImpl) GetMergeBase(rev1, rev2 string) (string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	commit1, err := resolveCommit(repo, rev1)
+	if err != nil {
+		return "", err
+	}
+	commit2, err := resolveCommit(repo, rev2)
+	if err != nil {
+		return "", err
+	}
+
+	bases, err := commit1.MergeBase(commit2)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %s and %s: %w", rev1, rev2, err)
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("%s and %s have no common ancestor", rev1, rev2)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+// resolveCommit resolves a revision to its commit
+func resolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %q: %w", rev, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", rev, err)
+	}
+	return commit, nil
+}
+
+// readTreeFile returns the content of path in tree, and whether it exists.
+// Only a path missing from the tree is absent; failing to read it is an
+// error.
+func readTreeFile(repo *git.Repository, tree *object.Tree, path string) ([]byte, bool, error) {
+	if tree == nil {
+		return nil, false, nil
+	}
+	entry, err := tree.FindEntry(path)
+	if err != nil && missingFromTree(tree, path, err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to find %s in HEAD: %w", path, err)
+	}
+	blob, err := repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get blob for %s: %w", path, err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open blob for %s: %w", path, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+	}
+	return content, true, nil
+}
+
+// missingFromTree reports whether err, from looking up path in tree, means
+// path isn't in the tree rather than that the tree couldn't be read
+func missingFromTree(tree *object.Tree, path string, err error) bool {
+	return errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) || underFile(tree, path)
+}
+
+// underFile reports whether a parent directory of path is a file in tree,
+// as when a file is replaced by a directory. go-git reports such paths as
+// missing objects rather than missing entries.
+func underFile(tree *object.Tree, path string) bool {
+	for dir := filepath.ToSlash(filepath.Dir(path)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		entry, err := tree.FindEntry(dir)
+		if err == nil {
+			return entry.Mode != filemode.Dir
+		}
+	}
+	return false
+}
+
+// writeFileDiff writes the diff of a single file whose content or mode
+// changed. A file that doesn't exist on one side is written as added or
+// deleted.
+func writeFileDiff(sb *strings.Builder, path string, oldContent, newContent []byte, oldMode, newMode filemode.FileMode, oldExists, newExists bool) {
+	sb.WriteString("diff --git a/")
+	sb.WriteString(path)
+	sb.WriteString(" b/")
+	sb.WriteString(path)
+	sb.WriteString("\n")
+	switch {
+	case !oldExists:
+		sb.WriteString("new file mode " + formatMode(newMode) + "\n")
+	case !newExists:
+		sb.WriteString("deleted file mode " + formatMode(oldMode) + "\n")
+	default:
+		writeModeChange(sb, oldMode, newMode)
+		if bytes.Equal(oldContent, newContent) {
+			return
+		}
+	}
+
+	oldName, newName := "a/"+path, "b/"+path
+	if !oldExists {
+		oldName = "/dev/null"
+	}
+	if !newExists {
+		newName = "/dev/null"
+	}
+
+	if isBinary(oldContent) || isBinary(newContent) {
+		sb.WriteString("Binary files ")
+		sb.WriteString(oldName)
+		sb.WriteString(" and ")
+		sb.WriteString(newName)
+		sb.WriteString(" differ\n")
+		return
+	}
+
+	sb.WriteString("--- ")
+	sb.WriteString(oldName)
+	sb.WriteString("\n+++ ")
+	sb.WriteString(newName)
+	sb.WriteString("\n")
+	writeUnifiedHunks(sb, string(oldContent), string(newContent))
+}
+
+// GetStagedPaths returns all staged files, including those matching
+// IgnoreFile, sorted by path
+func (c *ClientImpl) GetStagedPaths() ([]StagedPath, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	ignore, err := readIgnoreFile(worktree.Filesystem.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []StagedPath
+	for filePath, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			paths = append(paths, StagedPath{
+				Path:     filePath,
+				Ignored:  ignore.Ignored(filePath),
+				Unstaged: fileStatus.Worktree != git.Unmodified,
+			})
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i].Path < paths[j].Path })
+	return paths, nil
+}
+
+// StageFiles adds the current working tree state of the given paths to the
+// index. Paths missing from the working tree are staged as deletions.
+func (c *ClientImpl) StageFiles(paths []string) error {
 	repo, err := c.openRepo()
 	if err != nil {
 		return fmt.Errorf("failed to open repository: %w", err)
@@ -311,30 +1014,184 @@ func (c *ClientImpl) CommitWithMessage(message string) error {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	// Get git config for author information
-	config, err := repo.Config()
+	for _, path := range paths {
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// UnstageFiles resets the index entries of the given paths to HEAD,
+// leaving the working tree untouched
+func (c *ClientImpl) UnstageFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	repo, err := c.openRepo()
 	if err != nil {
-		return fmt.Errorf("failed to get git config: %w", err)
+		return fmt.Errorf("failed to open repository: %w", err)
 	}
 
-	// Validate that git user name and email are configured
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		// No commits yet, so unstaging means dropping the index entries
+		idx, err := repo.Storer.Index()
+		if err != nil {
+			return fmt.Errorf("failed to read index: %w", err)
+		}
+		for _, path := range paths {
+			if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+				return fmt.Errorf("failed to unstage %s: %w", path, err)
+			}
+		}
+		if err := repo.Storer.SetIndex(idx); err != nil {
+			return fmt.Errorf("failed to write index: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err := worktree.Reset(&git.ResetOptions{
+		Commit: head.Hash(),
+		Mode:   git.MixedReset,
+		Files:  paths,
+	}); err != nil {
+		return fmt.Errorf("failed to unstage files: %w", err)
+	}
+	return nil
+}
+
+// GetHTTPProxy returns git's http.proxy setting, looking at the repository
+// config first and then the user's global config. It returns an empty
+// string if no proxy is configured.
+func (c *ClientImpl) GetHTTPProxy() (string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	cfg, err := repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return "", fmt.Errorf("failed to get git config: %w", err)
+	}
+	return cfg.Raw.Section("http").Option("proxy"), nil
+}
+
+// GetUser returns the user name and email commits are made as, or an
+// error explaining how to set them if either is missing
+func (c *ClientImpl) GetUser() (name, email string, err error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	config, err := repo.Config()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get git config: %w", err)
+	}
 	if config.User.Name == "" {
-		return fmt.Errorf("git user name is not configured. Please set it with: git config user.name \"Your Name\"")
+		return "", "", fmt.Errorf("git user name is not configured. Please set it with: git config user.name \"Your Name\"")
 	}
 	if config.User.Email == "" {
-		return fmt.Errorf("git user email is not configured. Please set it with: git config user.email \"your.email@example.com\"")
+		return "", "", fmt.Errorf("git user email is not configured. Please set it with: git config user.email \"your.email@example.com\"")
+	}
+	return config.User.Name, config.User.Email, nil
+}
+
+// binarySniffLen is how much of a file is inspected for binary content,
+// the same amount git checks
+const binarySniffLen = 8000
+
+// isBinary reports whether content looks binary, using git's heuristic of
+// a NUL byte within the first few KB
+func isBinary(content []byte) bool {
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// CommitWithMessage executes git commit with the given message
+func (c *ClientImpl) CommitWithMessage(message string, opts CommitOptions) error {
+	return c.commit(message, false, opts)
+}
+
+// AmendWithMessage replaces the HEAD commit with one containing the staged
+// changes and the given message, like `git commit --amend`
+func (c *ClientImpl) AmendWithMessage(message string, opts CommitOptions) error {
+	return c.commit(message, true, opts)
+}
+
+// commit creates a commit from the index, replacing HEAD if amend is set
+func (c *ClientImpl) commit(message string, amend bool, opts CommitOptions) error {
+	repo, err := c.openRepo()
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
 	}
 
-	// Create author signature from config
-	author := &object.Signature{
-		Name:  config.User.Name,
-		Email: config.User.Email,
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	// Create committer signature from config
+	name, email, err := c.GetUser()
+	if err != nil {
+		return err
+	}
+	committer := &object.Signature{
+		Name:  name,
+		Email: email,
 		When:  time.Now(),
 	}
+	// Amending keeps the original author and date, like git commit --amend
+	author := committer
+	if amend {
+		head, err := repo.Head()
+		if err != nil {
+			return fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		headCommit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to get HEAD commit: %w", err)
+		}
+		author = &headCommit.Author
+	}
+	for _, coAuthor := range opts.CoAuthors {
+		message = appendTrailer(message, "Co-authored-by: "+coAuthor)
+	}
+	if opts.SignOff {
+		message = appendSignOff(message, committer.Name, committer.Email)
+	}
+
+	// Sign the commit like git would when commit.gpgsign is set
+	scoped, err := repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return fmt.Errorf("failed to get git config: %w", err)
+	}
+	var signer git.Signer
+	if signing := readSigningConfig(scoped.Raw); signing.enabled {
+		signer, err = newSigner(signing, fmt.Sprintf("%s <%s>", committer.Name, committer.Email))
+		if err != nil {
+			return err
+		}
+	}
 
 	// Commit the staged changes
 	_, err = worktree.Commit(message, &git.CommitOptions{
-		Author: author,
+		Author:    author,
+		Committer: committer,
+		Amend:     amend,
+		Signer:    signer,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
@@ -343,6 +1200,68 @@ func (c *ClientImpl) CommitWithMessage(message string) error {
 	return nil
 }
 
+// GetRecentCommitSubjects returns the subject lines of up to n commits
+// reachable from HEAD, newest first. A repository without commits has no
+// subjects.
+func (c *ClientImpl) GetRecentCommitSubjects(n int) ([]string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer commits.Close()
+
+	var subjects []string
+	for len(subjects) < n {
+		commit, err := commits.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		if subject = strings.TrimSpace(subject); subject != "" {
+			subjects = append(subjects, subject)
+		}
+	}
+	return subjects, nil
+}
+
+// GetLastCommitMessage returns the full message of the HEAD commit
+func (c *ClientImpl) GetLastCommitMessage() (string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return "", fmt.Errorf("no commits yet")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	return strings.TrimRight(commit.Message, "\n"), nil
+}
+
 // GetRepoRoot returns the root directory of the git repository
 func (c *ClientImpl) GetRepoRoot() (string, error) {
 	repo, err := c.openRepo()
@@ -360,9 +1279,9 @@ func (c *ClientImpl) GetRepoRoot() (string, error) {
 		return boundOS.Root(), nil
 	}
 
-	// Fallback: traverse up from current directory to find .git directory
-	// This works regardless of filesystem type
-	wd, err := os.Getwd()
+	// Fallback: traverse up from the client's directory to find .git
+	// directory. This works regardless of filesystem type
+	wd, err := c.workDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get working directory: %w", err)
 	}
@@ -384,3 +1303,90 @@ func (c *ClientImpl) GetRepoRoot() (string, error) {
 
 	return "", fmt.Errorf("failed to determine repository root: .git directory not found")
 }
+
+// GetCurrentBranch returns the short name of the checked-out branch.
+// It returns an empty string when HEAD is detached.
+func (c *ClientImpl) GetCurrentBranch() (string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	// Read HEAD without resolving it so unborn branches (no commits yet)
+	// still report their name
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+		return "", nil
+	}
+	return head.Target().Short(), nil
+}
+
+// GitPath returns the path of name in the git dir, like git rev-parse
+// --git-path. In a linked worktree that is the worktree's own git dir,
+// except for hooks, which are shared with the main repository.
+func (c *ClientImpl) GitPath(name string) (string, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errors.New("repository has no git dir on disk")
+	}
+	gitDir := storage.Filesystem().Root()
+	if first, _, _ := strings.Cut(filepath.ToSlash(name), "/"); first == "hooks" {
+		gitDir, err = commonGitDir(gitDir)
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(gitDir, name), nil
+}
+
+// commonGitDir returns the git dir gitDir shares hooks, objects and refs
+// with: the one its "commondir" file names, or gitDir itself
+func commonGitDir(gitDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if os.IsNotExist(err) {
+		return gitDir, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read commondir: %w", err)
+	}
+	commonDir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return filepath.Clean(commonDir), nil
+}
+
+// midOperationFiles are the files git keeps in the git dir while a merge
+// or rebase is stopped, e.g. on conflicts
+var midOperationFiles = []string{"MERGE_HEAD", "rebase-merge", "rebase-apply"}
+
+// IsMidMergeOrRebase reports whether a merge or rebase is in progress.
+// In a linked worktree the worktree's own git dir is checked.
+func (c *ClientImpl) IsMidMergeOrRebase() (bool, error) {
+	repo, err := c.openRepo()
+	if err != nil {
+		return false, fmt.Errorf("failed to open repository: %w", err)
+	}
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return false, nil
+	}
+	for _, name := range midOperationFiles {
+		_, err := storage.Filesystem().Stat(name)
+		if err == nil {
+			return true, nil
+		}
+		if !os.IsNotExist(err) {
+			return false, fmt.Errorf("failed to check for %s: %w", name, err)
+		}
+	}
+	return false, nil
+}
@@ -155,6 +155,22 @@ func BenchmarkGetStagedDiff_VeryLarge(b *testing.B) {
 	}
 }
 
+// BenchmarkGetStagedDiff_VeryLargeSerial diffs the same files as
+// BenchmarkGetStagedDiff_VeryLarge one at a time, for comparison with the
+// concurrent default. Only the tree and object lookups are serialized
+// there; the blob reads and diffs run in parallel.
+func BenchmarkGetStagedDiff_VeryLargeSerial(b *testing.B) {
+	cleanup := setupBenchRepo(b, 50, 1000)
+	defer cleanup()
+
+	client := &ClientImpl{diffWorkers: 1}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = client.GetStagedDiff()
+	}
+}
+
 func BenchmarkGetStagedDiff_ModifiedFiles(b *testing.B) {
 	tempDir := b.TempDir()
 	originalWd, _ := os.Getwd()
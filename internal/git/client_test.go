@@ -1,11 +1,24 @@
 package git
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 func TestClientImpl_Integration(t *testing.T) {
@@ -114,4 +127,2021 @@ func TestClientImpl_Integration(t *testing.T) {
 	if !strings.Contains(diff, "test.txt") {
 		t.Errorf("expected diff to contain 'test.txt', got: %s", diff)
 	}
+
+	// 7. Test GetCurrentBranch on an unborn branch
+	branch, err := client.GetCurrentBranch()
+	if err != nil {
+		t.Errorf("unexpected error getting branch: %v", err)
+	}
+	if branch != "master" {
+		t.Errorf("expected branch 'master', got %q", branch)
+	}
+}
+
+func TestClientImpl_GetStagedDiff_Binary(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+
+	// PNG signature followed by a chunk header containing NUL bytes
+	pngBytes := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDRrawpixeldata")
+	if err := os.WriteFile("image.png", pngBytes, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("image.png"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+
+	diff, err := NewClient().GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting diff: %v", err)
+	}
+
+	if !strings.Contains(diff, "Binary files /dev/null and b/image.png differ") {
+		t.Errorf("expected binary marker in diff, got: %s", diff)
+	}
+	if strings.Contains(diff, "IHDR") || strings.Contains(diff, "rawpixeldata") {
+		t.Errorf("expected raw binary content to be omitted, got: %s", diff)
+	}
+}
+
+func TestClientImpl_GetStagedDiff_Ordering(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	// Staged in reverse order, across directories
+	var expected []string
+	for i := 19; i >= 0; i-- {
+		name := fmt.Sprintf("dir%d/file%02d.txt", i%3, i)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(strings.Repeat(name+"\n", 50)), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+		expected = append(expected, name)
+	}
+	sort.Strings(expected)
+
+	serial, err := (&ClientImpl{diffWorkers: 1}).GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting diff: %v", err)
+	}
+
+	for run := 0; run < 5; run++ {
+		client := &ClientImpl{diffWorkers: 8}
+		files, err := client.GetStagedFiles()
+		if err != nil {
+			t.Fatalf("unexpected error getting files: %v", err)
+		}
+		var paths []string
+		for _, f := range files {
+			paths = append(paths, f.Path)
+		}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Fatalf("expected files in path order %v, got %v", expected, paths)
+		}
+
+		diff, err := client.GetStagedDiff()
+		if err != nil {
+			t.Fatalf("unexpected error getting diff: %v", err)
+		}
+		if diff != serial {
+			t.Fatalf("concurrent diff differs from the serial one")
+		}
+	}
+}
+
+func TestClientImpl_GetStagedDiff_Reproducible(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+
+	for _, name := range []string{"z.txt", "m/old.txt", "a/keep.txt"} {
+		write(name, "original\n")
+	}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// A mix of added, modified, and deleted files
+	write("z.txt", "changed\n")
+	write("a/keep.txt", "changed\n")
+	write("b/new.txt", "new\n")
+	write("y/new.txt", "new\n")
+	if _, err := worktree.Remove("m/old.txt"); err != nil {
+		t.Fatalf("failed to git rm: %v", err)
+	}
+
+	first, err := NewClient().GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting diff: %v", err)
+	}
+	second, err := NewClient().GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting diff: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected byte-identical diffs, got:\n%s\n---\n%s", first, second)
+	}
+
+	var headers []string
+	for _, line := range strings.Split(first, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			headers = append(headers, line)
+		}
+	}
+	expected := []string{
+		"diff --git a/a/keep.txt b/a/keep.txt",
+		"diff --git a/b/new.txt b/b/new.txt",
+		"diff --git a/m/old.txt b/m/old.txt",
+		"diff --git a/y/new.txt b/y/new.txt",
+		"diff --git a/z.txt b/z.txt",
+	}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Errorf("expected files in path order %v, got %v", expected, headers)
+	}
+}
+
+func TestClientImpl_GetStagedDiff_LargeBlobs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	// Multi-KB files on the HEAD side must be read whole
+	var lines []string
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d of a large file", i))
+	}
+	content := strings.Join(lines, "\n") + "\n"
+	for _, name := range []string{"modified.txt", "deleted.txt"} {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// Change only the last line of one file and delete the other
+	changed := strings.Replace(content, "line 1999 of", "last line of", 1)
+	if err := os.WriteFile("modified.txt", []byte(changed), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := worktree.Add("modified.txt"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	if _, err := worktree.Remove("deleted.txt"); err != nil {
+		t.Fatalf("failed to git rm: %v", err)
+	}
+
+	files, err := NewClient().GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting files: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+
+	deleted, modified := files[0].Diff, files[1].Diff
+	if removed := strings.Count(deleted, "\n-line "); removed != len(lines) {
+		t.Errorf("expected all %d lines of the deleted file to be removed, got %d", len(lines), removed)
+	}
+	if !strings.Contains(modified, "-line 1999 of a large file\n+last line of a large file\n") {
+		t.Errorf("expected the last line to change, got:\n%s", modified)
+	}
+	if added := strings.Count(modified, "\n+"); added != 2 {
+		// The "+++ b/modified.txt" header and the changed line
+		t.Errorf("expected only the last line to be added, got %d added lines:\n%s", added, modified)
+	}
+}
+
+// shortReadObject is a blob whose reader returns at most half of the
+// requested bytes per Read
+type shortReadObject struct {
+	*plumbing.MemoryObject
+}
+
+func (o shortReadObject) Reader() (io.ReadCloser, error) {
+	r, err := o.MemoryObject.Reader()
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(iotest.HalfReader(r)), nil
+}
+
+func TestReadBlob_ShortReads(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789abcdef", 512)) // 8 KiB
+	obj := &plumbing.MemoryObject{}
+	obj.SetType(plumbing.BlobObject)
+	if _, err := obj.Write(content); err != nil {
+		t.Fatalf("failed to write object: %v", err)
+	}
+
+	blob, err := object.DecodeBlob(shortReadObject{obj})
+	if err != nil {
+		t.Fatalf("failed to decode blob: %v", err)
+	}
+	got, err := readBlob(blob)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("expected the whole %d-byte blob, got a short read", len(content))
+	}
+
+	// A blob that ends early is an error, not a silently truncated diff
+	truncated := &plumbing.MemoryObject{}
+	truncated.SetType(plumbing.BlobObject)
+	if _, err := truncated.Write(content[:100]); err != nil {
+		t.Fatalf("failed to write object: %v", err)
+	}
+	truncated.SetSize(int64(len(content)))
+	blob, err = object.DecodeBlob(truncated)
+	if err != nil {
+		t.Fatalf("failed to decode blob: %v", err)
+	}
+	if _, err := readBlob(blob); err == nil {
+		t.Error("expected an error for a truncated blob")
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	if isBinary([]byte("plain text\n")) {
+		t.Error("expected text content not to be binary")
+	}
+	if !isBinary([]byte("abc\x00def")) {
+		t.Error("expected content with NUL byte to be binary")
+	}
+	if isBinary(append(bytes.Repeat([]byte("a"), binarySniffLen), 0)) {
+		t.Error("expected NUL beyond the sniff window to be ignored")
+	}
+}
+
+func TestClientImpl_StageAndUnstageFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	if _, err := git.PlainInit(tempDir, false); err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(name, []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	client := NewClient()
+
+	// Unborn branch: staging and unstaging edit the index directly
+	if err := client.StageFiles([]string{"a.txt", "b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	if err := client.UnstageFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("failed to unstage files: %v", err)
+	}
+	paths, err := client.GetStagedPaths()
+	if err != nil {
+		t.Fatalf("failed to get staged paths: %v", err)
+	}
+	if len(paths) != 1 || paths[0].Path != "b.txt" {
+		t.Errorf("expected only b.txt staged, got %v", paths)
+	}
+
+	// With a HEAD commit: unstaging resets the entry to HEAD
+	if err := client.StageFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	repo, _ := git.PlainOpen(tempDir)
+	worktree, _ := repo.Worktree()
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(name, []byte(name+" changed\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	if err := client.StageFiles([]string{"a.txt", "b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	if err := client.UnstageFiles([]string{"b.txt"}); err != nil {
+		t.Fatalf("failed to unstage files: %v", err)
+	}
+	paths, err = client.GetStagedPaths()
+	if err != nil {
+		t.Fatalf("failed to get staged paths: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != (StagedPath{Path: "a.txt"}) {
+		t.Errorf("expected only a.txt staged, got %v", paths)
+	}
+
+	// The working tree keeps the unstaged change
+	content, _ := os.ReadFile("b.txt")
+	if string(content) != "b.txt changed\n" {
+		t.Errorf("expected working tree to be untouched, got %q", content)
+	}
+
+	// Ignored and partially staged files are flagged
+	if err := os.WriteFile(IgnoreFile, []byte("b.txt\n"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", IgnoreFile, err)
+	}
+	if err := client.StageFiles([]string{"b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	if err := os.WriteFile("a.txt", []byte("a.txt changed again\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	paths, err = client.GetStagedPaths()
+	if err != nil {
+		t.Fatalf("failed to get staged paths: %v", err)
+	}
+	expected := []StagedPath{{Path: "a.txt", Unstaged: true}, {Path: "b.txt", Ignored: true}}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected staged paths %+v, got %+v", expected, paths)
+	}
+}
+
+func TestClientImpl_GetHTTPProxy(t *testing.T) {
+	tempDir := t.TempDir()
+	// Keep the user's real global git config out of the test
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+
+	client := NewClient()
+
+	proxy, err := client.GetHTTPProxy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxy != "" {
+		t.Errorf("expected no proxy, got %q", proxy)
+	}
+
+	config, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	config.Raw.Section("http").SetOption("proxy", "http://proxy.example.com:3128")
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	proxy, err = NewClient().GetHTTPProxy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxy != "http://proxy.example.com:3128" {
+		t.Errorf("expected proxy from git config, got %q", proxy)
+	}
+}
+
+func TestClientImpl_GetUser(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	client := NewClientAt(tempDir)
+
+	if _, _, err := client.GetUser(); err == nil || !strings.Contains(err.Error(), "user name is not configured") {
+		t.Errorf("expected a missing user name error, got %v", err)
+	}
+
+	config, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	config.User.Name = "Ada Lovelace"
+	config.User.Email = "ada@example.com"
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	name, email, err := client.GetUser()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "Ada Lovelace" || email != "ada@example.com" {
+		t.Errorf("expected Ada Lovelace <ada@example.com>, got %s <%s>", name, email)
+	}
+}
+
+func TestClientImpl_WorktreeAndRevisionDiffs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	commit := func(message string, files map[string]string) string {
+		t.Helper()
+		for name, content := range files {
+			if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			if _, err := worktree.Add(name); err != nil {
+				t.Fatalf("failed to git add: %v", err)
+			}
+		}
+		hash, err := worktree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		return hash.String()
+	}
+
+	first := commit("first", map[string]string{"a.txt": "one\n"})
+	commit("second", map[string]string{"a.txt": "two\n", "b.txt": "new\n"})
+
+	client := NewClient()
+
+	diff, err := client.GetRevisionDiff("HEAD^1", "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error getting revision diff: %v", err)
+	}
+	for _, want := range []string{"diff --git a/a.txt b/a.txt", "-one", "+two", "+++ b/b.txt", "+new"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected revision diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+
+	if _, err := client.GetRevisionDiff("missing", "HEAD"); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+
+	base, err := client.GetMergeBase(first, "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error getting merge base: %v", err)
+	}
+	if base != first {
+		t.Errorf("expected merge base %s, got %s", first, base)
+	}
+
+	// Unstaged edit, staged new file, and an untracked file
+	if err := os.WriteFile("a.txt", []byte("three\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile("c.txt", []byte("staged\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := worktree.Add("c.txt"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	if err := os.WriteFile("d.txt", []byte("untracked\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	diff, err = client.GetWorktreeDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting worktree diff: %v", err)
+	}
+	for _, want := range []string{"-two", "+three", "new file mode 100644", "+++ b/c.txt", "+staged"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected worktree diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "d.txt") || strings.Contains(diff, "b.txt") {
+		t.Errorf("expected untracked and unchanged files to be left out, got:\n%s", diff)
+	}
+}
+
+func TestClientImpl_GetStagedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"modified.txt": "old\n",
+		"deleted.txt":  "gone\n",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	if err := os.WriteFile("added.txt", []byte("new\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := worktree.Add("added.txt"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	if err := os.WriteFile("modified.txt", []byte("new\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := worktree.Add("modified.txt"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	if _, err := worktree.Remove("deleted.txt"); err != nil {
+		t.Fatalf("failed to git rm: %v", err)
+	}
+
+	client := NewClient()
+	files, err := client.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+
+	byPath := make(map[string]StagedFile, len(files))
+	for _, file := range files {
+		byPath[file.Path] = file
+	}
+
+	tests := []struct {
+		path     string
+		status   FileStatus
+		oldPath  string
+		contains string
+	}{
+		{path: "added.txt", status: StatusAdded, contains: "+new"},
+		{path: "modified.txt", status: StatusModified, contains: "-old\n+new"},
+		{path: "deleted.txt", status: StatusDeleted, contains: "-gone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			file, ok := byPath[tt.path]
+			if !ok {
+				t.Fatalf("expected %s to be staged, got %+v", tt.path, files)
+			}
+			if file.Status != tt.status {
+				t.Errorf("expected status %q, got %q", tt.status, file.Status)
+			}
+			if file.OldPath != tt.oldPath {
+				t.Errorf("expected old path %q, got %q", tt.oldPath, file.OldPath)
+			}
+			if !strings.HasPrefix(file.Diff, "diff --git ") {
+				t.Errorf("expected diff to start with its header, got:\n%s", file.Diff)
+			}
+			if !strings.Contains(file.Diff, tt.contains) {
+				t.Errorf("expected diff to contain %q, got:\n%s", tt.contains, file.Diff)
+			}
+		})
+	}
+
+	// Index lines name the real HEAD and staged blobs
+	oldHash := plumbing.ComputeHash(plumbing.BlobObject, []byte("old\n")).String()[:7]
+	newHash := plumbing.ComputeHash(plumbing.BlobObject, []byte("new\n")).String()[:7]
+	if oldHash == newHash {
+		t.Fatal("expected distinct blob hashes")
+	}
+	indexLines := map[string]string{
+		"modified.txt": "\nindex " + oldHash + ".." + newHash + " 100644\n",
+		"added.txt":    "\nindex 0000000.." + newHash + "\n",
+		"deleted.txt":  "\nindex " + plumbing.ComputeHash(plumbing.BlobObject, []byte("gone\n")).String()[:7] + "..0000000\n",
+	}
+	for path, line := range indexLines {
+		if !strings.Contains(byPath[path].Diff, line) {
+			t.Errorf("expected %s diff to contain %q, got:\n%s", path, line, byPath[path].Diff)
+		}
+	}
+
+	// The string diff is the concatenation of the file diffs
+	diff, err := client.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting diff: %v", err)
+	}
+	for _, file := range files {
+		if !strings.Contains(diff, file.Diff) {
+			t.Errorf("expected staged diff to contain the diff of %s", file.Path)
+		}
+	}
+}
+
+func TestStagedFile_Renamed(t *testing.T) {
+	// go-git's status reports renames as a delete and an add, so build the
+	// renamed status directly
+	file, ok, err := stagedFile(&headReader{}, nil, "new.txt", &git.FileStatus{Staging: git.Renamed, Extra: "old.txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected a renamed file to produce a diff")
+	}
+	if file.Status != StatusRenamed || file.OldPath != "old.txt" || file.Path != "new.txt" {
+		t.Errorf("unexpected renamed file %+v", file)
+	}
+	expected := "diff --git a/old.txt b/new.txt\nsimilarity index 100%\nrename from old.txt\nrename to new.txt\n"
+	if file.Diff != expected {
+		t.Errorf("expected diff %q, got %q", expected, file.Diff)
+	}
+
+	file, ok, err = stagedFile(&headReader{}, nil, "copy.txt", &git.FileStatus{Staging: git.Copied, Extra: "source.txt"})
+	if err != nil || !ok {
+		t.Fatalf("expected a copied file to produce a diff, got %v", err)
+	}
+	if file.Status != StatusCopied || file.OldPath != "source.txt" {
+		t.Errorf("unexpected copied file %+v", file)
+	}
+	expected = "diff --git a/source.txt b/copy.txt\nsimilarity index 100%\ncopy from source.txt\ncopy to copy.txt\n"
+	if file.Diff != expected {
+		t.Errorf("expected diff %q, got %q", expected, file.Diff)
+	}
+}
+
+func TestClientImpl_GetStagedFiles_Renamed(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	files := map[string]string{
+		"moved.txt":  "one\ntwo\nthree\nfour\nfive\n",
+		"edited.txt": "alpha\nbeta\ngamma\ndelta\n",
+		"gone.txt":   "unrelated\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// A pure rename, a rename with edits, and a deletion next to an
+	// unrelated new file
+	renamed := map[string]string{
+		"moved.txt":  "renamed.txt",
+		"edited.txt": "rewritten.txt",
+		"gone.txt":   "fresh.txt",
+	}
+	contents := map[string]string{
+		"renamed.txt":   files["moved.txt"],
+		"rewritten.txt": "alpha\nbeta\nGAMMA\ndelta\n",
+		"fresh.txt":     "brand new\n",
+	}
+	for oldPath, newPath := range renamed {
+		if _, err := worktree.Remove(oldPath); err != nil {
+			t.Fatalf("failed to git rm: %v", err)
+		}
+		if err := os.WriteFile(newPath, []byte(contents[newPath]), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(newPath); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+
+	staged, err := NewClient().GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+	byPath := make(map[string]StagedFile, len(staged))
+	for _, file := range staged {
+		byPath[file.Path] = file
+	}
+	if len(staged) != 4 {
+		t.Errorf("expected 2 renames, a deletion and an addition, got %+v", staged)
+	}
+
+	moved := byPath["renamed.txt"]
+	if moved.Status != StatusRenamed || moved.OldPath != "moved.txt" {
+		t.Errorf("expected renamed.txt to be renamed from moved.txt, got %+v", moved)
+	}
+	expected := "diff --git a/moved.txt b/renamed.txt\nsimilarity index 100%\nrename from moved.txt\nrename to renamed.txt\n"
+	if moved.Diff != expected {
+		t.Errorf("expected diff %q, got %q", expected, moved.Diff)
+	}
+
+	edited := byPath["rewritten.txt"]
+	if edited.Status != StatusRenamed || edited.OldPath != "edited.txt" {
+		t.Errorf("expected rewritten.txt to be renamed from edited.txt, got %+v", edited)
+	}
+	for _, want := range []string{
+		"diff --git a/edited.txt b/rewritten.txt\nsimilarity index 75%\nrename from edited.txt\nrename to rewritten.txt\n",
+		"--- a/edited.txt\n+++ b/rewritten.txt\n",
+		"-gamma\n+GAMMA\n",
+	} {
+		if !strings.Contains(edited.Diff, want) {
+			t.Errorf("expected the rename diff to contain %q, got:\n%s", want, edited.Diff)
+		}
+	}
+
+	if byPath["gone.txt"].Status != StatusDeleted || byPath["fresh.txt"].Status != StatusAdded {
+		t.Errorf("expected dissimilar files to stay a deletion and an addition, got %+v", staged)
+	}
+}
+
+func TestClientImpl_GetStagedFiles_Copied(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	stage := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	stage("template.txt", "one\ntwo\nthree\n")
+	stage("config.txt", "name = a\nport = 1\nhost = x\nmode = dev\n")
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// An exact copy of an unchanged file, and an edited copy of a file
+	// that is also modified
+	stage("template-copy.txt", "one\ntwo\nthree\n")
+	stage("config.txt", "name = a\nport = 2\nhost = x\nmode = dev\n")
+	stage("config-prod.txt", "name = a\nport = 1\nhost = x\nmode = prod\n")
+	stage("notes.txt", "unrelated\n")
+
+	staged, err := NewClient().GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+	byPath := make(map[string]StagedFile, len(staged))
+	for _, file := range staged {
+		byPath[file.Path] = file
+	}
+
+	exact := byPath["template-copy.txt"]
+	if exact.Status != StatusCopied || exact.OldPath != "template.txt" {
+		t.Errorf("expected template-copy.txt to be copied from template.txt, got %+v", exact)
+	}
+	expected := "diff --git a/template.txt b/template-copy.txt\nsimilarity index 100%\ncopy from template.txt\ncopy to template-copy.txt\n"
+	if exact.Diff != expected {
+		t.Errorf("expected diff %q, got %q", expected, exact.Diff)
+	}
+
+	edited := byPath["config-prod.txt"]
+	if edited.Status != StatusCopied || edited.OldPath != "config.txt" {
+		t.Errorf("expected config-prod.txt to be copied from config.txt, got %+v", edited)
+	}
+	for _, want := range []string{"similarity index 75%\ncopy from config.txt\ncopy to config-prod.txt\n", "-mode = dev\n+mode = prod\n"} {
+		if !strings.Contains(edited.Diff, want) {
+			t.Errorf("expected the copy diff to contain %q, got:\n%s", want, edited.Diff)
+		}
+	}
+
+	if byPath["config.txt"].Status != StatusModified || byPath["notes.txt"].Status != StatusAdded {
+		t.Errorf("expected the source to stay modified and the new file added, got %+v", staged)
+	}
+	stats := DiffStats(exact.Diff)
+	if len(stats) != 1 || stats[0].Status != StatusCopied {
+		t.Errorf("expected the stats to report a copy, got %+v", stats)
+	}
+}
+
+func TestClientImpl_GetStagedDiff_PartiallyStaged(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile("main.go", []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	write("package main\n\nfunc main() {}\n")
+	if _, err := worktree.Add("main.go"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// Stage one edit, then make another without staging it
+	write("package main\n\n// staged\nfunc main() {}\n")
+	if _, err := worktree.Add("main.go"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	write("package main\n\n// staged\nfunc main() {}\n\n// unstaged\n")
+
+	diff, err := NewClient().GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged diff: %v", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	entry, err := idx.Entry("main.go")
+	if err != nil {
+		t.Fatalf("failed to read index entry: %v", err)
+	}
+	if !strings.Contains(diff, ".."+entry.Hash.String()[:7]+" 100644\n") {
+		t.Errorf("expected the index line to name the staged blob, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "\n+// staged\n") {
+		t.Errorf("expected the hunk to show the staged content, got:\n%s", diff)
+	}
+	if strings.Contains(diff, "unstaged") {
+		t.Errorf("expected unstaged changes to stay out of the diff, got:\n%s", diff)
+	}
+}
+
+func TestClientImpl_GetStagedFiles_ReadsIndex(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	stage := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	stage("old.txt", "one\ntwo\nthree\nfour\n")
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// A new file staged, then deleted from disk
+	stage("added.txt", "staged line\n")
+	if err := os.Remove("added.txt"); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	// A rename staged with an edit, then edited again without staging
+	if _, err := worktree.Move("old.txt", "new.txt"); err != nil {
+		t.Fatalf("failed to git mv: %v", err)
+	}
+	stage("new.txt", "one\ntwo\nthree\nstaged four\n")
+	if err := os.WriteFile("new.txt", []byte("one\ntwo\nthree\nunstaged four\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	staged, err := NewClient().GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+	byPath := make(map[string]StagedFile, len(staged))
+	for _, file := range staged {
+		byPath[file.Path] = file
+	}
+
+	if added := byPath["added.txt"]; added.Status != StatusAdded || !strings.Contains(added.Diff, "\n+staged line\n") {
+		t.Errorf("expected the staged content of added.txt, got %+v", added)
+	}
+	renamed := byPath["new.txt"]
+	if renamed.Status != StatusRenamed || !strings.Contains(renamed.Diff, "\n-four\n+staged four\n") {
+		t.Errorf("expected the staged edit of new.txt, got %+v", renamed)
+	}
+	if strings.Contains(renamed.Diff, "unstaged") {
+		t.Errorf("expected unstaged changes to stay out of the diff, got:\n%s", renamed.Diff)
+	}
+}
+
+func TestClientImpl_GetStagedFiles_ModeChange(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	if err := os.WriteFile("build.sh", []byte("echo build\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := worktree.Add("build.sh"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// chmod +x, with no change to the content
+	if err := os.Chmod("build.sh", 0755); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+	if _, err := worktree.Add("build.sh"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+
+	client := NewClient()
+	staged, err := client.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+	expected := "diff --git a/build.sh b/build.sh\nold mode 100644\nnew mode 100755\n"
+	if len(staged) != 1 || staged[0].Status != StatusModified || staged[0].Diff != expected {
+		t.Fatalf("expected only the mode change %q, got %+v", expected, staged)
+	}
+
+	worktreeDiff, err := client.GetWorktreeDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting worktree diff: %v", err)
+	}
+	if worktreeDiff != expected {
+		t.Errorf("expected worktree diff %q, got %q", expected, worktreeDiff)
+	}
+
+	// An executable that is also edited keeps its index line, without the
+	// mode
+	if err := os.WriteFile("build.sh", []byte("echo build\necho test\n"), 0755); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := worktree.Add("build.sh"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	staged, err = client.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+	if len(staged) != 1 || !strings.HasPrefix(staged[0].Diff, expected+"index ") || !strings.Contains(staged[0].Diff, "\n+echo test\n") {
+		t.Errorf("expected the mode change followed by the content change, got %+v", staged)
+	}
+	if strings.Contains(staged[0].Diff, " 100755\n---") {
+		t.Errorf("expected no mode on the index line, got:\n%s", staged[0].Diff)
+	}
+
+	// A new executable reports its real mode
+	if err := os.WriteFile("deploy.sh", []byte("echo deploy\n"), 0755); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := worktree.Add("deploy.sh"); err != nil {
+		t.Fatalf("failed to git add: %v", err)
+	}
+	staged, err = client.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+	for _, file := range staged {
+		if file.Path == "deploy.sh" && !strings.Contains(file.Diff, "\nnew file mode 100755\n") {
+			t.Errorf("expected deploy.sh to be added as an executable, got:\n%s", file.Diff)
+		}
+	}
+}
+
+func TestClientImpl_IgnoreFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	ignore := "# generated files\n*.lock\ndist/\n!dist/keep.js\n"
+	if err := os.WriteFile(IgnoreFile, []byte(ignore), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", IgnoreFile, err)
+	}
+	stage := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+
+	// Only ignored files staged
+	stage("deps.lock", "pinned\n")
+	stage("dist/app.js", "bundle\n")
+
+	client := NewClient()
+	hasChanges, err := client.HasStagedChanges()
+	if hasChanges || !errors.Is(err, ErrOnlyIgnored) {
+		t.Errorf("expected ErrOnlyIgnored, got %v, %v", hasChanges, err)
+	}
+	if !strings.Contains(ErrOnlyIgnored.Error(), "no relevant staged changes") {
+		t.Errorf("unexpected error text %q", ErrOnlyIgnored)
+	}
+	diff, err := client.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged diff: %v", err)
+	}
+	if diff != "" {
+		t.Errorf("expected an empty diff, got:\n%s", diff)
+	}
+
+	// Files that don't match, or are re-included, still count
+	stage("dist/keep.js", "kept\n")
+	stage("main.go", "package main\n")
+
+	hasChanges, err = client.HasStagedChanges()
+	if !hasChanges || err != nil {
+		t.Errorf("expected staged changes, got %v, %v", hasChanges, err)
+	}
+	files, err := client.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("unexpected error getting staged files: %v", err)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	if expected := []string{"dist/keep.js", "main.go"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected staged files %v, got %v", expected, paths)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected int
+	}{
+		{name: "Identical", old: "a\nb\n", new: "a\nb\n", expected: 100},
+		{name: "Half changed", old: "a\nb\nc\nd\n", new: "a\nb\nx\ny\n", expected: 50},
+		{name: "Lines added", old: "a\n", new: "a\nb\nc\nd\n", expected: 25},
+		{name: "Nothing in common", old: "a\n", new: "b\n", expected: 0},
+		{name: "Both empty", old: "", new: "", expected: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarity([]byte(tt.old), []byte(tt.new)); got != tt.expected {
+				t.Errorf("expected similarity %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClientImpl_AmendWithMessage(t *testing.T) {
+	tempDir := t.TempDir()
+	// Keep the user's real global git config, e.g. commit.gpgsign, out of
+	// the test
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	config, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	config.User.Name = "Test User"
+	config.User.Email = "test@example.com"
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	client := NewClient()
+
+	if _, err := client.GetLastCommitMessage(); err == nil {
+		t.Error("expected an error before the first commit")
+	}
+
+	if err := os.WriteFile("a.txt", []byte("a\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := client.StageFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	if err := client.CommitWithMessage("feat: add a\n\nFirst file.\n", CommitOptions{}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	message, err := client.GetLastCommitMessage()
+	if err != nil {
+		t.Fatalf("unexpected error getting last message: %v", err)
+	}
+	if message != "feat: add a\n\nFirst file." {
+		t.Errorf("unexpected last message %q", message)
+	}
+
+	if err := os.WriteFile("b.txt", []byte("b\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := client.StageFiles([]string{"b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	headCommit := func() *object.Commit {
+		head, err := repo.Head()
+		if err != nil {
+			t.Fatalf("failed to get HEAD: %v", err)
+		}
+		commit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			t.Fatalf("failed to get HEAD commit: %v", err)
+		}
+		return commit
+	}
+	original := headCommit().Author
+
+	// Someone else amends the commit later
+	config.User.Name = "Other User"
+	config.User.Email = "other@example.com"
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	time.Sleep(time.Second)
+	if err := client.AmendWithMessage("feat: add a and b", CommitOptions{SignOff: true}); err != nil {
+		t.Fatalf("failed to amend: %v", err)
+	}
+
+	commit := headCommit()
+	if commit.Message != "feat: add a and b\n\nSigned-off-by: Other User <other@example.com>" {
+		t.Errorf("unexpected amended message %q", commit.Message)
+	}
+	if commit.Author.Name != original.Name || commit.Author.Email != original.Email || !commit.Author.When.Equal(original.When) {
+		t.Errorf("expected the amended commit to keep author %v, got %v", original, commit.Author)
+	}
+	if commit.Committer.Name != "Other User" || !commit.Committer.When.After(original.When) {
+		t.Errorf("expected Other User to commit the amendment now, got %v", commit.Committer)
+	}
+	if commit.NumParents() != 0 {
+		t.Errorf("expected the amended commit to replace the first one, got %d parents", commit.NumParents())
+	}
+	if _, err := commit.File("b.txt"); err != nil {
+		t.Errorf("expected the amended commit to contain b.txt: %v", err)
+	}
+}
+
+func TestClientImpl_GetRecentCommitSubjects(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+	t.Setenv("HOME", tempDir)
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	config, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	config.User.Name = "Test User"
+	config.User.Email = "test@example.com"
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	client := NewClient()
+
+	subjects, err := client.GetRecentCommitSubjects(5)
+	if err != nil || len(subjects) != 0 {
+		t.Fatalf("expected no subjects before the first commit, got %v, %v", subjects, err)
+	}
+
+	messages := []string{"feat: add a\n\nFirst file.\n", "fix: correct b\n", "docs: describe c\n"}
+	for i, message := range messages {
+		name := fmt.Sprintf("file%d.txt", i)
+		if err := os.WriteFile(name, []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if err := client.StageFiles([]string{name}); err != nil {
+			t.Fatalf("failed to stage files: %v", err)
+		}
+		if err := client.CommitWithMessage(message, CommitOptions{}); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	subjects, err = client.GetRecentCommitSubjects(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"docs: describe c", "fix: correct b"}; !reflect.DeepEqual(subjects, expected) {
+		t.Errorf("expected %v, got %v", expected, subjects)
+	}
+
+	subjects, err = client.GetRecentCommitSubjects(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"docs: describe c", "fix: correct b", "feat: add a"}; !reflect.DeepEqual(subjects, expected) {
+		t.Errorf("expected %v, got %v", expected, subjects)
+	}
+}
+
+func TestNewClientAt(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	config, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	config.User.Name = "Test User"
+	config.User.Email = "test@example.com"
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	client := NewClientAt(tempDir)
+
+	isRepo, err := client.IsInsideRepo()
+	if err != nil || !isRepo {
+		t.Fatalf("expected %s to be a repo, got %v, %v", tempDir, isRepo, err)
+	}
+	root, err := client.GetRepoRoot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if root != tempDir {
+		t.Errorf("expected repo root %s, got %s", tempDir, root)
+	}
+
+	if err := client.StageFiles([]string{"main.go"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	diff, err := client.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(diff, "+package main") {
+		t.Errorf("expected the diff to include main.go, got:\n%s", diff)
+	}
+	if err := client.CommitWithMessage("feat: add main\n", CommitOptions{}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	subjects, err := client.GetRecentCommitSubjects(1)
+	if err != nil || !reflect.DeepEqual(subjects, []string{"feat: add main"}) {
+		t.Errorf("expected the commit in %s, got %v, %v", tempDir, subjects, err)
+	}
+
+	if wd, err := os.Getwd(); err != nil || wd != originalWd {
+		t.Errorf("expected the working directory to stay %s, got %s", originalWd, wd)
+	}
+
+	// A client for a directory outside any repository
+	isRepo, err = NewClientAt(t.TempDir()).IsInsideRepo()
+	if err != nil || isRepo {
+		t.Errorf("expected no repo, got %v, %v", isRepo, err)
+	}
+}
+
+// initRepoWithCommit creates a repository at dir with one commit of file
+func initRepoWithCommit(t *testing.T, dir, file, subject string) {
+	t.Helper()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add(file); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if _, err := worktree.Commit(subject, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+}
+
+func TestClientImpl_DotGitFile(t *testing.T) {
+	writeFile := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	t.Run("Linked worktree", func(t *testing.T) {
+		// main/ is the repository, and main/.worktrees/feature a linked
+		// worktree whose .git file points into main/.git/worktrees
+		main := t.TempDir()
+		initRepoWithCommit(t, main, "main.go", "feat: add main")
+		head, err := os.ReadFile(filepath.Join(main, ".git", "HEAD"))
+		if err != nil {
+			t.Fatalf("failed to read HEAD: %v", err)
+		}
+		worktree := filepath.Join(main, ".worktrees", "feature")
+		gitDir := filepath.Join(main, ".git", "worktrees", "feature")
+		writeFile(t, filepath.Join(gitDir, "HEAD"), string(head))
+		writeFile(t, filepath.Join(gitDir, "commondir"), "../..\n")
+		writeFile(t, filepath.Join(gitDir, "gitdir"), filepath.Join(worktree, ".git")+"\n")
+		writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+gitDir+"\n")
+		writeFile(t, filepath.Join(worktree, "pkg", "main.go"), "package main\n")
+
+		client := NewClientAt(filepath.Join(worktree, "pkg"))
+		root, err := client.GetRepoRoot()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if root != worktree {
+			t.Errorf("expected repo root %s, got %s", worktree, root)
+		}
+		// The commits are read from the main repository
+		subjects, err := client.GetRecentCommitSubjects(1)
+		if err != nil || !reflect.DeepEqual(subjects, []string{"feat: add main"}) {
+			t.Errorf("expected the main repository's commit, got %v, %v", subjects, err)
+		}
+	})
+
+	t.Run("Submodule", func(t *testing.T) {
+		// super/lib is a submodule whose repository lives in
+		// super/.git/modules/lib
+		super := t.TempDir()
+		initRepoWithCommit(t, super, "README.md", "docs: add readme")
+		lib := t.TempDir()
+		initRepoWithCommit(t, lib, "lib.go", "feat: add lib")
+		if err := os.MkdirAll(filepath.Join(super, ".git", "modules"), 0755); err != nil {
+			t.Fatalf("failed to create modules dir: %v", err)
+		}
+		if err := os.Rename(filepath.Join(lib, ".git"), filepath.Join(super, ".git", "modules", "lib")); err != nil {
+			t.Fatalf("failed to move the submodule's git dir: %v", err)
+		}
+		writeFile(t, filepath.Join(super, "lib", ".git"), "gitdir: ../.git/modules/lib\n")
+		writeFile(t, filepath.Join(super, "lib", "lib.go"), "lib.go\n")
+
+		client := NewClientAt(filepath.Join(super, "lib"))
+		root, err := client.GetRepoRoot()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := filepath.Join(super, "lib"); root != expected {
+			t.Errorf("expected repo root %s, got %s", expected, root)
+		}
+		subjects, err := client.GetRecentCommitSubjects(1)
+		if err != nil || !reflect.DeepEqual(subjects, []string{"feat: add lib"}) {
+			t.Errorf("expected the submodule's commit, got %v, %v", subjects, err)
+		}
+	})
+}
+
+func TestClientImpl_IsMidMergeOrRebase(t *testing.T) {
+	tests := []struct {
+		name     string
+		create   func(gitDir string) error
+		expected bool
+	}{
+		{name: "Clean", create: func(string) error { return nil }},
+		{
+			name: "Merge",
+			create: func(gitDir string) error {
+				return os.WriteFile(filepath.Join(gitDir, "MERGE_HEAD"), []byte("0123\n"), 0644)
+			},
+			expected: true,
+		},
+		{
+			name:     "Interactive rebase",
+			create:   func(gitDir string) error { return os.Mkdir(filepath.Join(gitDir, "rebase-merge"), 0755) },
+			expected: true,
+		},
+		{
+			name:     "Apply rebase",
+			create:   func(gitDir string) error { return os.Mkdir(filepath.Join(gitDir, "rebase-apply"), 0755) },
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if _, err := git.PlainInit(dir, false); err != nil {
+				t.Fatalf("failed to git init: %v", err)
+			}
+			if err := tt.create(filepath.Join(dir, ".git")); err != nil {
+				t.Fatalf("failed to simulate the state: %v", err)
+			}
+
+			got, err := NewClientAt(dir).IsMidMergeOrRebase()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Linked worktree", func(t *testing.T) {
+		// A merge in the main worktree doesn't affect a linked one, whose
+		// state lives in its own git dir
+		main := t.TempDir()
+		initRepoWithCommit(t, main, "main.go", "feat: add main")
+		head, err := os.ReadFile(filepath.Join(main, ".git", "HEAD"))
+		if err != nil {
+			t.Fatalf("failed to read HEAD: %v", err)
+		}
+		worktree := t.TempDir()
+		gitDir := filepath.Join(main, ".git", "worktrees", "feature")
+		if err := os.MkdirAll(gitDir, 0755); err != nil {
+			t.Fatalf("failed to create the worktree git dir: %v", err)
+		}
+		files := map[string]string{
+			filepath.Join(gitDir, "HEAD"):             string(head),
+			filepath.Join(gitDir, "commondir"):        "../..\n",
+			filepath.Join(worktree, ".git"):           "gitdir: " + gitDir + "\n",
+			filepath.Join(main, ".git", "MERGE_HEAD"): "0123\n",
+		}
+		for path, content := range files {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", path, err)
+			}
+		}
+
+		client := NewClientAt(worktree)
+		if got, err := client.IsMidMergeOrRebase(); err != nil || got {
+			t.Errorf("expected no merge in the linked worktree, got %v, %v", got, err)
+		}
+		if err := os.Mkdir(filepath.Join(gitDir, "rebase-merge"), 0755); err != nil {
+			t.Fatalf("failed to simulate a rebase: %v", err)
+		}
+		if got, err := client.IsMidMergeOrRebase(); err != nil || !got {
+			t.Errorf("expected a rebase in the linked worktree, got %v, %v", got, err)
+		}
+	})
+}
+
+func TestClientImpl_GitPath(t *testing.T) {
+	main := t.TempDir()
+	initRepoWithCommit(t, main, "main.go", "feat: add main")
+
+	client := NewClientAt(main)
+	for name, expected := range map[string]string{
+		"hooks":                 filepath.Join(main, ".git", "hooks"),
+		"generate-commit-cache": filepath.Join(main, ".git", "generate-commit-cache"),
+	} {
+		if got, err := client.GitPath(name); err != nil || got != expected {
+			t.Errorf("GitPath(%q) = %q, %v, expected %q", name, got, err, expected)
+		}
+	}
+
+	t.Run("Linked worktree", func(t *testing.T) {
+		// Hooks are shared with the main repository, everything else
+		// lives in the worktree's own git dir
+		head, err := os.ReadFile(filepath.Join(main, ".git", "HEAD"))
+		if err != nil {
+			t.Fatalf("failed to read HEAD: %v", err)
+		}
+		worktree := t.TempDir()
+		gitDir := filepath.Join(main, ".git", "worktrees", "feature")
+		if err := os.MkdirAll(gitDir, 0755); err != nil {
+			t.Fatalf("failed to create the worktree git dir: %v", err)
+		}
+		files := map[string]string{
+			filepath.Join(gitDir, "HEAD"):      string(head),
+			filepath.Join(gitDir, "commondir"): "../..\n",
+			filepath.Join(worktree, ".git"):    "gitdir: " + gitDir + "\n",
+		}
+		for path, content := range files {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", path, err)
+			}
+		}
+
+		client := NewClientAt(worktree)
+		for name, expected := range map[string]string{
+			"hooks":                 filepath.Join(main, ".git", "hooks"),
+			"hooks/pre-commit":      filepath.Join(main, ".git", "hooks", "pre-commit"),
+			"generate-commit-cache": filepath.Join(gitDir, "generate-commit-cache"),
+		} {
+			if got, err := client.GitPath(name); err != nil || got != expected {
+				t.Errorf("GitPath(%q) = %q, %v, expected %q", name, got, err, expected)
+			}
+		}
+	})
+}
+
+func TestClientImpl_GetWorktreeDiff_HeadReads(t *testing.T) {
+	dir := t.TempDir()
+	initRepoWithCommit(t, dir, "a", "feat: add a")
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	// a becomes a directory and new/b is added under a directory HEAD
+	// doesn't have; both are absent from HEAD, not errors
+	if _, err := worktree.Remove("a"); err != nil {
+		t.Fatalf("failed to git rm: %v", err)
+	}
+	for _, path := range []string{"a/b", "new/b"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte("b\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		if _, err := worktree.Add(path); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+
+	client := NewClientAt(dir)
+	diff, err := client.GetWorktreeDiff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"deleted file mode 100644", "+++ b/a/b", "+++ b/new/b"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected worktree diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+
+	// A HEAD blob that can't be read is an error, not a new file
+	hash := plumbing.ComputeHash(plumbing.BlobObject, []byte("a\n")).String()
+	if err := os.Remove(filepath.Join(dir, ".git", "objects", hash[:2], hash[2:])); err != nil {
+		t.Fatalf("failed to remove blob: %v", err)
+	}
+	if _, err := NewClientAt(dir).GetWorktreeDiff(); err == nil {
+		t.Error("expected an error for a missing HEAD blob")
+	}
+}
+
+func TestHeadReader_Content(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	for _, path := range []string{"main.go", "pkg/lib.go"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(path+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		if _, err := worktree.Add(path); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	hash, err := worktree.Commit("feat: add main", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	headTree := func() *object.Tree {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			t.Fatalf("failed to get HEAD commit: %v", err)
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			t.Fatalf("failed to get HEAD tree: %v", err)
+		}
+		return tree
+	}
+	reader := &headReader{repo: repo, tree: headTree()}
+
+	content, err := reader.content("pkg/lib.go")
+	if err != nil || string(content) != "pkg/lib.go\n" {
+		t.Errorf("expected pkg/lib.go's content, got %q, %v", content, err)
+	}
+	for _, path := range []string{"missing.go", "missing/main.go", "main.go/inner"} {
+		if content, err := reader.content(path); err != nil || content != nil {
+			t.Errorf("expected %s to be absent, got %q, %v", path, content, err)
+		}
+	}
+
+	// A subtree that can't be read is an error, not an absent file
+	tree := headTree()
+	entry, err := tree.FindEntry("pkg")
+	if err != nil {
+		t.Fatalf("failed to find pkg: %v", err)
+	}
+	subtree := entry.Hash.String()
+	if err := os.Remove(filepath.Join(dir, ".git", "objects", subtree[:2], subtree[2:])); err != nil {
+		t.Fatalf("failed to remove the subtree: %v", err)
+	}
+	if _, err := (&headReader{repo: repo, tree: tree}).content("pkg/lib.go"); err == nil {
+		t.Error("expected an error for a missing subtree object")
+	}
+}
+
+func TestDetectRenames_FullHashes(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	files := map[string]string{"old.txt": "one\ntwo\nthree\n", "other.txt": "something else entirely\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	hash, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatalf("failed to get HEAD commit: %v", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatalf("failed to get HEAD tree: %v", err)
+	}
+	head := &headReader{repo: repo, tree: tree}
+
+	// Stage a blob whose hash shares old.txt's abbreviation but not its
+	// full hash, holding other.txt's content
+	oldHash := headBlobHash(tree, "old.txt")
+	collision := oldHash
+	collision[len(collision)-1] ^= 0xff
+	if shortHash(collision) != shortHash(oldHash) {
+		t.Fatalf("expected %s to abbreviate like %s", collision, oldHash)
+	}
+	objectPath := func(hash plumbing.Hash) string {
+		name := hash.String()
+		return filepath.Join(dir, ".git", "objects", name[:2], name[2:])
+	}
+	blob, err := os.ReadFile(objectPath(headBlobHash(tree, "other.txt")))
+	if err != nil {
+		t.Fatalf("failed to read other.txt's blob: %v", err)
+	}
+	if err := os.WriteFile(objectPath(collision), blob, 0444); err != nil {
+		t.Fatalf("failed to write the colliding blob: %v", err)
+	}
+
+	idx := &index.Index{Entries: []*index.Entry{{Name: "new.txt", Hash: collision, Size: uint32(len(files["other.txt"]))}}}
+	status := git.Status{
+		"old.txt": &git.FileStatus{Staging: git.Deleted},
+		"new.txt": &git.FileStatus{Staging: git.Added},
+	}
+	renames, err := detectRenames(head, idx, []string{"new.txt", "old.txt"}, status)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(renames) != 0 {
+		t.Errorf("expected no renames for blobs differing past the abbreviation, got %v", renames)
+	}
+}
+
+func TestStagedFile_ModeChangeWithAbbreviationCollision(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	files := map[string]string{"build.sh": "echo build\n", "test.sh": "echo test\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to git add: %v", err)
+		}
+	}
+	hash, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatalf("failed to get HEAD commit: %v", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatalf("failed to get HEAD tree: %v", err)
+	}
+
+	// chmod +x along with new content whose blob hash shares build.sh's
+	// abbreviation
+	oldHash := headBlobHash(tree, "build.sh")
+	collision := oldHash
+	collision[len(collision)-1] ^= 0xff
+	objectPath := func(hash plumbing.Hash) string {
+		name := hash.String()
+		return filepath.Join(dir, ".git", "objects", name[:2], name[2:])
+	}
+	blob, err := os.ReadFile(objectPath(headBlobHash(tree, "test.sh")))
+	if err != nil {
+		t.Fatalf("failed to read test.sh's blob: %v", err)
+	}
+	if err := os.WriteFile(objectPath(collision), blob, 0444); err != nil {
+		t.Fatalf("failed to write the colliding blob: %v", err)
+	}
+	idx := &index.Index{Entries: []*index.Entry{{Name: "build.sh", Hash: collision, Mode: filemode.Executable}}}
+
+	file, ok, err := stagedFile(&headReader{repo: repo, tree: tree}, idx, "build.sh", &git.FileStatus{Staging: git.Modified})
+	if err != nil || !ok {
+		t.Fatalf("expected a diff, got %v", err)
+	}
+	if !strings.Contains(file.Diff, "\n-echo build\n+echo test\n") {
+		t.Errorf("expected the content change after the mode change, got:\n%s", file.Diff)
+	}
 }
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileDiff is the section of a unified diff that belongs to one file
+type FileDiff struct {
+	// Path is the file's path after the change (the "b/" side)
+	Path string
+	// Text is the full section, starting with its "diff --git" line
+	Text string
+}
+
+// SplitDiff splits a multi-file diff into per-file sections. Text before
+// the first "diff --git" line is dropped.
+func SplitDiff(diff string) []FileDiff {
+	var files []FileDiff
+	for _, section := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(section, "diff --git ") {
+			files = append(files, FileDiff{Path: diffHeaderPath(section)})
+		}
+		if len(files) > 0 {
+			files[len(files)-1].Text += section
+		}
+	}
+	return files
+}
+
+// JoinDiff reassembles per-file sections into a single diff
+func JoinDiff(files []FileDiff) string {
+	var sb strings.Builder
+	for _, f := range files {
+		sb.WriteString(f.Text)
+	}
+	return sb.String()
+}
+
+// diffHeaderPath extracts the "b/" path from a "diff --git a/x b/y" line
+func diffHeaderPath(header string) string {
+	header = strings.TrimSuffix(header, "\n")
+	if i := strings.LastIndex(header, " b/"); i != -1 {
+		return header[i+len(" b/"):]
+	}
+	return strings.TrimPrefix(header, "diff --git ")
+}
+
+// FileStat counts the lines changed in one file, like a line of
+// `git diff --stat`
+type FileStat struct {
+	Path string
+	// Status is whether the file was added, modified, deleted or renamed
+	Status     FileStatus
+	Insertions int
+	Deletions  int
+	// Binary is true for binary files, which have no line counts
+	Binary bool
+}
+
+// DiffStats counts the insertions and deletions of each file in diff
+func DiffStats(diff string) []FileStat {
+	var stats []FileStat
+	for _, file := range SplitDiff(diff) {
+		stat := FileStat{Path: file.Path, Status: StatusModified}
+		inHunks := false
+		for _, line := range strings.Split(file.Text, "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				inHunks = true
+			case !inHunks && strings.HasPrefix(line, "new file mode"):
+				stat.Status = StatusAdded
+			case !inHunks && strings.HasPrefix(line, "deleted file mode"):
+				stat.Status = StatusDeleted
+			case !inHunks && strings.HasPrefix(line, "rename from "):
+				stat.Status = StatusRenamed
+			case !inHunks && strings.HasPrefix(line, "copy from "):
+				stat.Status = StatusCopied
+			case !inHunks && strings.HasPrefix(line, "Binary files "):
+				stat.Binary = true
+			case inHunks && strings.HasPrefix(line, "+"):
+				stat.Insertions++
+			case inHunks && strings.HasPrefix(line, "-"):
+				stat.Deletions++
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// StatSummary returns the totals line of `git diff --stat` for stats, e.g.
+// "3 files changed, 42 insertions(+), 10 deletions(-)". Zero insertions or
+// deletions are left out.
+func StatSummary(stats []FileStat) string {
+	insertions, deletions := 0, 0
+	for _, stat := range stats {
+		insertions += stat.Insertions
+		deletions += stat.Deletions
+	}
+	summary := fmt.Sprintf("%d %s changed", len(stats), plural(len(stats), "file", "files"))
+	if insertions > 0 {
+		summary += fmt.Sprintf(", %d %s(+)", insertions, plural(insertions, "insertion", "insertions"))
+	}
+	if deletions > 0 {
+		summary += fmt.Sprintf(", %d %s(-)", deletions, plural(deletions, "deletion", "deletions"))
+	}
+	return summary
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
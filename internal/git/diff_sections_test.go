@@ -0,0 +1,93 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitDiff(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n" +
+		"--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/old.txt b/docs/new.txt\n" +
+		"rename from old.txt\nrename to docs/new.txt\n"
+
+	files := SplitDiff(diff)
+	expected := []FileDiff{
+		{Path: "main.go", Text: "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n"},
+		{Path: "docs/new.txt", Text: "diff --git a/old.txt b/docs/new.txt\nrename from old.txt\nrename to docs/new.txt\n"},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %+v, got %+v", expected, files)
+	}
+
+	if JoinDiff(files) != diff {
+		t.Errorf("expected JoinDiff to round-trip, got %q", JoinDiff(files))
+	}
+}
+
+func TestSplitDiff_Empty(t *testing.T) {
+	if files := SplitDiff(""); len(files) != 0 {
+		t.Errorf("expected no files, got %+v", files)
+	}
+}
+
+func TestDiffStats(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n" +
+		"--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,3 @@\n-a\n+b\n+++c\n ctx\n" +
+		"diff --git a/logo.png b/logo.png\n" +
+		"Binary files a/logo.png and b/logo.png differ\n" +
+		"diff --git a/old.txt b/new.txt\n" +
+		"rename from old.txt\nrename to new.txt\n"
+
+	expected := []FileStat{
+		{Path: "main.go", Status: StatusModified, Insertions: 2, Deletions: 1},
+		{Path: "logo.png", Status: StatusModified, Binary: true},
+		{Path: "new.txt", Status: StatusRenamed},
+	}
+	if stats := DiffStats(diff); !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestDiffStats_Status(t *testing.T) {
+	diff := "diff --git a/new.go b/new.go\n" +
+		"new file mode 100644\nindex 0000000..e69de29\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,3 @@\n+package main\n+\n+func main() {}\n" +
+		"diff --git a/main.go b/main.go\n" +
+		"index e69de29..e69de30 100644\n--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,3 @@\n package main\n-var a = 1\n+var a = 2\n" +
+		"diff --git a/old.go b/old.go\n" +
+		"deleted file mode 100644\nindex e69de29..0000000\n--- a/old.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-package main\n-var b = 1\n"
+
+	expected := []FileStat{
+		{Path: "new.go", Status: StatusAdded, Insertions: 3},
+		{Path: "main.go", Status: StatusModified, Insertions: 1, Deletions: 1},
+		{Path: "old.go", Status: StatusDeleted, Deletions: 2},
+	}
+	stats := DiffStats(diff)
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+	if summary := StatSummary(stats); summary != "3 files changed, 4 insertions(+), 3 deletions(-)" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+}
+
+func TestStatSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    []FileStat
+		expected string
+	}{
+		{name: "Singular", stats: []FileStat{{Insertions: 1, Deletions: 1}}, expected: "1 file changed, 1 insertion(+), 1 deletion(-)"},
+		{name: "Insertions only", stats: []FileStat{{Insertions: 4}, {Insertions: 2}}, expected: "2 files changed, 6 insertions(+)"},
+		{name: "Deletions only", stats: []FileStat{{Deletions: 5}}, expected: "1 file changed, 5 deletions(-)"},
+		{name: "No line changes", stats: []FileStat{{Binary: true}}, expected: "1 file changed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatSummary(tt.stats); got != tt.expected {
+				t.Errorf("StatSummary() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
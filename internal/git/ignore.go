@@ -0,0 +1,73 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreFile is the file at the repository root whose .gitignore-style
+// patterns exclude staged files from the diff. Unlike exclude_paths in the
+// config, it is committed and shared with the rest of the team.
+const IgnoreFile = ".commitgenignore"
+
+// ErrOnlyIgnored is returned by HasStagedChanges when files are staged but
+// every one of them matches IgnoreFile
+var ErrOnlyIgnored = errors.New("no relevant staged changes: every staged file matches " + IgnoreFile)
+
+// IgnoreMatcher matches repo-relative paths against .gitignore-style
+// patterns, as used by IgnoreFile and exclude_paths. A nil matcher ignores
+// nothing.
+type IgnoreMatcher struct {
+	matcher gitignore.Matcher
+}
+
+// NewIgnoreMatcher returns a matcher for .gitignore-style patterns. Blank
+// patterns and "#" comments are skipped.
+func NewIgnoreMatcher(patterns []string) *IgnoreMatcher {
+	var parsed []gitignore.Pattern
+	for _, pattern := range patterns {
+		pattern = strings.TrimRight(pattern, " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		parsed = append(parsed, gitignore.ParsePattern(pattern, nil))
+	}
+	return &IgnoreMatcher{matcher: gitignore.NewMatcher(parsed)}
+}
+
+// readIgnoreFile reads IgnoreFile from the repository root. It returns nil
+// if there is no such file.
+func readIgnoreFile(root string) (*IgnoreMatcher, error) {
+	content, err := os.ReadFile(filepath.Join(root, IgnoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+	return NewIgnoreMatcher(lines), nil
+}
+
+// Ignored reports whether the repo-relative path matches a pattern
+func (m *IgnoreMatcher) Ignored(path string) bool {
+	if m == nil {
+		return false
+	}
+	return m.matcher.Match(strings.Split(path, "/"), false)
+}
@@ -0,0 +1,53 @@
+package git
+
+import "testing"
+
+func TestIgnoreMatcher(t *testing.T) {
+	tests := []struct {
+		path     string
+		pattern  string
+		expected bool
+	}{
+		{path: "package-lock.json", pattern: "package-lock.json", expected: true},
+		{path: "web/package-lock.json", pattern: "package-lock.json", expected: true},
+		{path: "go.sum", pattern: "*.sum", expected: true},
+		{path: "api/user.pb.go", pattern: "*.pb.go", expected: true},
+		{path: "dist/app.js", pattern: "dist/", expected: true},
+		{path: "web/dist/app.js", pattern: "dist/", expected: true},
+		{path: "dist", pattern: "dist/", expected: false},
+		{path: "web/dist/app.js", pattern: "/dist", expected: false},
+		{path: "dist/app.js", pattern: "/dist", expected: true},
+		{path: "docs/generated/api.md", pattern: "docs/generated", expected: true},
+		{path: "src/docs/generated/api.md", pattern: "docs/generated", expected: false},
+		{path: "src/a/b/snapshots/x.snap", pattern: "**/snapshots/*.snap", expected: true},
+		{path: "vendor/github.com/x/y.go", pattern: "vendor/**", expected: true},
+		{path: "main.go", pattern: "*.sum", expected: false},
+		{path: "main.go", pattern: "ma?n.go", expected: true},
+		{path: "main.go", pattern: "# main.go", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := NewIgnoreMatcher([]string{tt.pattern}).Ignored(tt.path); got != tt.expected {
+				t.Errorf("Ignored(%q) with %q = %v, expected %v", tt.path, tt.pattern, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIgnoreMatcher_Negation(t *testing.T) {
+	// exclude_paths and .commitgenignore share gitignore semantics, so a
+	// later "!" pattern re-includes a path
+	matcher := NewIgnoreMatcher([]string{"docs/", "!docs/README.md"})
+	if !matcher.Ignored("docs/api.md") {
+		t.Error("expected docs/api.md to be ignored")
+	}
+	if matcher.Ignored("docs/README.md") {
+		t.Error("expected docs/README.md to be re-included")
+	}
+
+	var none *IgnoreMatcher
+	if none.Ignored("main.go") {
+		t.Error("expected a nil matcher to ignore nothing")
+	}
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// formatMode returns mode as git writes it in diff headers, e.g. "100755"
+func formatMode(mode filemode.FileMode) string {
+	return strconv.FormatUint(uint64(mode), 8)
+}
+
+// headMode returns the mode of path in the HEAD tree, or filemode.Empty if
+// it isn't there
+func headMode(tree *object.Tree, path string) filemode.FileMode {
+	if tree == nil {
+		return filemode.Empty
+	}
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		return filemode.Empty
+	}
+	return entry.Mode
+}
+
+// indexMode returns the mode of path's staged entry, or filemode.Regular
+// if it isn't staged
+func indexMode(idx *index.Index, path string) filemode.FileMode {
+	if idx == nil {
+		return filemode.Regular
+	}
+	entry, err := idx.Entry(path)
+	if err != nil {
+		return filemode.Regular
+	}
+	return entry.Mode
+}
+
+// worktreeMode returns the mode git would stage for the file at fullPath:
+// symlink, executable or regular
+func worktreeMode(fullPath string) (filemode.FileMode, error) {
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return filemode.Empty, err
+	}
+	return filemode.NewFromOSFileMode(info.Mode())
+}
+
+// readWorktreeFile returns the content git stores for the file at
+// fullPath: the target of a symlink, or the file's content
+func readWorktreeFile(fullPath string, mode filemode.FileMode) ([]byte, error) {
+	if mode == filemode.Symlink {
+		target, err := os.Readlink(fullPath)
+		return []byte(target), err
+	}
+	return os.ReadFile(fullPath)
+}
+
+// writeModeChange writes git's "old mode"/"new mode" lines if a file's
+// mode changed, and reports whether it did
+func writeModeChange(sb *strings.Builder, oldMode, newMode filemode.FileMode) bool {
+	if oldMode == newMode || oldMode == filemode.Empty || newMode == filemode.Empty {
+		return false
+	}
+	sb.WriteString("old mode ")
+	sb.WriteString(formatMode(oldMode))
+	sb.WriteString("\nnew mode ")
+	sb.WriteString(formatMode(newMode))
+	sb.WriteString("\n")
+	return true
+}
@@ -0,0 +1,197 @@
+package git
+
+import (
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// renameThreshold is the similarity, in percent, at which a deleted and an
+// added file are taken to be a rename, matching git's default
+const renameThreshold = 50
+
+// maxRenameCandidates caps the deleted/added pairs compared by content, so
+// staging many unrelated files stays fast. Identical files are always
+// paired.
+const maxRenameCandidates = 1000
+
+// detectRenames pairs staged deletions with staged additions that are the
+// same file moved, and returns the old path of each renamed new path. An
+// addition with the same blob as a deletion is a rename; otherwise the
+// most similar deletion is, if at least renameThreshold percent similar.
+func detectRenames(head *headReader, idx *index.Index, paths []string, status git.Status) (map[string]string, error) {
+	var deleted, added []string
+	for _, filePath := range paths {
+		switch status[filePath].Staging {
+		case git.Deleted:
+			deleted = append(deleted, filePath)
+		case git.Added:
+			added = append(added, filePath)
+		}
+	}
+	if len(deleted) == 0 || len(added) == 0 {
+		return nil, nil
+	}
+
+	renames := make(map[string]string)
+	used := make(map[string]bool)
+	for _, newPath := range added {
+		// Empty files are never paired, as in git
+		if entry, err := idx.Entry(newPath); err != nil || entry.Size == 0 {
+			continue
+		}
+		hash := indexBlobHash(idx, newPath)
+		for _, oldPath := range deleted {
+			if !used[oldPath] && head.blobHash(oldPath) == hash {
+				renames[newPath] = oldPath
+				used[oldPath] = true
+				break
+			}
+		}
+	}
+	if len(added)*len(deleted) > maxRenameCandidates {
+		return renames, nil
+	}
+
+	oldContents := make(map[string][]byte)
+	for _, oldPath := range deleted {
+		if used[oldPath] {
+			continue
+		}
+		content, err := head.content(oldPath)
+		if err != nil {
+			return nil, err
+		}
+		if !isBinary(content) {
+			oldContents[oldPath] = content
+		}
+	}
+	for _, newPath := range added {
+		if _, ok := renames[newPath]; ok {
+			continue
+		}
+		newContent, err := head.staged(idx, newPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(newContent) == 0 || isBinary(newContent) {
+			continue
+		}
+		best, bestScore := "", renameThreshold-1
+		for _, oldPath := range deleted {
+			oldContent, ok := oldContents[oldPath]
+			if !ok || used[oldPath] {
+				continue
+			}
+			if score := similarity(oldContent, newContent); score > bestScore {
+				best, bestScore = oldPath, score
+			}
+		}
+		if best != "" {
+			renames[newPath] = best
+			used[best] = true
+		}
+	}
+	return renames, nil
+}
+
+// detectCopies finds staged additions that copy a file from HEAD, and
+// returns the source of each copied new path. Like git's copy detection,
+// an addition is a copy of a file with the same blob anywhere in HEAD, or
+// of the most similar staged modification if at least renameThreshold
+// percent similar. Additions in renames are skipped.
+func detectCopies(head *headReader, idx *index.Index, paths []string, status git.Status, renames map[string]string) (map[string]string, error) {
+	var added, modified []string
+	for _, filePath := range paths {
+		switch status[filePath].Staging {
+		case git.Added:
+			if _, ok := renames[filePath]; !ok {
+				added = append(added, filePath)
+			}
+		case git.Modified:
+			modified = append(modified, filePath)
+		}
+	}
+	if len(added) == 0 || head.tree == nil {
+		return nil, nil
+	}
+
+	headPaths, err := head.pathsByHash()
+	if err != nil {
+		return nil, err
+	}
+	copies := make(map[string]string)
+	var remaining []string
+	for _, newPath := range added {
+		// Empty files are never paired, as in git
+		entry, err := idx.Entry(newPath)
+		if err != nil || entry.Size == 0 {
+			continue
+		}
+		if source, ok := headPaths[entry.Hash]; ok {
+			copies[newPath] = source
+			continue
+		}
+		remaining = append(remaining, newPath)
+	}
+	if len(remaining) == 0 || len(modified) == 0 || len(remaining)*len(modified) > maxRenameCandidates {
+		return copies, nil
+	}
+
+	sources := make(map[string][]byte)
+	for _, source := range modified {
+		content, err := head.content(source)
+		if err != nil {
+			return nil, err
+		}
+		if !isBinary(content) {
+			sources[source] = content
+		}
+	}
+	for _, newPath := range remaining {
+		newContent, err := head.staged(idx, newPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(newContent) == 0 || isBinary(newContent) {
+			continue
+		}
+		best, bestScore := "", renameThreshold-1
+		for _, source := range modified {
+			content, ok := sources[source]
+			if !ok {
+				continue
+			}
+			if score := similarity(content, newContent); score > bestScore {
+				best, bestScore = source, score
+			}
+		}
+		if best != "" {
+			copies[newPath] = best
+		}
+	}
+	return copies, nil
+}
+
+// similarity returns how much of the larger of two contents is unchanged
+// in the other, as a percentage of lines
+func similarity(oldContent, newContent []byte) int {
+	lines := diffLines(string(oldContent), string(newContent))
+	oldLines, newLines, equal := 0, 0, 0
+	for _, line := range lines {
+		switch line.op {
+		case diffmatchpatch.DiffEqual:
+			equal++
+			oldLines++
+			newLines++
+		case diffmatchpatch.DiffDelete:
+			oldLines++
+		case diffmatchpatch.DiffInsert:
+			newLines++
+		}
+	}
+	if total := max(oldLines, newLines); total > 0 {
+		return equal * 100 / total
+	}
+	return 100
+}
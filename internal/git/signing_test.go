@@ -0,0 +1,200 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+	formatconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+func TestReadSigningConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected signingConfig
+	}{
+		{
+			name:     "Not configured",
+			config:   "",
+			expected: signingConfig{format: "openpgp"},
+		},
+		{
+			name:     "GPG with key",
+			config:   "[commit]\n\tgpgsign = true\n[user]\n\tsigningkey = ABCD1234\n",
+			expected: signingConfig{enabled: true, format: "openpgp", key: "ABCD1234"},
+		},
+		{
+			name:     "GPG program",
+			config:   "[commit]\n\tgpgSign = true\n[gpg]\n\tprogram = gpg2\n",
+			expected: signingConfig{enabled: true, format: "openpgp", program: "gpg2"},
+		},
+		{
+			name:     "SSH",
+			config:   "[commit]\n\tgpgsign = true\n[gpg]\n\tformat = ssh\n\tprogram = gpg2\n[gpg \"ssh\"]\n\tprogram = /usr/bin/ssh-keygen\n[user]\n\tsigningKey = ~/.ssh/id_ed25519.pub\n",
+			expected: signingConfig{enabled: true, format: "ssh", key: "~/.ssh/id_ed25519.pub", program: "/usr/bin/ssh-keygen"},
+		},
+		{
+			name:     "Disabled",
+			config:   "[commit]\n\tgpgsign = false\n[user]\n\tsigningkey = ABCD1234\n",
+			expected: signingConfig{format: "openpgp", key: "ABCD1234"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := formatconfig.New()
+			if err := formatconfig.NewDecoder(strings.NewReader(tt.config)).Decode(raw); err != nil {
+				t.Fatalf("failed to decode config: %v", err)
+			}
+			if got := readSigningConfig(raw); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        signingConfig
+		expectedArgs  []string
+		expectedError string
+	}{
+		{
+			name:         "GPG defaults to the committer",
+			config:       signingConfig{enabled: true, format: "openpgp"},
+			expectedArgs: []string{"gpg", "--status-fd=2", "-bsau", "Test User <test@example.com>"},
+		},
+		{
+			name:         "GPG key and program",
+			config:       signingConfig{enabled: true, format: "openpgp", key: "ABCD1234", program: "gpg2"},
+			expectedArgs: []string{"gpg2", "--status-fd=2", "-bsau", "ABCD1234"},
+		},
+		{
+			name:         "SSH key file",
+			config:       signingConfig{enabled: true, format: "ssh", key: "/keys/id_ed25519"},
+			expectedArgs: []string{"ssh-keygen", "-Y", "sign", "-n", "git", "-f", "/keys/id_ed25519"},
+		},
+		{
+			name:          "SSH without key",
+			config:        signingConfig{enabled: true, format: "ssh"},
+			expectedError: "user.signingkey is not set",
+		},
+		{
+			name:          "SSH literal key",
+			config:        signingConfig{enabled: true, format: "ssh", key: "key::ssh-ed25519 AAAA"},
+			expectedError: "literal user.signingkey is not supported",
+		},
+		{
+			name:          "X.509",
+			config:        signingConfig{enabled: true, format: "x509"},
+			expectedError: `gpg.format "x509" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := newSigner(tt.config, "Test User <test@example.com>")
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ps := signer.(*programSigner)
+			got := append([]string{ps.program}, ps.args...)
+			if strings.Join(got, " ") != strings.Join(tt.expectedArgs, " ") {
+				t.Errorf("expected %q, got %q", tt.expectedArgs, got)
+			}
+		})
+	}
+}
+
+func TestClientImpl_CommitWithMessage_Signed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub signing program is a shell script")
+	}
+	tempDir := t.TempDir()
+	// Keep the user's real global git config out of the test
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get WD: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change to temp dir: %v", err)
+	}
+
+	// A stand-in for gpg that checks its arguments and prints a signature
+	program := filepath.Join(t.TempDir(), "fake-gpg")
+	script := "#!/bin/sh\n[ \"$3\" = ABCD1234 ] || exit 1\ncat > /dev/null\necho '-----BEGIN PGP SIGNATURE-----'\necho 'c2lnbmVk'\necho '-----END PGP SIGNATURE-----'\n"
+	if err := os.WriteFile(program, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write signing program: %v", err)
+	}
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to git init: %v", err)
+	}
+	config, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	config.User.Name = "Test User"
+	config.User.Email = "test@example.com"
+	config.Raw.Section("commit").SetOption("gpgsign", "true")
+	config.Raw.Section("user").SetOption("signingkey", "ABCD1234")
+	config.Raw.Section("gpg").SetOption("program", program)
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	if err := os.WriteFile("a.txt", []byte("a\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	client := NewClient()
+	if err := client.StageFiles([]string{"a.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	if err := client.CommitWithMessage("feat: add a", CommitOptions{}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("failed to get HEAD commit: %v", err)
+	}
+	if !strings.Contains(commit.PGPSignature, "c2lnbmVk") {
+		t.Errorf("expected the commit to be signed, got signature %q", commit.PGPSignature)
+	}
+
+	// A failing signer aborts the commit instead of committing unsigned
+	config.Raw.Section("user").SetOption("signingkey", "WRONG")
+	if err := repo.SetConfig(config); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	if err := os.WriteFile("b.txt", []byte("b\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := client.StageFiles([]string{"b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	if err := client.CommitWithMessage("feat: add b", CommitOptions{}); err == nil || !strings.Contains(err.Error(), "failed to sign commit") {
+		t.Errorf("expected a signing error, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// trailerLine matches a git trailer such as "Signed-off-by: Name <email>"
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9-]+: \S`)
+
+// appendSignOff adds a Signed-off-by trailer for name and email to message
+func appendSignOff(message, name, email string) string {
+	return appendTrailer(message, fmt.Sprintf("Signed-off-by: %s <%s>", name, email))
+}
+
+// appendTrailer adds trailer to message. The trailer joins an existing
+// trailer block, and is not added again if message already has it.
+func appendTrailer(message, trailer string) string {
+	message = strings.TrimRight(message, "\n")
+
+	paragraphs := strings.Split(message, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	if len(paragraphs) > 1 && isTrailerBlock(last) {
+		for _, line := range strings.Split(last, "\n") {
+			if line == trailer {
+				return message
+			}
+		}
+		return message + "\n" + trailer
+	}
+	return message + "\n\n" + trailer
+}
+
+// isTrailerBlock reports whether every line of paragraph is a trailer
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+func TestAppendSignOff(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "Subject only",
+			message:  "feat: add login",
+			expected: "feat: add login\n\nSigned-off-by: Test User <test@example.com>",
+		},
+		{
+			name:     "Subject and body",
+			message:  "feat: add login\n\nUsers asked for it.\n",
+			expected: "feat: add login\n\nUsers asked for it.\n\nSigned-off-by: Test User <test@example.com>",
+		},
+		{
+			name:     "Existing trailers",
+			message:  "feat: add login\n\nCo-authored-by: Other <other@example.com>",
+			expected: "feat: add login\n\nCo-authored-by: Other <other@example.com>\nSigned-off-by: Test User <test@example.com>",
+		},
+		{
+			name:     "Already signed off",
+			message:  "feat: add login\n\nSigned-off-by: Test User <test@example.com>\n",
+			expected: "feat: add login\n\nSigned-off-by: Test User <test@example.com>",
+		},
+		{
+			name:     "Signed off by someone else",
+			message:  "feat: add login\n\nSigned-off-by: Other <other@example.com>",
+			expected: "feat: add login\n\nSigned-off-by: Other <other@example.com>\nSigned-off-by: Test User <test@example.com>",
+		},
+		{
+			name:     "Body that looks like a trailer",
+			message:  "fix: handle errors\n\nNote: the retry is now bounded.\nIt used to loop forever.",
+			expected: "fix: handle errors\n\nNote: the retry is now bounded.\nIt used to loop forever.\n\nSigned-off-by: Test User <test@example.com>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appendSignOff(tt.message, "Test User", "test@example.com")
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClientImpl_CommitWithMessage_CoAuthors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	initRepoWithCommit(t, dir, "a.txt", "feat: add a")
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	cfg.User.Name = "Test User"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	client := NewClientAt(dir)
+	if err := client.StageFiles([]string{"b.txt"}); err != nil {
+		t.Fatalf("failed to stage files: %v", err)
+	}
+	opts := CommitOptions{
+		SignOff:   true,
+		CoAuthors: []string{"Ada Lovelace <ada@example.com>", "Alan Turing <alan@example.com>"},
+	}
+	if err := client.CommitWithMessage("feat: add b\n\nCo-authored-by: Ada Lovelace <ada@example.com>\n", opts); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	message, err := client.GetLastCommitMessage()
+	if err != nil {
+		t.Fatalf("failed to read the commit: %v", err)
+	}
+	expected := "feat: add b\n\n" +
+		"Co-authored-by: Ada Lovelace <ada@example.com>\n" +
+		"Co-authored-by: Alan Turing <alan@example.com>\n" +
+		"Signed-off-by: Test User <test@example.com>"
+	if message != expected {
+		t.Errorf("expected %q, got %q", expected, message)
+	}
+}
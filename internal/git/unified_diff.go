@@ -0,0 +1,138 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContextLines is the number of unchanged lines shown around each
+// change, matching git's default
+const diffContextLines = 3
+
+// diffLine is a single line of a line-level diff
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+	// oldIdx and newIdx are the number of old/new lines preceding this line
+	oldIdx int
+	newIdx int
+	// noEOL is true for the last line of a file that doesn't end with a
+	// newline
+	noEOL bool
+}
+
+// writeUnifiedHunks writes the @@ hunks turning oldContent into newContent,
+// in the same format as `git diff`. Nothing is written if the contents are
+// identical.
+func writeUnifiedHunks(sb *strings.Builder, oldContent, newContent string) {
+	lines := diffLines(oldContent, newContent)
+
+	for start := 0; start < len(lines); {
+		// Find the next change
+		first := start
+		for first < len(lines) && lines[first].op == diffmatchpatch.DiffEqual {
+			first++
+		}
+		if first == len(lines) {
+			return
+		}
+
+		// Extend the hunk while the gap between changes can be bridged
+		// by the context of both sides
+		last := first
+		for i := first + 1; i < len(lines); i++ {
+			if lines[i].op == diffmatchpatch.DiffEqual {
+				continue
+			}
+			if i-last-1 > 2*diffContextLines {
+				break
+			}
+			last = i
+		}
+
+		hunkStart := max(first-diffContextLines, start)
+		hunkEnd := min(last+diffContextLines+1, len(lines))
+		writeHunk(sb, lines[hunkStart:hunkEnd])
+		start = hunkEnd
+	}
+}
+
+// writeHunk writes a single hunk header followed by its lines
+func writeHunk(sb *strings.Builder, hunk []diffLine) {
+	oldCount, newCount := 0, 0
+	for _, l := range hunk {
+		if l.op != diffmatchpatch.DiffInsert {
+			oldCount++
+		}
+		if l.op != diffmatchpatch.DiffDelete {
+			newCount++
+		}
+	}
+
+	sb.WriteString("@@ -")
+	sb.WriteString(formatHunkRange(hunk[0].oldIdx, oldCount))
+	sb.WriteString(" +")
+	sb.WriteString(formatHunkRange(hunk[0].newIdx, newCount))
+	sb.WriteString(" @@\n")
+
+	for _, l := range hunk {
+		switch l.op {
+		case diffmatchpatch.DiffEqual:
+			sb.WriteString(" ")
+		case diffmatchpatch.DiffDelete:
+			sb.WriteString("-")
+		case diffmatchpatch.DiffInsert:
+			sb.WriteString("+")
+		}
+		sb.WriteString(l.text)
+		sb.WriteString("\n")
+		if l.noEOL {
+			sb.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
+
+// formatHunkRange formats a hunk range the way git does: the count is
+// omitted when it is 1, and an empty range points at the preceding line
+func formatHunkRange(preceding, count int) string {
+	if count == 0 {
+		return strconv.Itoa(preceding) + ",0"
+	}
+	if count == 1 {
+		return strconv.Itoa(preceding + 1)
+	}
+	return strconv.Itoa(preceding+1) + "," + strconv.Itoa(count)
+}
+
+// diffLines computes a line-level diff (Myers, via go-git) and flattens the
+// result into individual lines with their positions
+func diffLines(oldContent, newContent string) []diffLine {
+	var lines []diffLine
+	oldIdx, newIdx := 0, 0
+	for _, d := range diff.Do(oldContent, newContent) {
+		texts := splitLines(d.Text)
+		for i, text := range texts {
+			// Only the last line of a file can lack its newline
+			noEOL := i == len(texts)-1 && !strings.HasSuffix(d.Text, "\n")
+			lines = append(lines, diffLine{op: d.Type, text: text, oldIdx: oldIdx, newIdx: newIdx, noEOL: noEOL})
+			if d.Type != diffmatchpatch.DiffInsert {
+				oldIdx++
+			}
+			if d.Type != diffmatchpatch.DiffDelete {
+				newIdx++
+			}
+		}
+	}
+	return lines
+}
+
+// splitLines splits text into lines without their terminating newlines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
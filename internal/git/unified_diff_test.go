@@ -0,0 +1,113 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteUnifiedHunks(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "Identical",
+			old:      "a\nb\nc\n",
+			new:      "a\nb\nc\n",
+			expected: "",
+		},
+		{
+			name: "Single line change with context",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: "@@ -2,7 +2,7 @@\n" +
+				" 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "Distant changes produce separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: "@@ -1,4 +1,4 @@\n" +
+				"-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n" +
+				" 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name: "Changes six lines apart share a hunk",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n",
+			new:  "1\n2\n3\n4\n5\nsix\n7\n8\n9\n10\n11\n12\nthirteen\n14\n15\n16\n",
+			expected: "@@ -3,14 +3,14 @@\n" +
+				" 3\n 4\n 5\n-6\n+six\n 7\n 8\n 9\n 10\n 11\n 12\n-13\n+thirteen\n 14\n 15\n 16\n",
+		},
+		{
+			name: "Changes seven lines apart get separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n",
+			new:  "1\n2\n3\n4\n5\nsix\n7\n8\n9\n10\n11\n12\n13\nfourteen\n15\n16\n17\n",
+			expected: "@@ -3,7 +3,7 @@\n" +
+				" 3\n 4\n 5\n-6\n+six\n 7\n 8\n 9\n" +
+				"@@ -11,7 +11,7 @@\n" +
+				" 11\n 12\n 13\n-14\n+fourteen\n 15\n 16\n 17\n",
+		},
+		{
+			name: "Insertion at start",
+			old:  "a\nb\n",
+			new:  "new\na\nb\n",
+			expected: "@@ -1,2 +1,3 @@\n" +
+				"+new\n a\n b\n",
+		},
+		{
+			name:     "New file",
+			old:      "",
+			new:      "hello\nworld\n",
+			expected: "@@ -0,0 +1,2 @@\n+hello\n+world\n",
+		},
+		{
+			name:     "Deleted file",
+			old:      "bye\n",
+			new:      "",
+			expected: "@@ -1 +0,0 @@\n-bye\n",
+		},
+		{
+			name:     "Trailing newline adds no empty line",
+			old:      "a\n",
+			new:      "a\nb\n",
+			expected: "@@ -1 +1,2 @@\n a\n+b\n",
+		},
+		{
+			name:     "New file without trailing newline",
+			old:      "",
+			new:      "hello\nworld",
+			expected: "@@ -0,0 +1,2 @@\n+hello\n+world\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "Newline added at end of file",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:     "Newline removed at end of file",
+			old:      "a\nb\n",
+			new:      "a\nc",
+			expected: "@@ -1,2 +1,2 @@\n a\n-b\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "Unchanged last line without newline",
+			old:      "a\nb",
+			new:      "x\nb",
+			expected: "@@ -1,2 +1,2 @@\n-a\n+x\n b\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			writeUnifiedHunks(&sb, tt.old, tt.new)
+			if sb.String() != tt.expected {
+				t.Errorf("unexpected hunks\nexpected:\n%s\ngot:\n%s", tt.expected, sb.String())
+			}
+		})
+	}
+}
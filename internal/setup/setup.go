@@ -0,0 +1,258 @@
+// Package setup wires the git client, the config and the AI client into an
+// app.App. It is shared by the generate-commit CLI and pkg/commitgen, so
+// the public package doesn't have to expose the App.
+package setup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/app"
+	"ai-commit-message-generator/internal/config"
+	"ai-commit-message-generator/internal/git"
+)
+
+// ErrNoAPIKey is returned when the configured provider needs an API key
+// and none is set in the environment or the config. The error returned is
+// a *NoAPIKeyError naming the provider's variable; match it with errors.Is.
+var ErrNoAPIKey = errors.New("API key is not set and not found in config")
+
+// NoAPIKeyError reports the provider missing an API key and the
+// environment variable it's read from
+type NoAPIKeyError struct {
+	Provider string
+	EnvVar   string
+}
+
+func (e *NoAPIKeyError) Error() string {
+	return fmt.Sprintf("API key environment variable %s is not set and not found in config for provider %s", e.EnvVar, e.Provider)
+}
+
+// Is makes errors.Is(err, ErrNoAPIKey) match
+func (e *NoAPIKeyError) Is(target error) bool {
+	return target == ErrNoAPIKey
+}
+
+// NoAPIKey returns the ErrNoAPIKey error for cfg's provider
+func NoAPIKey(cfg *config.Config) error {
+	return &NoAPIKeyError{Provider: cfg.Provider, EnvVar: config.APIKeyEnv(cfg.Provider)}
+}
+
+// Options select the repository, config and model an App is wired up with
+type Options struct {
+	// Dir is a directory in the repository to work on. Empty uses the
+	// current working directory.
+	Dir string
+	// Profile is the provider profile from the config to use, if any
+	Profile string
+	// Diff is a unified diff to read from the App's Input instead of git
+	Diff string
+	// DryRun needs no API key, as the model is never called
+	DryRun bool
+	// Offline uses the heuristic client instead of the configured provider
+	Offline bool
+	// Client generates the messages instead of the configured provider.
+	// Its responses are not cached.
+	Client ai.Client
+	// ModelName, BaseURL and TimeoutSeconds override the configured
+	// provider's model, endpoint and request timeout when set
+	ModelName      string
+	BaseURL        string
+	TimeoutSeconds int
+	// Status receives progress messages and warnings. A nil Status
+	// discards them.
+	Status io.Writer
+	// Color prints the model's retry notices on Status in color
+	Color bool
+	// Debug, if set, receives the raw requests to and responses from the
+	// model
+	Debug io.Writer
+}
+
+// NewApp loads the config and wires up an App with its AI client
+func NewApp(opts Options) (*app.App, error) {
+	if opts.Status == nil {
+		opts.Status = io.Discard
+	}
+	gitClient, configLoader, cfg, err := load(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var aiClient ai.Client
+	switch {
+	case opts.Client != nil:
+		aiClient = opts.Client
+		cfg.Cache = false
+	case opts.Offline:
+		aiClient = ai.NewHeuristicClient()
+		// Heuristic messages must not be served later in place of the model's
+		cfg.Cache = false
+	default:
+		// A dry run never calls the API, so it doesn't need a key
+		if !opts.DryRun && cfg.APIKey == "" {
+			return nil, NoAPIKey(cfg)
+		}
+		aiClient, err = NewAIClient(opts, gitClient, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	application := newApp(opts, gitClient, configLoader, cfg)
+	application.AI = aiClient
+	if opts.Diff != "" {
+		application.Input = strings.NewReader(opts.Diff)
+	}
+	return application, nil
+}
+
+// Lint checks message with the App for opts, without an AI client
+func Lint(message string, opts Options) ([]string, error) {
+	gitClient, configLoader, cfg, err := load(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newApp(opts, gitClient, configLoader, cfg).Lint(message)
+}
+
+// NewAIClient returns the client for the configured provider, trying the
+// fallback providers after it if any are set, and warns on opts.Status if
+// TLS verification is off
+func NewAIClient(opts Options, gitClient git.Client, cfg *config.Config) (ai.Client, error) {
+	client, err := ai.NewClient(aiOptions(opts, gitClient, cfg))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.InsecureSkipVerify {
+		fmt.Fprintln(opts.Status, "Warning: insecure_skip_verify is set, so the API's TLS certificate is not verified")
+	}
+	if len(cfg.FallbackProviders) == 0 {
+		return client, nil
+	}
+
+	fallbacks, err := cfg.Fallbacks()
+	if err != nil {
+		return nil, err
+	}
+	clients := []ai.NamedClient{{Name: providerName(cfg), Client: client}}
+	for _, fallback := range fallbacks {
+		client, err := ai.NewClient(aiOptions(opts, gitClient, fallback))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fallback provider %s: %w", providerName(fallback), err)
+		}
+		clients = append(clients, ai.NamedClient{Name: providerName(fallback), Client: client})
+	}
+	return ai.NewFallbackClient(clients, opts.Status), nil
+}
+
+// aiOptions returns the client settings for cfg
+func aiOptions(opts Options, gitClient git.Client, cfg *config.Config) ai.Options {
+	return ai.Options{
+		Provider:           cfg.Provider,
+		APIKey:             cfg.APIKey,
+		BaseURL:            cfg.BaseURL,
+		Model:              cfg.Model,
+		Timeout:            cfg.GetTimeout(),
+		ExtraOptions:       cfg.ExtraOptions,
+		ExtraHeaders:       cfg.ExtraHeaders,
+		Temperature:        cfg.Temperature,
+		TopP:               cfg.TopP,
+		Proxy:              proxyURL(cfg, gitClient),
+		Jitter:             cfg.GetJitter(),
+		MaxRetries:         cfg.MaxRetries,
+		RetryBaseDelay:     cfg.GetRetryBaseDelay(),
+		Style:              cfg.Style,
+		Language:           cfg.Language,
+		PromptTemplate:     cfg.PromptTemplate,
+		AllowedTypes:       cfg.AllowedTypes,
+		Status:             opts.Status,
+		Color:              opts.Color,
+		Debug:              opts.Debug,
+		CACertFile:         cfg.CACertFile,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+}
+
+// providerName names cfg's provider in fallback notices: the profile and
+// provider, e.g. "cloud (openai)", or just the provider
+func providerName(cfg *config.Config) string {
+	if cfg.ActiveProfile != "" {
+		return fmt.Sprintf("%s (%s)", cfg.ActiveProfile, cfg.Provider)
+	}
+	return cfg.Provider
+}
+
+// load returns the git client and config for opts.Dir
+func load(opts Options) (git.Client, *config.ConfigLoader, *config.Config, error) {
+	gitClient := NewGitClient(opts)
+	configLoader := NewConfigLoader(opts)
+	cfg, err := configLoader.LoadConfig()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return gitClient, configLoader, cfg, nil
+}
+
+// NewGitClient returns the git client for opts.Dir
+func NewGitClient(opts Options) git.Client {
+	if opts.Dir != "" {
+		return git.NewClientAt(opts.Dir)
+	}
+	return git.NewClient()
+}
+
+// NewConfigLoader returns the config loader for opts.Dir, profile and
+// overrides
+func NewConfigLoader(opts Options) *config.ConfigLoader {
+	configLoader := config.NewConfigLoader()
+	configLoader.Profile = opts.Profile
+	configLoader.Dir = opts.Dir
+	configLoader.Overrides = config.Overrides{
+		Model:          opts.ModelName,
+		BaseURL:        opts.BaseURL,
+		TimeoutSeconds: opts.TimeoutSeconds,
+	}
+	return configLoader
+}
+
+// newApp wires up an App without an AI client
+func newApp(opts Options, gitClient git.Client, configLoader *config.ConfigLoader, cfg *config.Config) *app.App {
+	rulesLoader := &config.FileLoader{MergeStrategy: cfg.RulesMergeStrategy, Dir: opts.Dir}
+	application := app.NewApp(gitClient, rulesLoader, configLoader, nil)
+	application.Config = cfg
+	application.Status = opts.Status
+	if application.Status == nil {
+		application.Status = io.Discard
+	}
+	return application
+}
+
+// proxyURL returns the proxy for API requests: proxy_url if set, otherwise
+// the gitProxyFallback
+func proxyURL(cfg *config.Config, gitClient git.Client) string {
+	if cfg.ProxyURL != "" {
+		return cfg.ProxyURL
+	}
+	return gitProxyFallback(gitClient)
+}
+
+// gitProxyFallback returns git's http.proxy setting when no proxy is set in
+// the environment, so users who already configured a proxy for git don't
+// have to repeat it. Environment proxies take precedence.
+func gitProxyFallback(gitClient git.Client) string {
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if os.Getenv(key) != "" {
+			return ""
+		}
+	}
+	proxy, err := gitClient.GetHTTPProxy()
+	if err != nil {
+		return ""
+	}
+	return proxy
+}
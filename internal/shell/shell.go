@@ -0,0 +1,67 @@
+// Package shell runs the commands users configure, such as api_key_command
+// and message_filter_command, through the platform's shell.
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ExitError reports a command that exited non-zero
+type ExitError struct {
+	// Name is the command's name in messages, e.g. api_key_command
+	Name string
+	// Reason is the command's stderr, or its exit status if it printed
+	// nothing
+	Reason string
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Name, e.Reason)
+}
+
+// Run runs command in the shell with stdin as its input, which may be nil,
+// and returns its trimmed output. name is the command in error messages.
+// Running past timeout is an error, as is a non-zero exit, reported as an
+// *ExitError.
+func Run(name, command string, stdin io.Reader, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	// Don't wait for children of the shell that keep the pipes open
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("%s timed out after %v", name, timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		reason := strings.TrimSpace(stderr.String())
+		if reason == "" {
+			reason = exitErr.Error()
+		}
+		return "", &ExitError{Name: name, Reason: reason}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
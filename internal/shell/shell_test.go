@@ -0,0 +1,78 @@
+package shell
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands use sh")
+	}
+
+	tests := []struct {
+		name           string
+		command        string
+		stdin          string
+		expected       string
+		expectedErr    string
+		expectedReason string
+	}{
+		{
+			name:     "Trims output",
+			command:  `printf '  sk-key\n\n'`,
+			expected: "sk-key",
+		},
+		{
+			name:     "Reads stdin",
+			command:  `tr '[:lower:]' '[:upper:]'`,
+			stdin:    "fix: handle nil",
+			expected: "FIX: HANDLE NIL",
+		},
+		{
+			name:           "Exit with stderr",
+			command:        `echo vault is sealed >&2; exit 1`,
+			expectedErr:    "helper failed: vault is sealed",
+			expectedReason: "vault is sealed",
+		},
+		{
+			name:           "Silent exit",
+			command:        `exit 3`,
+			expectedErr:    "helper failed: exit status 3",
+			expectedReason: "exit status 3",
+		},
+		{
+			name:        "Times out",
+			command:     `sleep 5`,
+			expectedErr: "helper timed out after 200ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Run("helper", tt.command, strings.NewReader(tt.stdin), 200*time.Millisecond)
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+				}
+				var exitErr *ExitError
+				if errors.As(err, &exitErr) != (tt.expectedReason != "") {
+					t.Fatalf("expected an *ExitError only for a non-zero exit, got %T", err)
+				}
+				if exitErr != nil && exitErr.Reason != tt.expectedReason {
+					t.Errorf("expected reason %q, got %q", tt.expectedReason, exitErr.Reason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Package version holds build information, set at link time with
+// -ldflags "-X ai-commit-message-generator/internal/version.Version=v1.2.3"
+// (and likewise Commit and Date)
+package version
+
+import "fmt"
+
+// Build information, overridden by the release build
+var (
+	// Version is the release tag, e.g. "v1.2.3"
+	Version = "dev"
+	// Commit is the git commit the binary was built from
+	Commit = "unknown"
+	// Date is when the binary was built, in RFC 3339
+	Date = "unknown"
+)
+
+// String formats the build information for `generate-commit version`, e.g.
+// "generate-commit v1.2.3 (commit abc1234, built 2024-05-01T10:00:00Z)"
+func String() string {
+	return fmt.Sprintf("generate-commit %s (commit %s, built %s)", Version, Commit, Date)
+}
@@ -0,0 +1,41 @@
+package version
+
+import "testing"
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		commit   string
+		date     string
+		expected string
+	}{
+		{
+			name:     "Defaults",
+			version:  "dev",
+			commit:   "unknown",
+			date:     "unknown",
+			expected: "generate-commit dev (commit unknown, built unknown)",
+		},
+		{
+			name:     "Release build",
+			version:  "v1.2.3",
+			commit:   "abc1234",
+			date:     "2024-05-01T10:00:00Z",
+			expected: "generate-commit v1.2.3 (commit abc1234, built 2024-05-01T10:00:00Z)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(version, commit, date string) {
+				Version, Commit, Date = version, commit, date
+			}(Version, Commit, Date)
+			Version, Commit, Date = tt.version, tt.commit, tt.date
+
+			if got := String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
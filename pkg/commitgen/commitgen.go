@@ -0,0 +1,136 @@
+// Package commitgen generates commit messages for the changes in a git
+// repository. It is the API behind the generate-commit CLI, for editor
+// integrations and other tools that embed the generator.
+//
+// Like the CLI, it works on the repository containing the current working
+// directory, or Options.Dir, and reads .commit-generator-config and
+// .git-commit-rules-for-ai from there.
+package commitgen
+
+import (
+	"context"
+	"io"
+
+	"ai-commit-message-generator/internal/ai"
+	"ai-commit-message-generator/internal/app"
+	"ai-commit-message-generator/internal/setup"
+)
+
+// ErrNoAPIKey is returned when the configured provider needs an API key
+// and none is set in the environment or the config. The error returned is
+// a *NoAPIKeyError naming the provider's variable; match it with errors.Is.
+var ErrNoAPIKey = setup.ErrNoAPIKey
+
+// NoAPIKeyError reports the provider missing an API key and the
+// environment variable it's read from
+type NoAPIKeyError = setup.NoAPIKeyError
+
+// Options configure Generate
+type Options struct {
+	// Dir is a directory in the repository to work on. Empty uses the
+	// current working directory.
+	Dir string
+	// Profile is the provider profile from the config to use, if any
+	Profile string
+	// Source selects where the diff comes from, e.g. "all" or
+	// "range:v1.0..v1.1", as for the CLI's --source flag. Empty means the
+	// staged changes.
+	Source string
+	// Diff is a unified diff to describe instead of reading one from git.
+	// It takes precedence over Source, and needs no repository.
+	Diff string
+	// Body asks for a body explaining why the change was made
+	Body bool
+	// NoCache asks the model even if the response cache has this diff
+	NoCache bool
+	// DryRun returns the prompt that would be sent instead of a message,
+	// without calling the model
+	DryRun bool
+	// Offline builds a heuristic message from the diff without calling
+	// the model
+	Offline bool
+	// Model generates the messages instead of the configured provider.
+	// Its responses are not cached.
+	Model Model
+	// ModelName, BaseURL and TimeoutSeconds override the configured
+	// provider's model, endpoint and request timeout when set
+	ModelName      string
+	BaseURL        string
+	TimeoutSeconds int
+	// Status receives progress messages and warnings. A nil Status
+	// discards them.
+	Status io.Writer
+	// Color prints the model's retry notices on Status in color
+	Color bool
+	// Debug, if set, receives the raw requests to and responses from the
+	// model, with the API key redacted
+	Debug io.Writer
+}
+
+// SplitSuggestion is returned by Generate when the model suggests
+// committing the changes separately instead of giving one message
+type SplitSuggestion struct {
+	// Suggestion describes how to split the changes
+	Suggestion string
+}
+
+func (e *SplitSuggestion) Error() string {
+	return "the model suggested splitting the changes: " + e.Suggestion
+}
+
+// setup returns the options to wire up the App for opts
+func (opts Options) setup() setup.Options {
+	var client ai.Client
+	if opts.Model != nil {
+		client = &modelClient{model: opts.Model}
+	}
+	return setup.Options{
+		Dir:            opts.Dir,
+		Profile:        opts.Profile,
+		Diff:           opts.Diff,
+		DryRun:         opts.DryRun,
+		Offline:        opts.Offline,
+		Client:         client,
+		ModelName:      opts.ModelName,
+		BaseURL:        opts.BaseURL,
+		TimeoutSeconds: opts.TimeoutSeconds,
+		Status:         opts.Status,
+		Color:          opts.Color,
+		Debug:          opts.Debug,
+	}
+}
+
+// Generate returns a commit message for the changes selected by opts
+func Generate(ctx context.Context, opts Options) (string, error) {
+	application, err := setup.NewApp(opts.setup())
+	if err != nil {
+		return "", err
+	}
+	source := opts.Source
+	if opts.Diff != "" {
+		source = app.SourceStdin
+	}
+	result, err := application.Run(ctx, app.RunOptions{
+		DryRun:  opts.DryRun,
+		Source:  source,
+		Body:    opts.Body,
+		NoCache: opts.NoCache,
+	})
+	if err != nil {
+		return "", err
+	}
+	if opts.DryRun {
+		return result.Prompt, nil
+	}
+	if result.IsSplitSuggestion {
+		return "", &SplitSuggestion{Suggestion: result.Message}
+	}
+	return result.Message, nil
+}
+
+// Lint checks an existing commit message against the Conventional Commits
+// grammar, the subject length limit, and the repository's structured
+// rules, returning the problems found. It never calls the model.
+func Lint(message string, opts Options) ([]string, error) {
+	return setup.Lint(message, opts.setup())
+}
@@ -0,0 +1,234 @@
+package commitgen
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+const testDiff = `diff --git a/parser/parse.go b/parser/parse.go
+--- a/parser/parse.go
++++ b/parser/parse.go
+@@ -1 +1,2 @@
+ package parser
++// Parse parses input
+`
+
+// recordingModel returns its message and records what it was asked
+type recordingModel struct {
+	message string
+	err     error
+	prompt  string
+
+	diff  string
+	rules string
+}
+
+func (m *recordingModel) GenerateCommitMessage(ctx context.Context, diff, rules string) (string, error) {
+	m.diff, m.rules = diff, rules
+	return m.message, m.err
+}
+
+func (m *recordingModel) BuildPrompt(diff, rules string) string {
+	return m.prompt + diff
+}
+
+// isolateConfig keeps the user's config and API keys out of the test
+func isolateConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OLLAMA_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("AZURE_OPENAI_API_KEY", "")
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get current working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(originalWd); err != nil {
+			t.Errorf("failed to restore working directory: %v", err)
+		}
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		model       *recordingModel
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "Model message",
+			opts:     Options{Diff: testDiff},
+			model:    &recordingModel{message: "docs(parser): document Parse"},
+			expected: "docs(parser): document Parse",
+		},
+		{
+			name:        "Model error",
+			opts:        Options{Diff: testDiff},
+			model:       &recordingModel{err: errors.New("model unavailable")},
+			expectedErr: "model unavailable",
+		},
+		{
+			name:     "Dry run returns the model's prompt",
+			opts:     Options{Diff: testDiff, DryRun: true},
+			model:    &recordingModel{prompt: "Describe:\n"},
+			expected: "Describe:\n" + testDiff,
+		},
+		{
+			name:     "Offline",
+			opts:     Options{Diff: testDiff, Offline: true},
+			expected: "chore(parser): update parse.go",
+		},
+		{
+			name:        "No API key",
+			opts:        Options{Diff: testDiff},
+			expectedErr: "OLLAMA_API_KEY is not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfig(t)
+			if tt.model != nil {
+				tt.opts.Model = tt.model
+			}
+
+			message, err := Generate(context.Background(), tt.opts)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if message != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, message)
+			}
+			if tt.model != nil && !tt.opts.DryRun && tt.model.diff != testDiff {
+				t.Errorf("expected the model to get the diff, got %q", tt.model.diff)
+			}
+		})
+	}
+}
+
+func TestGenerate_Rules(t *testing.T) {
+	isolateConfig(t)
+	model := &recordingModel{message: "docs(parser): document Parse"}
+	if _, err := Generate(context.Background(), Options{Diff: testDiff, Model: model}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The scope hint is derived from the diff's paths
+	if !strings.Contains(model.rules, "parser") {
+		t.Errorf("expected the rules to suggest the parser scope, got %q", model.rules)
+	}
+}
+
+func TestGenerate_Dir(t *testing.T) {
+	isolateConfig(t)
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".git-commit-rules-for-ai"), []byte("Mention the ticket.\n"), 0644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+
+	model := &recordingModel{message: "docs(parser): document Parse"}
+	if _, err := Generate(context.Background(), Options{Dir: repo, Diff: testDiff, Model: model}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(model.rules, "Mention the ticket.") {
+		t.Errorf("expected the rules from %s, got %q", repo, model.rules)
+	}
+}
+
+func TestLint(t *testing.T) {
+	isolateConfig(t)
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".git-commit-rules-for-ai.yaml"), []byte("require_scope: true\n"), 0644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+
+	problems, err := Lint("fix(parser): handle empty input", Options{Dir: repo})
+	if err != nil || len(problems) != 0 {
+		t.Errorf("expected no problems, got %v, %v", problems, err)
+	}
+	problems, err = Lint("fix: handle empty input", Options{Dir: repo})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "no scope") {
+		t.Errorf("expected a missing scope, got %q", problems)
+	}
+}
+
+func TestGenerate_ConflictMarkers(t *testing.T) {
+	isolateConfig(t)
+	repo := t.TempDir()
+	r, err := git.PlainInit(repo, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	conflicted := "package auth\n\n<<<<<<< HEAD\nconst timeout = 30\n=======\nconst timeout = 60\n>>>>>>> feature/login\n"
+	if err := os.WriteFile(filepath.Join(repo, "auth.go"), []byte(conflicted), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	worktree, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("auth.go"); err != nil {
+		t.Fatalf("failed to stage file: %v", err)
+	}
+
+	model := &recordingModel{message: "fix(auth): raise login timeout"}
+	_, err = Generate(context.Background(), Options{Dir: repo, Model: model})
+	if err == nil || !strings.Contains(err.Error(), "conflict markers found in auth.go") {
+		t.Fatalf("expected a conflict markers error naming auth.go, got %v", err)
+	}
+
+	// With conflict_markers set to strip, the markers never reach the model
+	if err := os.WriteFile(filepath.Join(repo, ".commit-generator-config"), []byte(`{"conflict_markers": "strip"}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := Generate(context.Background(), Options{Dir: repo, Model: model}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(model.diff, "<<<<<<<") || !strings.Contains(model.diff, "const timeout = 60") {
+		t.Errorf("expected the markers to be stripped and both sides kept, got:\n%s", model.diff)
+	}
+}
+
+func TestGenerate_NoAPIKeyNamesProviderVariable(t *testing.T) {
+	isolateConfig(t)
+
+	_, err := Generate(context.Background(), Options{Diff: testDiff})
+	if !errors.Is(err, ErrNoAPIKey) {
+		t.Fatalf("expected ErrNoAPIKey, got %v", err)
+	}
+	var noKey *NoAPIKeyError
+	if !errors.As(err, &noKey) {
+		t.Fatalf("expected a *NoAPIKeyError, got %T", err)
+	}
+	if noKey.Provider != "ollama" || noKey.EnvVar != "OLLAMA_API_KEY" {
+		t.Errorf("expected ollama and OLLAMA_API_KEY, got %s and %s", noKey.Provider, noKey.EnvVar)
+	}
+}
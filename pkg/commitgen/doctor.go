@@ -0,0 +1,89 @@
+package commitgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"ai-commit-message-generator/internal/setup"
+)
+
+// Check is the outcome of one of Doctor's checks
+type Check struct {
+	// Name says what was checked
+	Name string
+	// Detail describes what was found, e.g. the config file read
+	Detail string
+	// Err is why the check failed, or nil if it passed
+	Err error
+}
+
+// Doctor checks that generating a message can work in the repository
+// selected by opts: that it is a git repository with a user to commit as,
+// that the config loads and provides an API key, and that the AI endpoint
+// answers. Checks that depend on the config are skipped if it fails to
+// load.
+func Doctor(ctx context.Context, opts Options) []Check {
+	if opts.Status == nil {
+		opts.Status = io.Discard
+	}
+	setupOpts := opts.setup()
+	var checks []Check
+
+	gitClient := setup.NewGitClient(setupOpts)
+	repoCheck := Check{Name: "Git repository"}
+	if inside, err := gitClient.IsInsideRepo(); err != nil {
+		repoCheck.Err = err
+	} else if !inside {
+		repoCheck.Err = errors.New("not inside a git repository")
+	} else if root, err := gitClient.GetRepoRoot(); err == nil {
+		repoCheck.Detail = root
+	}
+	checks = append(checks, repoCheck)
+
+	userCheck := Check{Name: "Git user"}
+	if name, email, err := gitClient.GetUser(); err != nil {
+		userCheck.Err = err
+	} else {
+		userCheck.Detail = fmt.Sprintf("%s <%s>", name, email)
+	}
+	checks = append(checks, userCheck)
+
+	cfg, source, err := setup.NewConfigLoader(setupOpts).LoadConfigWithSource()
+	if err != nil {
+		return append(checks, Check{Name: "Config", Err: fmt.Errorf("failed to load config: %w", err)})
+	}
+	configCheck := Check{Name: "Config"}
+	var files []string
+	for _, path := range []string{source.Path, source.GlobalPath} {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	if len(files) == 0 {
+		configCheck.Err = errors.New("no config file found; run generate-commit init to create one")
+	} else {
+		configCheck.Detail = strings.Join(files, ", ")
+	}
+	checks = append(checks, configCheck)
+
+	keyCheck := Check{Name: "API key"}
+	if cfg.APIKey == "" {
+		keyCheck.Err = setup.NoAPIKey(cfg)
+	} else {
+		keyCheck.Detail = "from " + source.APIKeySource
+	}
+	checks = append(checks, keyCheck)
+
+	endpointCheck := Check{Name: "AI endpoint", Detail: fmt.Sprintf("%s (%s, model %s)", cfg.BaseURL, cfg.Provider, cfg.Model)}
+	client, err := setup.NewAIClient(setupOpts, gitClient, cfg)
+	if err == nil {
+		err = client.Ping(ctx)
+	}
+	if err != nil {
+		endpointCheck.Err = fmt.Errorf("%s: %w", cfg.BaseURL, err)
+	}
+	return append(checks, endpointCheck)
+}
@@ -0,0 +1,94 @@
+package commitgen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+func TestDoctor(t *testing.T) {
+	isolateConfig(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("expected a ping to /api/tags, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-doctor" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"models": []}`))
+	}))
+	defer server.Close()
+
+	repo := t.TempDir()
+	r, err := git.PlainInit(repo, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	gitConfig, err := r.Config()
+	if err != nil {
+		t.Fatalf("failed to read git config: %v", err)
+	}
+	gitConfig.User.Name = "Ada Lovelace"
+	gitConfig.User.Email = "ada@example.com"
+	if err := r.SetConfig(gitConfig); err != nil {
+		t.Fatalf("failed to write git config: %v", err)
+	}
+
+	writeConfig := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, ".commit-generator-config"), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+
+	writeConfig(`{"api_key": "sk-doctor", "base_url": "` + server.URL + `/api/generate"}`)
+	checks := Doctor(context.Background(), Options{Dir: repo})
+	names := []string{"Git repository", "Git user", "Config", "API key", "AI endpoint"}
+	if len(checks) != len(names) {
+		t.Fatalf("expected %d checks, got %+v", len(names), checks)
+	}
+	for i, check := range checks {
+		if check.Name != names[i] {
+			t.Errorf("expected check %d to be %q, got %q", i, names[i], check.Name)
+		}
+		if check.Err != nil {
+			t.Errorf("expected %s to pass, got %v", check.Name, check.Err)
+		}
+	}
+	if checks[1].Detail != "Ada Lovelace <ada@example.com>" {
+		t.Errorf("expected the git user in the detail, got %q", checks[1].Detail)
+	}
+
+	// A rejected key fails only the endpoint check
+	writeConfig(`{"api_key": "sk-wrong", "base_url": "` + server.URL + `/api/generate"}`)
+	checks = Doctor(context.Background(), Options{Dir: repo})
+	if err := checks[len(checks)-1].Err; err == nil || !strings.Contains(err.Error(), "API key rejected") {
+		t.Errorf("expected the endpoint check to fail with a rejected key, got %v", err)
+	}
+
+	// An invalid config stops the checks that need it
+	writeConfig(`{"timeout_seconds": -1}`)
+	checks = Doctor(context.Background(), Options{Dir: repo})
+	last := checks[len(checks)-1]
+	if len(checks) != 3 || last.Name != "Config" || last.Err == nil {
+		t.Errorf("expected a failed config check to end the checks, got %+v", checks)
+	}
+}
+
+func TestDoctor_NotARepository(t *testing.T) {
+	isolateConfig(t)
+	checks := Doctor(context.Background(), Options{Dir: t.TempDir(), BaseURL: "http://127.0.0.1:1/api/generate"})
+	if checks[0].Name != "Git repository" || checks[0].Err == nil {
+		t.Errorf("expected the repository check to fail, got %+v", checks[0])
+	}
+	if checks[len(checks)-1].Err == nil {
+		t.Errorf("expected the unreachable endpoint to fail, got %+v", checks[len(checks)-1])
+	}
+}
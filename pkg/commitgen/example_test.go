@@ -0,0 +1,38 @@
+package commitgen_test
+
+import (
+	"context"
+	"fmt"
+
+	"ai-commit-message-generator/pkg/commitgen"
+)
+
+// stubModel answers every request with the same message, in place of a
+// provider
+type stubModel struct{}
+
+func (stubModel) GenerateCommitMessage(ctx context.Context, diff, rules string) (string, error) {
+	return "fix(parser): handle empty input", nil
+}
+
+func ExampleGenerate() {
+	diff := `diff --git a/parser/parse.go b/parser/parse.go
+--- a/parser/parse.go
++++ b/parser/parse.go
+@@ -1,3 +1,6 @@
+ func Parse(input string) (*Node, error) {
++	if input == "" {
++		return nil, ErrEmpty
++	}
+`
+	message, err := commitgen.Generate(context.Background(), commitgen.Options{
+		Diff:  diff,
+		Model: stubModel{},
+	})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(message)
+	// Output: fix(parser): handle empty input
+}
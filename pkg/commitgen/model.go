@@ -0,0 +1,72 @@
+package commitgen
+
+import (
+	"context"
+	"errors"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+// Model generates a commit message for a diff, following the rules from
+// the repository's rules file. Implement it to use a model the built-in
+// providers don't cover, or a stub in tests.
+type Model interface {
+	GenerateCommitMessage(ctx context.Context, diff, rules string) (string, error)
+}
+
+// PromptBuilder is optionally implemented by a Model to show its prompt
+// for Options.DryRun
+type PromptBuilder interface {
+	BuildPrompt(diff, rules string) string
+}
+
+// errNotSupported is returned for requests a Model can't answer
+var errNotSupported = errors.New("not supported by a custom Model")
+
+// modelClient adapts a Model to the ai.Client the App uses
+type modelClient struct {
+	model Model
+}
+
+// GenerateCommitMessage asks the model for a message
+func (c *modelClient) GenerateCommitMessage(ctx context.Context, diff string, rules string) (*ai.GenerateResult, error) {
+	message, err := c.model.GenerateCommitMessage(ctx, diff, rules)
+	if err != nil {
+		return nil, err
+	}
+	return &ai.GenerateResult{Kind: ai.ResultMessage, Content: message}, nil
+}
+
+// GenerateCommitMessageWithBody asks the model for a message. Any body is
+// up to the model.
+func (c *modelClient) GenerateCommitMessageWithBody(ctx context.Context, diff string, rules string) (*ai.GenerateResult, error) {
+	return c.GenerateCommitMessage(ctx, diff, rules)
+}
+
+// SplitChanges is not supported
+func (c *modelClient) SplitChanges(ctx context.Context, diff string, rules string) ([]ai.ChangeGroup, error) {
+	return nil, errNotSupported
+}
+
+// BuildPrompt returns the model's prompt if it is a PromptBuilder
+func (c *modelClient) BuildPrompt(diff string, rules string) string {
+	if builder, ok := c.model.(PromptBuilder); ok {
+		return builder.BuildPrompt(diff, rules)
+	}
+	return ""
+}
+
+// BuildBodyPrompt returns the same prompt as BuildPrompt
+func (c *modelClient) BuildBodyPrompt(diff string, rules string) string {
+	return c.BuildPrompt(diff, rules)
+}
+
+// CheckMessage is not supported, so self-check is skipped with a warning
+func (c *modelClient) CheckMessage(ctx context.Context, message, diff, rules string) (*ai.SelfCheckResult, error) {
+	return nil, errNotSupported
+}
+
+// Ping always succeeds: the model is the caller's own
+func (c *modelClient) Ping(ctx context.Context) error {
+	return nil
+}
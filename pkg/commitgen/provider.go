@@ -0,0 +1,60 @@
+package commitgen
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"ai-commit-message-generator/internal/ai"
+)
+
+// ProviderConfig holds the settings from the config passed to a
+// ProviderFactory
+type ProviderConfig struct {
+	APIKey  string
+	BaseURL string
+	// ModelName is the configured model
+	ModelName string
+	Timeout   time.Duration
+	// HTTPClient applies the configured timeout, proxy, TLS settings,
+	// extra headers and debug logging. Send API requests with it.
+	HTTPClient *http.Client
+	// Status receives warnings and progress messages
+	Status io.Writer
+}
+
+// ProviderFactory builds the Model for a registered provider
+type ProviderFactory func(cfg ProviderConfig) (Model, error)
+
+// RegisterProvider adds a provider that configs can select with
+// "provider": name, alongside the built-in ones. Call it from an init
+// function. It panics if name is empty or already registered.
+func RegisterProvider(name string, factory ProviderFactory) {
+	if factory == nil {
+		panic("commitgen: RegisterProvider factory is nil for provider " + name)
+	}
+	ai.Register(name, func(opts ai.Options) (ai.Client, error) {
+		httpClient, err := opts.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		model, err := factory(ProviderConfig{
+			APIKey:     opts.APIKey,
+			BaseURL:    opts.BaseURL,
+			ModelName:  opts.Model,
+			Timeout:    opts.Timeout,
+			HTTPClient: httpClient,
+			Status:     opts.Status,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &modelClient{model: model}, nil
+	})
+}
+
+// Providers returns the sorted names of the built-in and registered
+// providers
+func Providers() []string {
+	return ai.Providers()
+}
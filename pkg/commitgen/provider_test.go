@@ -0,0 +1,54 @@
+package commitgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// echoModel answers with a message naming its config
+type echoModel struct {
+	cfg ProviderConfig
+}
+
+func (m *echoModel) GenerateCommitMessage(ctx context.Context, diff, rules string) (string, error) {
+	if m.cfg.HTTPClient == nil {
+		return "", errors.New("no HTTP client")
+	}
+	return fmt.Sprintf("chore: via %s at %s with %s", m.cfg.ModelName, m.cfg.BaseURL, m.cfg.APIKey), nil
+}
+
+func init() {
+	RegisterProvider("echo", func(cfg ProviderConfig) (Model, error) {
+		return &echoModel{cfg: cfg}, nil
+	})
+}
+
+func TestRegisterProvider(t *testing.T) {
+	isolateConfig(t)
+	if !slices.Contains(Providers(), "echo") {
+		t.Fatalf("expected echo in the providers, got %v", Providers())
+	}
+
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git dir: %v", err)
+	}
+	configData := `{"provider": "echo", "model": "echo-1", "api_key": "echo-key", "base_url": "http://echo.invalid/v1", "cache": false}`
+	if err := os.WriteFile(filepath.Join(repo, ".commit-generator-config"), []byte(configData), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	message, err := Generate(context.Background(), Options{Dir: repo, Diff: testDiff})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// The factory gets the settings from the config
+	if expected := "chore: via echo-1 at http://echo.invalid/v1 with echo-key"; message != expected {
+		t.Errorf("expected %q, got %q", expected, message)
+	}
+}